@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"bqui/internal/cache"
+	"bqui/internal/config"
+	"bqui/internal/tui"
+)
+
+// openCache opens the metadata cache with the size limit from the config file
+func openCache(cfg *config.Config) (*cache.Cache, error) {
+	c, err := cache.New()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.CacheMaxSizeMB > 0:
+		c.SetMaxSize(int64(cfg.CacheMaxSizeMB) << 20)
+	case cfg.CacheMaxSizeMB < 0:
+		c.SetMaxSize(0)
+	}
+
+	return c, nil
+}
+
+// runCacheCommand implements `bqui cache stats|prune|clear`
+func runCacheCommand(args []string) error {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to config file (default: <user config dir>/bqui/config.json)")
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "prune: remove entries not used within this duration (0 = only enforce the size limit)")
+	fs.Usage = func() {
+		fmt.Printf("Usage: %s cache [options] stats|prune|clear\n\n", appName)
+		fmt.Println("  stats   Show cache location, size and entry counts")
+		fmt.Println("  prune   Remove unused entries and shrink the cache to its size limit")
+		fmt.Println("  clear   Remove all cached data")
+		fmt.Println()
+		fmt.Println("Only one process can open the cache, so quit any running bqui first.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+
+	c, err := openCache(cfg)
+	if errors.Is(err, cache.ErrInUse) {
+		return fmt.Errorf("%w; quit it and run the cache command again", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	switch fs.Arg(0) {
+	case "stats":
+		stats, err := c.Stats()
+		if err != nil {
+			return err
+		}
+		printCacheStats(stats)
+	case "prune":
+		removed, err := c.Prune(*olderThan)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cache entries\n", removed)
+	case "clear":
+		if err := c.Clear(); err != nil {
+			return err
+		}
+		fmt.Println("Cache cleared successfully")
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func printCacheStats(stats cache.Stats) {
+	fmt.Printf("Location:   %s\n", stats.Path)
+	fmt.Printf("File size:  %s\n", tui.FormatBytes(stats.FileSize))
+	if stats.MaxSize > 0 {
+		fmt.Printf("Entries:    %s of %s limit\n", tui.FormatBytes(stats.Size), tui.FormatBytes(stats.MaxSize))
+	} else {
+		fmt.Printf("Entries:    %s (no limit)\n", tui.FormatBytes(stats.Size))
+	}
+
+	kinds := make([]string, 0, len(stats.Entries))
+	for kind := range stats.Entries {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Printf("  %-9s %d\n", kind, stats.Entries[kind])
+	}
+
+	fmt.Printf("Accounts:   %d\n", stats.Namespaces)
+
+	if !stats.Oldest.IsZero() {
+		fmt.Printf("Oldest:     %s\n", stats.Oldest.Format(time.DateTime))
+		fmt.Printf("Newest:     %s\n", stats.Newest.Format(time.DateTime))
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+// defaultEndpoint is the BigQuery API endpoint used when -emulator isn't set
+const defaultEndpoint = "https://bigquery.googleapis.com"
+
+// credentialsFile holds the fields of a credentials JSON file that identify
+// whom it authenticates as
+type credentialsFile struct {
+	Type             string `json:"type"`
+	ClientEmail      string `json:"client_email"`
+	Account          string `json:"account"`
+	RefreshToken     string `json:"refresh_token"`
+	Audience         string `json:"audience"`
+	ImpersonationURL string `json:"service_account_impersonation_url"`
+}
+
+// cacheEndpoint returns the API endpoint the cache should be scoped to
+func cacheEndpoint() string {
+	if *emulator != "" {
+		return *emulator
+	}
+	return defaultEndpoint
+}
+
+// detectPrincipal identifies the account bqui authenticates as, following the
+// same lookup order as Application Default Credentials. It only reads local
+// files (and the metadata server on GCE), so it also works offline.
+func detectPrincipal(ctx context.Context) string {
+	if *emulator != "" {
+		return "emulator"
+	}
+
+	path := *credFile
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		path = wellKnownCredentialsFile()
+	}
+	if principal := principalFromFile(path); principal != "" {
+		return principal
+	}
+
+	if metadata.OnGCE() {
+		if email, err := metadata.EmailWithContext(ctx, "default"); err == nil {
+			return "serviceAccount:" + email
+		}
+	}
+
+	return "unknown"
+}
+
+func principalFromFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var creds credentialsFile
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return ""
+	}
+
+	switch {
+	case creds.ImpersonationURL != "":
+		return "impersonated:" + creds.ImpersonationURL
+	case creds.ClientEmail != "":
+		return "serviceAccount:" + creds.ClientEmail
+	case creds.Account != "":
+		return "user:" + creds.Account
+	case creds.RefreshToken != "":
+		// User credentials don't always record the account; the refresh token
+		// is unique to the login, so a hash of it tells accounts apart
+		sum := sha256.Sum256([]byte(creds.RefreshToken))
+		return "user-token:" + hex.EncodeToString(sum[:8])
+	case creds.Audience != "":
+		return creds.Type + ":" + creds.Audience
+	}
+	return ""
+}
+
+// wellKnownCredentialsFile returns where `gcloud auth application-default login`
+// stores credentials
+func wellKnownCredentialsFile() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "gcloud", "application_default_credentials.json")
+}
@@ -7,11 +7,14 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"bqui/internal/bigquery"
+	"bqui/internal/cache"
+	"bqui/internal/config"
 	"bqui/internal/tui"
+	"bqui/pkg/clipboard"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"google.golang.org/api/option"
@@ -23,6 +26,11 @@ var (
 	emulator   = flag.String("emulator", "", "BigQuery emulator endpoint (for testing)")
 	version    = flag.Bool("version", false, "Show version information")
 	clearCache = flag.Bool("clear-cache", false, "Clear all cached data and exit")
+	configFile = flag.String("config", "", "Path to config file (default: <user config dir>/bqui/config.json)")
+	themeName  = flag.String("theme", "", "Color theme: dark, light, high-contrast, monochrome, or a theme file name/path")
+	offline    = flag.Bool("offline", false, "Browse cached metadata without connecting to BigQuery (queries disabled)")
+	tableName  = flag.String("table", "", "Table to open at startup: project.dataset.table, project:dataset.table or a console URL (also accepted as an argument)")
+	query      = flag.String("e", "", "Query to run at startup, shown in the Results tab")
 )
 
 const (
@@ -31,6 +39,13 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		if err := runCacheCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Cache command failed: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	flag.Parse()
 
 	if *version {
@@ -40,15 +55,83 @@ func main() {
 	}
 
 	if *clearCache {
-		if err := clearAllCache(); err != nil {
+		if err := runCacheCommand([]string{"-config", *configFile, "clear"}); err != nil {
 			log.Fatalf("Failed to clear cache: %v", err)
 		}
-		fmt.Println("Cache cleared successfully")
 		os.Exit(0)
 	}
 
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	keyMap, err := tui.NewKeyMap(cfg.Keys)
+	if err != nil {
+		log.Fatalf("Invalid key bindings in config: %v", err)
+	}
+
+	if *themeName != "" {
+		cfg.Theme = *themeName
+	}
+	themesDir, _ := config.ThemesDir() // Built-in themes still work without a config dir
+	theme, err := tui.LoadTheme(cfg.Theme, themesDir)
+	if err != nil {
+		log.Fatalf("Failed to load theme: %v", err)
+	}
+	tui.ApplyTheme(theme)
+
+	clipboardMode, err := clipboard.ParseMode(cfg.Clipboard)
+	if err != nil {
+		log.Fatalf("Invalid clipboard in config: %v", err)
+	}
+	clipboard.SetMode(clipboardMode)
+
+	cacheTTL := cache.DefaultTTL
+	if cfg.CacheTTL != "" {
+		cacheTTL, err = time.ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			log.Fatalf("Invalid cache_ttl in config: %v", err)
+		}
+	}
+
+	bookmarksPath, bookmarks := loadBookmarks()
+
+	// The TUI writes OSC 52 clipboard sequences to the same output it draws to
+	output := tui.NewTerminalOutput(os.Stdout)
+	opts := tui.Options{
+		KeyMap:        keyMap,
+		Bookmarks:     bookmarks,
+		BookmarksPath: bookmarksPath,
+		Query:         *query,
+		Output:        output,
+	}
+	if *tableName == "" && flag.NArg() > 0 {
+		*tableName = flag.Arg(0)
+	}
+	if flag.NArg() > 1 || (flag.NArg() == 1 && *tableName != flag.Arg(0)) {
+		log.Fatalf("Unexpected arguments: %s", strings.Join(flag.Args(), " "))
+	}
+	if *tableName != "" {
+		ref, err := bigquery.ParseTableReference(*tableName)
+		if err != nil {
+			log.Fatalf("Invalid table: %v", err)
+		}
+		// Start in the table's project unless -project says otherwise; the
+		// TUI then switches to it
+		if *projectID == "" {
+			*projectID = ref.ProjectID
+		}
+		opts.Table = &ref
+	}
+
 	ctx := context.Background()
 
+	if *offline {
+		runOffline(ctx, cfg, opts, cacheTTL)
+		return
+	}
+
 	client, err := createBigQueryClient(ctx)
 	if err != nil {
 		log.Fatalf("Failed to create BigQuery client: %v", err)
@@ -59,12 +142,91 @@ func main() {
 		}
 	}()
 
-	model := tui.NewModel(ctx, client)
+	rateLimit := cfg.MetadataRateLimit
+	switch {
+	case rateLimit == 0:
+		rateLimit = bigquery.DefaultMetadataRateLimit
+	case rateLimit < 0:
+		rateLimit = 0
+	}
+	client.SetMetadataConcurrency(cfg.MetadataWorkers, rateLimit)
+
+	metadataCache, err := openCache(cfg)
+	if err != nil {
+		// Continue without caching, e.g. when another bqui instance holds the cache
+		log.Printf("Warning: running without cache: %v", err)
+		metadataCache = nil
+	} else {
+		metadataCache.SetTTL(cacheTTL)
+		metadataCache.SetNamespace(detectPrincipal(ctx), cacheEndpoint())
+		defer metadataCache.Close()
+	}
+
+	opts.Cache = metadataCache
+	model := tui.NewModel(ctx, client, opts)
 
 	program := tea.NewProgram(
 		model,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
+		tea.WithOutput(output),
+	)
+
+	if _, err := program.Run(); err != nil {
+		log.Fatalf("Error running program: %v", err)
+	}
+}
+
+// loadBookmarks reads the starred tables. Without a readable bookmarks file
+// bqui still runs, but bookmarks are only kept for the session.
+func loadBookmarks() (string, []config.Bookmark) {
+	path, err := config.BookmarksPath()
+	if err != nil {
+		log.Printf("Warning: bookmarks won't be saved: %v", err)
+		return "", nil
+	}
+	bookmarks, err := config.LoadBookmarks(path)
+	if err != nil {
+		// Don't overwrite a file we couldn't read
+		log.Printf("Warning: bookmarks won't be saved: %v", err)
+		return "", nil
+	}
+	return path, bookmarks
+}
+
+// runOffline starts the TUI on cached metadata alone, without credentials
+func runOffline(ctx context.Context, cfg *config.Config, opts tui.Options, cacheTTL time.Duration) {
+	metadataCache, err := openCache(cfg)
+	if err != nil {
+		log.Fatalf("Offline mode needs the cache: %v", err)
+	}
+	defer metadataCache.Close()
+	metadataCache.SetTTL(cacheTTL)
+	metadataCache.SetNamespace(detectPrincipal(ctx), cacheEndpoint())
+
+	projID := *projectID
+	if projID == "" {
+		projID = detectDefaultProject()
+	}
+	if projID == "" {
+		// Fall back to any project we have cached
+		projects, err := metadataCache.Projects()
+		if err != nil || len(projects) == 0 {
+			log.Fatalf("No cached projects found. Run bqui online first to populate the cache")
+		}
+		projID = projects[0]
+	}
+
+	opts.Cache = metadataCache
+	opts.Offline = true
+	opts.ProjectID = projID
+	model := tui.NewModel(ctx, nil, opts)
+
+	program := tea.NewProgram(
+		model,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithOutput(opts.Output),
 	)
 
 	if _, err := program.Run(); err != nil {
@@ -130,40 +292,14 @@ func getGCloudDefaultProject() string {
 	return projectID
 }
 
-func clearAllCache() error {
-	// Get the cache directory by recreating the cache directory logic
-	var cacheDir string
-	switch {
-	case os.Getenv("LOCALAPPDATA") != "": // Windows
-		cacheDir = filepath.Join(os.Getenv("LOCALAPPDATA"), "bqui")
-	case os.Getenv("XDG_CACHE_HOME") != "": // Linux with XDG
-		cacheDir = filepath.Join(os.Getenv("XDG_CACHE_HOME"), "bqui")
-	default: // macOS and Linux fallback
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		if _, err := os.Stat(filepath.Join(homeDir, "Library")); err == nil {
-			// macOS
-			cacheDir = filepath.Join(homeDir, "Library", "Caches", "bqui")
-		} else {
-			// Linux
-			cacheDir = filepath.Join(homeDir, ".cache", "bqui")
-		}
-	}
-
-	// Remove the entire cache directory
-	if err := os.RemoveAll(cacheDir); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove cache directory: %w", err)
-	}
-
-	return nil
-}
-
 func init() {
 	flag.Usage = func() {
 		fmt.Printf("%s - A BigQuery Terminal User Interface\n\n", appName)
-		fmt.Printf("Usage: %s [options]\n\n", appName)
+		fmt.Printf("Usage: %s [options] [project.dataset.table]\n", appName)
+		fmt.Printf("       %s cache stats|prune|clear\n\n", appName)
+		fmt.Println("Only one bqui process can use the metadata cache at a time: a second instance")
+		fmt.Println("runs without it, and the cache command fails while bqui is running.")
+		fmt.Println()
 		fmt.Println("Options:")
 		flag.PrintDefaults()
 		fmt.Println()
@@ -171,15 +307,21 @@ func init() {
 		fmt.Println("  GOOGLE_APPLICATION_CREDENTIALS  Path to service account key file")
 		fmt.Println("  GOOGLE_CLOUD_PROJECT             Default project ID")
 		fmt.Println("  GCP_PROJECT                      Alternative project ID variable")
+		fmt.Println("  NO_COLOR                         Use the monochrome theme unless -theme is given")
+		fmt.Println()
+		fmt.Println("Key bindings can be remapped in the config file, e.g.:")
+		fmt.Println(`  {"keys": {"up": ["up", "k"], "visual": ["v"]}}`)
 		fmt.Println()
-		fmt.Println("Key Bindings:")
+		fmt.Println("Default Key Bindings:")
 		fmt.Println("  Navigation:    ↑↓←→ or hjkl")
 		fmt.Println("  Select:        Enter")
 		fmt.Println("  Search:        /")
+		fmt.Println("  Go to table:   Ctrl+G")
 		fmt.Println("  Copy table:    y or Ctrl+Y")
 		fmt.Println("  Cycle tabs:    Tab")
 		fmt.Println("  Back:          Esc")
-		fmt.Println("  Project list:  Ctrl+P")
+		fmt.Println("  Palette:       Ctrl+P")
+		fmt.Println("  Project list:  Ctrl+Space or Alt+P")
 		fmt.Println("  Help:          ?")
 		fmt.Println("  Quit:          q or Ctrl+C")
 		fmt.Println()
@@ -6,17 +6,23 @@ import (
 	"os/exec"
 	"sort"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/bigquery"
+	"golang.org/x/time/rate"
+	bqv2 "google.golang.org/api/bigquery/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type Client struct {
-	bqClient  *bigquery.Client
-	projectID string
-	ctx       context.Context
-	opts      []option.ClientOption
+	bqClient        *bigquery.Client
+	bqService       *bqv2.Service
+	projectID       string
+	ctx             context.Context
+	opts            []option.ClientOption
+	metadataWorkers int
+	metadataLimiter *rate.Limiter
 }
 
 func NewClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*Client, error) {
@@ -25,12 +31,24 @@ func NewClient(ctx context.Context, projectID string, opts ...option.ClientOptio
 		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
 	}
 
-	return &Client{
+	// The raw API service exposes list fields (type, creation time) that the
+	// high-level client drops, which lets us list tables without Metadata() calls
+	bqService, err := bqv2.NewService(ctx, opts...)
+	if err != nil {
+		_ = bqClient.Close()
+		return nil, fmt.Errorf("failed to create BigQuery API service: %w", err)
+	}
+
+	client := &Client{
 		bqClient:  bqClient,
+		bqService: bqService,
 		projectID: projectID,
 		ctx:       ctx,
 		opts:      opts,
-	}, nil
+	}
+	client.SetMetadataConcurrency(DefaultMetadataWorkers, DefaultMetadataRateLimit)
+
+	return client, nil
 }
 
 func (c *Client) Close() error {
@@ -41,8 +59,11 @@ func (c *Client) GetProjectID() string {
 	return c.projectID
 }
 
+// ListDatasets lists all datasets in the current project, fetching their metadata
+// concurrently. If some metadata fetches fail, the datasets are still returned
+// along with a *PartialError describing the failures.
 func (c *Client) ListDatasets() ([]*Dataset, error) {
-	datasets := make([]*Dataset, 0)
+	var refs []*bigquery.Dataset
 	it := c.bqClient.Datasets(c.ctx)
 
 	for {
@@ -53,103 +74,231 @@ func (c *Client) ListDatasets() ([]*Dataset, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to iterate datasets: %w", err)
 		}
+		refs = append(refs, dataset)
+	}
 
-		metadata, err := dataset.Metadata(c.ctx)
+	datasets := make([]*Dataset, len(refs))
+	for i, ref := range refs {
+		datasets[i] = &Dataset{
+			ID:        ref.DatasetID,
+			ProjectID: c.projectID,
+		}
+	}
+
+	errs := c.runMetadataJobs(len(refs), func(i int) error {
+		metadata, err := refs[i].Metadata(c.ctx)
 		if err != nil {
-			continue
+			return err
 		}
 
-		datasets = append(datasets, &Dataset{
-			ID:          dataset.DatasetID,
-			ProjectID:   c.projectID,
-			Location:    metadata.Location,
-			Description: metadata.Description,
-			CreatedAt:   metadata.CreationTime,
-			Labels:      metadata.Labels,
-		})
-	}
+		datasets[i].Location = metadata.Location
+		datasets[i].Description = metadata.Description
+		datasets[i].CreatedAt = metadata.CreationTime
+		datasets[i].Labels = metadata.Labels
+		return nil
+	})
 
 	sort.Slice(datasets, func(i, j int) bool {
 		return datasets[i].ID < datasets[j].ID
 	})
 
-	return datasets, nil
+	return datasets, collectItemErrors("datasets", errs, func(i int) string { return refs[i].DatasetID })
 }
 
-func (c *Client) ListTables(datasetID string) ([]*Table, error) {
-	dataset := c.bqClient.Dataset(datasetID)
-	tables := make([]*Table, 0)
-	it := dataset.Tables(c.ctx)
+// ListTablesPage lists one page of tables in a dataset using only the fields
+// returned by the list call (ID, type, creation time, labels). Row counts and
+// sizes are not populated; use GetTableDetails to fill them in.
+func (c *Client) ListTablesPage(datasetID, pageToken string, pageSize int) (*TablePage, error) {
+	call := c.bqService.Tables.List(c.projectID, datasetID).
+		PageToken(pageToken).
+		Context(c.ctx)
+	if pageSize > 0 {
+		call.MaxResults(int64(pageSize))
+	}
 
-	for {
-		table, err := it.Next()
-		if err == iterator.Done {
-			break
+	res, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	page := &TablePage{NextPageToken: res.NextPageToken}
+	for _, t := range res.Tables {
+		if t.TableReference == nil {
+			continue
 		}
+		page.Tables = append(page.Tables, &Table{
+			ID:        t.TableReference.TableId,
+			DatasetID: datasetID,
+			ProjectID: c.projectID,
+			CreatedAt: time.UnixMilli(t.CreationTime),
+			Type:      t.Type,
+			Labels:    t.Labels,
+		})
+	}
+
+	return page, nil
+}
+
+// GetTableDetails fetches full metadata (row counts, sizes, descriptions) for the
+// given tables concurrently. Tables whose metadata fails are omitted and reported
+// in a *PartialError.
+func (c *Client) GetTableDetails(datasetID string, tableIDs []string) ([]*Table, error) {
+	results := make([]*Table, len(tableIDs))
+	errs := c.runMetadataJobs(len(tableIDs), func(i int) error {
+		metadata, err := c.bqClient.Dataset(datasetID).Table(tableIDs[i]).Metadata(c.ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate tables: %w", err)
+			return err
 		}
 
-		metadata, err := table.Metadata(c.ctx)
-		if err != nil {
-			continue
+		results[i] = &Table{
+			ID:            tableIDs[i],
+			DatasetID:     datasetID,
+			ProjectID:     c.projectID,
+			Description:   metadata.Description,
+			CreatedAt:     metadata.CreationTime,
+			NumRows:       metadata.NumRows,
+			NumBytes:      metadata.NumBytes,
+			Type:          string(metadata.Type),
+			Labels:        metadata.Labels,
+			DetailsLoaded: true,
 		}
+		return nil
+	})
 
-		tables = append(tables, &Table{
-			ID:          table.TableID,
-			DatasetID:   datasetID,
-			ProjectID:   c.projectID,
-			Description: metadata.Description,
-			CreatedAt:   metadata.CreationTime,
-			NumRows:     metadata.NumRows,
-			NumBytes:    metadata.NumBytes,
-			Type:        string(metadata.Type),
-			Labels:      metadata.Labels,
-		})
+	var tables []*Table
+	for _, table := range results {
+		if table != nil {
+			tables = append(tables, table)
+		}
 	}
 
-	sort.Slice(tables, func(i, j int) bool {
-		return tables[i].ID < tables[j].ID
-	})
+	return tables, collectItemErrors("tables", errs, func(i int) string { return tableIDs[i] })
+}
 
-	return tables, nil
+// collectItemErrors turns per-index job errors into a *PartialError, or nil if all succeeded
+func collectItemErrors(kind string, errs []error, idOf func(i int) string) error {
+	var itemErrs []ItemError
+	for i, err := range errs {
+		if err != nil {
+			itemErrs = append(itemErrs, ItemError{ID: idOf(i), Err: err})
+		}
+	}
+	if len(itemErrs) == 0 {
+		return nil
+	}
+	return &PartialError{Kind: kind, Errors: itemErrs}
 }
 
 func (c *Client) GetTableSchema(datasetID, tableID string) (*TableSchema, error) {
-	table := c.bqClient.Dataset(datasetID).Table(tableID)
+	return c.GetTableSchemaInProject(c.projectID, datasetID, tableID)
+}
+
+// GetTableSchemaInProject fetches the schema of a table in any project the
+// credentials can read, not only the current one
+func (c *Client) GetTableSchemaInProject(projectID, datasetID, tableID string) (*TableSchema, error) {
+	table := c.bqClient.DatasetInProject(projectID, datasetID).Table(tableID)
 	metadata, err := table.Metadata(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table metadata: %w", err)
 	}
 
+	return schemaOf(metadata), nil
+}
+
+func schemaOf(metadata *bigquery.TableMetadata) *TableSchema {
 	return &TableSchema{
 		Fields: convertBigQuerySchema(metadata.Schema),
-	}, nil
+		Version: TableVersion{
+			ETag:         metadata.ETag,
+			LastModified: metadata.LastModifiedTime,
+		},
+	}
 }
 
+// GetTableVersion fetches only a table's ETag and last-modified time, which is
+// much cheaper than reading its full metadata
+func (c *Client) GetTableVersion(datasetID, tableID string) (TableVersion, error) {
+	res, err := c.bqService.Tables.Get(c.projectID, datasetID, tableID).
+		Fields("etag", "lastModifiedTime").
+		Context(c.ctx).
+		Do()
+	if err != nil {
+		return TableVersion{}, fmt.Errorf("failed to get table version: %w", err)
+	}
+
+	version := TableVersion{ETag: res.Etag}
+	if res.LastModifiedTime != 0 {
+		version.LastModified = time.UnixMilli(int64(res.LastModifiedTime))
+	}
+	return version, nil
+}
+
+// PreviewTable previews a table. Partitioned tables are previewed from their
+// newest partition, so the query only scans that partition.
 func (c *Client) PreviewTable(datasetID, tableID string, limit int) (*TablePreview, error) {
+	return c.Preview(datasetID, tableID, PreviewOptions{Limit: limit})
+}
+
+// Preview previews a table, optionally reading a given partition and sorting
+// the rows in BigQuery before the limit applies
+func (c *Client) Preview(datasetID, tableID string, opts PreviewOptions) (*TablePreview, error) {
+	limit := opts.Limit
 	if limit <= 0 {
 		limit = 100
 	}
+	partitionID := opts.PartitionID
 
-	schema, err := c.GetTableSchema(datasetID, tableID)
+	metadata, err := c.bqClient.Dataset(datasetID).Table(tableID).Metadata(c.ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get table metadata: %w", err)
 	}
+	schema := schemaOf(metadata)
 
-	// Check if table is partitioned and requires partition filter
-	partitionFilter, err := c.getPartitionFilter(datasetID, tableID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check partition info: %w", err)
+	var conditions []string
+	if partitioning := partitioningOf(metadata); partitioning != nil {
+		if partitionID == "" {
+			partitions, err := c.ListPartitions(datasetID, tableID)
+			if err != nil && partitioning.RequireFilter {
+				return nil, err
+			}
+			if latest := LatestPartition(partitions); latest != nil {
+				partitionID = latest.ID
+			} else if partitioning.RequireFilter {
+				// Without a partition with rows there is nothing to preview
+				return &TablePreview{Schema: schema, Headers: previewHeaders(schema)}, nil
+			}
+		}
+		if partitionID != "" {
+			filter, err := partitioning.Filter(partitionID)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, filter)
+		}
+	}
+	if opts.Where != "" {
+		conditions = append(conditions, "("+opts.Where+")")
+	}
+	var where string
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	var query string
-	if partitionFilter != "" {
-		query = fmt.Sprintf("SELECT * FROM `%s.%s.%s` %s LIMIT %d", c.projectID, datasetID, tableID, partitionFilter, limit)
-	} else {
-		query = fmt.Sprintf("SELECT * FROM `%s.%s.%s` LIMIT %d", c.projectID, datasetID, tableID, limit)
+	var orderBy string
+	if len(opts.OrderBy) > 0 {
+		var keys []string
+		for _, key := range opts.OrderBy {
+			if key.Desc {
+				keys = append(keys, QuoteIdentifier(key.Column)+" DESC")
+			} else {
+				keys = append(keys, QuoteIdentifier(key.Column))
+			}
+		}
+		orderBy = " ORDER BY " + strings.Join(keys, ", ")
 	}
 
+	query := fmt.Sprintf("SELECT * FROM `%s.%s.%s`%s%s LIMIT %d", c.projectID, datasetID, tableID, where, orderBy, limit)
+
 	q := c.bqClient.Query(query)
 	q.UseStandardSQL = true
 
@@ -159,15 +308,7 @@ func (c *Client) PreviewTable(datasetID, tableID string, limit int) (*TablePrevi
 	}
 
 	var rows [][]interface{}
-	var headers []string
-
-	for i, field := range schema.Fields {
-		headers = append(headers, field.Name)
-		if i > 10 {
-			headers = append(headers, "...")
-			break
-		}
-	}
+	headers := previewHeaders(schema)
 
 	for {
 		var row []bigquery.Value
@@ -179,11 +320,8 @@ func (c *Client) PreviewTable(datasetID, tableID string, limit int) (*TablePrevi
 			break
 		}
 
-		rowData := make([]interface{}, len(headers))
+		rowData := make([]interface{}, len(row))
 		for i, val := range row {
-			if i >= len(headers) {
-				break
-			}
 			rowData[i] = val
 		}
 		rows = append(rows, rowData)
@@ -194,12 +332,24 @@ func (c *Client) PreviewTable(datasetID, tableID string, limit int) (*TablePrevi
 	}
 
 	return &TablePreview{
-		Schema:  schema,
-		Rows:    rows,
-		Headers: headers,
+		Schema:      schema,
+		Rows:        rows,
+		Headers:     headers,
+		PartitionID: partitionID,
+		Where:       opts.Where,
+		OrderBy:     opts.OrderBy,
 	}, nil
 }
 
+// previewHeaders lists the columns of a preview: every top-level field
+func previewHeaders(schema *TableSchema) []string {
+	headers := make([]string, len(schema.Fields))
+	for i, field := range schema.Fields {
+		headers[i] = field.Name
+	}
+	return headers
+}
+
 func (c *Client) ExecuteQuery(query string) (*QueryResult, error) {
 	q := c.bqClient.Query(query)
 	q.UseStandardSQL = true
@@ -249,11 +399,15 @@ func (c *Client) ExecuteQuery(query string) (*QueryResult, error) {
 		rows = append(rows, rowData)
 	}
 
-	return &QueryResult{
+	result := &QueryResult{
 		Columns: columns,
 		Rows:    rows,
 		JobID:   job.ID(),
-	}, nil
+	}
+	if len(it.Schema) > 0 {
+		result.Schema = &TableSchema{Fields: convertBigQuerySchema(it.Schema)}
+	}
+	return result, nil
 }
 
 func convertBigQuerySchema(schema bigquery.Schema) []*Column {
@@ -317,101 +471,6 @@ func (c *Client) ListProjects() ([]*Project, error) {
 	return projects, nil
 }
 
-// getPartitionFilter checks if a table is partitioned and returns an appropriate WHERE clause
-func (c *Client) getPartitionFilter(datasetID, tableID string) (string, error) {
-	table := c.bqClient.Dataset(datasetID).Table(tableID)
-	metadata, err := table.Metadata(c.ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get table metadata: %w", err)
-	}
-
-	// Check for time partitioning
-	if metadata.TimePartitioning != nil {
-		if metadata.RequirePartitionFilter {
-			// If no field is specified, it's ingestion-time partitioning
-			if metadata.TimePartitioning.Field == "" {
-				// Use _PARTITIONTIME with a broad date range to ensure results
-				return "WHERE _PARTITIONTIME >= TIMESTAMP('1970-01-01')", nil
-			} else {
-				// Use the specific partition column with a broad range
-				return fmt.Sprintf("WHERE %s >= TIMESTAMP('1970-01-01')", metadata.TimePartitioning.Field), nil
-			}
-		}
-	}
-
-	// Check for range partitioning
-	if metadata.RangePartitioning != nil {
-		if metadata.RequirePartitionFilter {
-			// For range partitioning, use a broad numeric range
-			return fmt.Sprintf("WHERE %s >= 0", metadata.RangePartitioning.Field), nil
-		}
-	}
-
-	// Check for common partitioning patterns by examining column names
-	partitionFilter, err := c.detectPartitionColumns(datasetID, tableID)
-	if err != nil {
-		// If we can't detect via INFORMATION_SCHEMA, continue without filter
-		return "", nil
-	}
-
-	return partitionFilter, nil
-}
-
-// detectPartitionColumns checks for common partition columns using INFORMATION_SCHEMA
-func (c *Client) detectPartitionColumns(datasetID, tableID string) (string, error) {
-	// Common partition column patterns
-	commonPartitionColumns := []string{
-		"_PARTITIONTIME", "_PARTITIONDATE", "_PARTITION_LOAD_TIME",
-		"date", "created_at", "event_date", "transaction_date", "partition_date", "dt",
-	}
-
-	query := fmt.Sprintf(`
-		SELECT column_name, data_type
-		FROM `+"`"+`%s.%s.INFORMATION_SCHEMA.COLUMNS`+"`"+`
-		WHERE table_name = '%s'
-		AND (is_partitioning_column = "YES" OR column_name IN UNNEST(@partition_columns))
-		ORDER BY ordinal_position
-		LIMIT 1`, c.projectID, datasetID, tableID)
-
-	q := c.bqClient.Query(query)
-	q.UseStandardSQL = true
-	q.Parameters = []bigquery.QueryParameter{
-		{Name: "partition_columns", Value: commonPartitionColumns},
-	}
-
-	it, err := q.Read(c.ctx)
-	if err != nil {
-		// INFORMATION_SCHEMA might not be accessible, return no filter
-		return "", nil
-	}
-
-	var row []bigquery.Value
-	err = it.Next(&row)
-	if err == iterator.Done {
-		// No partition columns found
-		return "", nil
-	}
-	if err != nil {
-		return "", nil
-	}
-
-	columnName := row[0].(string)
-	dataType := row[1].(string)
-
-	// Create appropriate filter based on data type
-	switch {
-	case strings.Contains(dataType, "TIMESTAMP"):
-		return fmt.Sprintf("WHERE %s >= TIMESTAMP('1970-01-01')", columnName), nil
-	case strings.Contains(dataType, "DATE"):
-		return fmt.Sprintf("WHERE %s >= DATE('1970-01-01')", columnName), nil
-	case strings.Contains(dataType, "INT") || strings.Contains(dataType, "NUMERIC"):
-		return fmt.Sprintf("WHERE %s >= 0", columnName), nil
-	default:
-		// Unknown type, try timestamp filter
-		return fmt.Sprintf("WHERE %s >= TIMESTAMP('1970-01-01')", columnName), nil
-	}
-}
-
 func (c *Client) SwitchProject(projectID string) error {
 	if err := c.bqClient.Close(); err != nil {
 		return fmt.Errorf("failed to close current client: %w", err)
@@ -0,0 +1,43 @@
+package bigquery
+
+import (
+	"fmt"
+
+	"google.golang.org/api/iterator"
+)
+
+// ListColumns lists every top-level column of every table and view in a
+// dataset with a single INFORMATION_SCHEMA.COLUMNS query. The dataset's
+// project is named, so a listing that outlives a project switch still reads
+// the project it was started for.
+func (c *Client) ListColumns(projectID, datasetID string) ([]*ColumnInfo, error) {
+	query := fmt.Sprintf("SELECT table_name, column_name, data_type FROM `%s.%s.INFORMATION_SCHEMA.COLUMNS` ORDER BY table_name, ordinal_position",
+		projectID, datasetID)
+
+	q := c.bqClient.Query(query)
+	q.UseStandardSQL = true
+
+	it, err := q.Read(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns of dataset %s: %w", datasetID, err)
+	}
+
+	var columns []*ColumnInfo
+	for {
+		var row struct {
+			TableName  string `bigquery:"table_name"`
+			ColumnName string `bigquery:"column_name"`
+			DataType   string `bigquery:"data_type"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of dataset %s: %w", datasetID, err)
+		}
+		columns = append(columns, &ColumnInfo{TableID: row.TableName, Name: row.ColumnName, Type: row.DataType})
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,546 @@
+package bigquery
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a parsed row filter such as
+//
+//	status = 'active' and amount > 100
+//	name ~ /^a.*z$/ or not (email is null)
+//
+// It can be evaluated against loaded rows or rendered as a WHERE condition.
+// Conditions compare a column to a quoted string, a number or true/false with
+// =, !=, <>, <, <=, >, >=; match a regular expression with ~ or !~; or test
+// for NULL with "is null" and "is not null". They combine with and, or, not
+// and parentheses.
+type Filter struct {
+	root filterNode
+}
+
+type filterNode interface {
+	sql() string
+	match(value func(column string) (interface{}, bool)) (truth, error)
+}
+
+// truth is the result of a condition in SQL's three-valued logic, where a
+// comparison with NULL is neither true nor false
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+// ParseFilter parses a filter expression
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %s", p.peek())
+	}
+	return &Filter{root: root}, nil
+}
+
+// SQL renders the filter as a WHERE condition
+func (f *Filter) SQL() string {
+	return f.root.sql()
+}
+
+// Match evaluates the filter against a row, reading cells through value. As
+// in SQL, a comparison with NULL is unknown rather than false, not keeps it
+// unknown, and only rows where the whole filter is true match.
+func (f *Filter) Match(value func(column string) (interface{}, bool)) (bool, error) {
+	result, err := f.root.match(value)
+	return result == truthTrue, err
+}
+
+type logicalNode struct {
+	and         bool
+	left, right filterNode
+}
+
+func (n *logicalNode) sql() string {
+	op := "OR"
+	if n.and {
+		op = "AND"
+	}
+	return fmt.Sprintf("(%s %s %s)", n.left.sql(), op, n.right.sql())
+}
+
+func (n *logicalNode) match(value func(string) (interface{}, bool)) (truth, error) {
+	// false decides AND and true decides OR; otherwise unknown wins over the other
+	decisive := truthOf(!n.and)
+	left, err := n.left.match(value)
+	if err != nil || left == decisive {
+		return left, err // Short-circuit
+	}
+	right, err := n.right.match(value)
+	if err != nil || right == decisive {
+		return right, err
+	}
+	if left == truthUnknown || right == truthUnknown {
+		return truthUnknown, nil
+	}
+	return left, nil
+}
+
+type notNode struct {
+	operand filterNode
+}
+
+func (n *notNode) sql() string {
+	return fmt.Sprintf("NOT %s", n.operand.sql())
+}
+
+func (n *notNode) match(value func(string) (interface{}, bool)) (truth, error) {
+	result, err := n.operand.match(value)
+	switch result {
+	case truthTrue:
+		return truthFalse, err
+	case truthFalse:
+		return truthTrue, err
+	}
+	return result, err
+}
+
+type compareNode struct {
+	column string
+	op     string
+	value  filterLiteral
+}
+
+func (n *compareNode) sql() string {
+	return fmt.Sprintf("%s %s %s", quoteColumnPath(n.column), n.op, n.value.sql())
+}
+
+func (n *compareNode) match(value func(string) (interface{}, bool)) (truth, error) {
+	cell, ok := value(n.column)
+	if !ok {
+		return truthFalse, fmt.Errorf("unknown column %s", n.column)
+	}
+	if cell == nil {
+		return truthUnknown, nil
+	}
+	c, ok := n.value.compare(cell)
+	if !ok {
+		return truthFalse, nil
+	}
+	switch n.op {
+	case "=":
+		return truthOf(c == 0), nil
+	case "!=":
+		return truthOf(c != 0), nil
+	case "<":
+		return truthOf(c < 0), nil
+	case "<=":
+		return truthOf(c <= 0), nil
+	case ">":
+		return truthOf(c > 0), nil
+	default:
+		return truthOf(c >= 0), nil
+	}
+}
+
+type regexNode struct {
+	column string
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (n *regexNode) sql() string {
+	expr := fmt.Sprintf("REGEXP_CONTAINS(CAST(%s AS STRING), %s)", quoteColumnPath(n.column), quoteString(n.re.String()))
+	if n.negate {
+		return "NOT " + expr
+	}
+	return expr
+}
+
+func (n *regexNode) match(value func(string) (interface{}, bool)) (truth, error) {
+	cell, ok := value(n.column)
+	if !ok {
+		return truthFalse, fmt.Errorf("unknown column %s", n.column)
+	}
+	if cell == nil {
+		return truthUnknown, nil
+	}
+	return truthOf(n.re.MatchString(fmt.Sprintf("%v", cell)) != n.negate), nil
+}
+
+type nullNode struct {
+	column string
+	not    bool
+}
+
+func (n *nullNode) sql() string {
+	if n.not {
+		return quoteColumnPath(n.column) + " IS NOT NULL"
+	}
+	return quoteColumnPath(n.column) + " IS NULL"
+}
+
+func (n *nullNode) match(value func(string) (interface{}, bool)) (truth, error) {
+	cell, ok := value(n.column)
+	if !ok {
+		return truthFalse, fmt.Errorf("unknown column %s", n.column)
+	}
+	return truthOf((cell == nil) != n.not), nil
+}
+
+// filterLiteral is a value a column is compared to
+type filterLiteral struct {
+	kind tokenKind // tokenString, tokenNumber or tokenBool
+	text string
+}
+
+func (l filterLiteral) sql() string {
+	switch l.kind {
+	case tokenString:
+		return quoteString(l.text)
+	case tokenBool:
+		return strings.ToUpper(l.text)
+	default:
+		return l.text
+	}
+}
+
+// compare orders a cell relative to the literal. ok is false when they can't
+// be compared, such as for NULL cells or a number against a bool.
+func (l filterLiteral) compare(cell interface{}) (c int, ok bool) {
+	if cell == nil {
+		return 0, false
+	}
+
+	switch l.kind {
+	case tokenNumber:
+		want, _ := strconv.ParseFloat(l.text, 64)
+		switch v := cell.(type) {
+		case int64:
+			return cmp.Compare(float64(v), want), true
+		case float64:
+			return cmp.Compare(v, want), true
+		case *big.Rat:
+			f, _ := v.Float64()
+			return cmp.Compare(f, want), true
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return cmp.Compare(f, want), true
+			}
+		}
+		return 0, false
+	case tokenBool:
+		v, isBool := cell.(bool)
+		if !isBool {
+			return 0, false
+		}
+		if v == strings.EqualFold(l.text, "true") {
+			return 0, true
+		}
+		return 1, true
+	}
+
+	if t, isTime := cell.(time.Time); isTime {
+		for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+			if want, err := time.Parse(layout, l.text); err == nil {
+				return t.Compare(want), true
+			}
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%v", cell), l.text), true
+}
+
+// quoteColumnPath quotes each part of a possibly nested column name
+func quoteColumnPath(column string) string {
+	parts := strings.Split(column, ".")
+	for i, part := range parts {
+		parts[i] = QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteString writes a GoogleSQL string literal
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`).Replace(s) + "'"
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenBool
+	tokenRegex
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+}
+
+func (t filterToken) String() string {
+	if t.kind == tokenString {
+		return fmt.Sprintf("'%s'", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var filterKeywords = map[string]bool{"and": true, "or": true, "not": true, "is": true, "null": true}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			kind := tokenLParen
+			if r == ')' {
+				kind = tokenRParen
+			}
+			tokens = append(tokens, filterToken{kind: kind, text: string(r)})
+			i++
+
+		case r == '\'' || r == '"' || r == '`' || r == '/':
+			// Quoted strings, backquoted identifiers and /regex/ literals
+			text, end, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenString
+			switch r {
+			case '`':
+				kind = tokenIdent
+			case '/':
+				kind = tokenRegex
+			}
+			tokens = append(tokens, filterToken{kind: kind, text: text})
+			i = end
+
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "!=", "<=", ">=", "<>", "!~":
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected ! at position %d", i+1)
+			}
+			i += len(op)
+			if op == "<>" {
+				op = "!="
+			}
+			tokens = append(tokens, filterToken{kind: tokenOp, text: op})
+
+		case unicode.IsDigit(r) || ((r == '-' || r == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE", runes[i]) ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, fmt.Errorf("invalid number %s", text)
+			}
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: text})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			word := string(runes[start:i])
+			lower := strings.ToLower(word)
+			switch {
+			case filterKeywords[lower]:
+				tokens = append(tokens, filterToken{kind: tokenKeyword, text: lower})
+			case lower == "true" || lower == "false":
+				tokens = append(tokens, filterToken{kind: tokenBool, text: lower})
+			default:
+				tokens = append(tokens, filterToken{kind: tokenIdent, text: word})
+			}
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+		}
+	}
+	return tokens, nil
+}
+
+// readQuoted reads a literal delimited by runes[start], where a backslash
+// escapes the delimiter. Regex literals keep their other escapes as written.
+func readQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes):
+			if runes[i+1] != quote && (quote == '/' || runes[i+1] != '\\') {
+				text.WriteRune(runes[i])
+			}
+			i++
+			text.WriteRune(runes[i])
+		case runes[i] == quote:
+			return text.String(), i + 1, nil
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated %c", quote)
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{kind: tokenKeyword, text: "end of filter"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) keyword(word string) bool {
+	if !p.done() && p.tokens[p.pos].kind == tokenKeyword && p.tokens[p.pos].text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.keyword("not") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) but found %s", p.peek())
+		}
+		p.pos++
+		return node, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseCondition() (filterNode, error) {
+	column := p.peek()
+	if column.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a column name but found %s", column)
+	}
+	p.pos++
+
+	if p.keyword("is") {
+		not := p.keyword("not")
+		if !p.keyword("null") {
+			return nil, fmt.Errorf("expected null after is but found %s", p.peek())
+		}
+		return &nullNode{column: column.text, not: not}, nil
+	}
+
+	op := p.peek()
+	if op.kind != tokenOp {
+		return nil, fmt.Errorf("expected an operator after %s but found %s", column.text, op)
+	}
+	p.pos++
+
+	value := p.peek()
+	p.pos++
+	if op.text == "~" || op.text == "!~" {
+		if value.kind != tokenRegex && value.kind != tokenString {
+			return nil, fmt.Errorf("expected /regex/ after %s but found %s", op.text, value)
+		}
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return &regexNode{column: column.text, re: re, negate: op.text == "!~"}, nil
+	}
+
+	switch value.kind {
+	case tokenString, tokenNumber, tokenBool:
+		return &compareNode{column: column.text, op: op.text, value: filterLiteral{kind: value.kind, text: value.text}}, nil
+	}
+	return nil, fmt.Errorf("expected a quoted string, number or true/false after %s but found %s", op.text, value)
+}
@@ -0,0 +1,93 @@
+package bigquery
+
+import "testing"
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(`status = 'active' and (amount > 100 or name ~ /^a.*z$/) and not email is null`)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	want := "((status = 'active' AND (amount > 100 OR REGEXP_CONTAINS(CAST(name AS STRING), '^a.*z$'))) AND NOT email IS NULL)"
+	if got := filter.SQL(); got != want {
+		t.Errorf("Unexpected SQL:\n got %s\nwant %s", got, want)
+	}
+
+	row := func(cells map[string]interface{}) func(string) (interface{}, bool) {
+		return func(column string) (interface{}, bool) {
+			v, ok := cells[column]
+			return v, ok
+		}
+	}
+	cases := []struct {
+		cells map[string]interface{}
+		want  bool
+	}{
+		{map[string]interface{}{"status": "active", "amount": int64(150), "name": "bob", "email": "b@x"}, true},
+		{map[string]interface{}{"status": "active", "amount": int64(5), "name": "abz", "email": "a@x"}, true},
+		{map[string]interface{}{"status": "active", "amount": int64(5), "name": "bob", "email": "b@x"}, false},
+		{map[string]interface{}{"status": "active", "amount": nil, "name": "abz", "email": nil}, false},
+		{map[string]interface{}{"status": "closed", "amount": 500.0, "name": "bob", "email": "b@x"}, false},
+	}
+	for i, c := range cases {
+		got, err := filter.Match(row(c.cells))
+		if err != nil {
+			t.Fatalf("case %d: Match failed: %v", i, err)
+		}
+		if got != c.want {
+			t.Errorf("case %d: expected %v, got %v", i, c.want, got)
+		}
+	}
+
+	if _, err := filter.Match(row(map[string]interface{}{"status": "active"})); err == nil {
+		t.Error("Expected an error for a column the row doesn't have")
+	}
+
+	for _, expr := range []string{"", "just some words", "amount >", "name = 'unterminated", "(a = 1"} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("Expected %q not to parse", expr)
+		}
+	}
+}
+
+// TestFilterNullMatchesSQL checks that rows with a NULL amount match locally
+// exactly when BigQuery's WHERE would keep them
+func TestFilterNullMatchesSQL(t *testing.T) {
+	row := func(column string) (interface{}, bool) {
+		cells := map[string]interface{}{"amount": nil, "status": "open"}
+		v, ok := cells[column]
+		return v, ok
+	}
+	cases := []struct {
+		expr string
+		sql  string
+		want bool // Whether WHERE keeps a row with amount NULL and status 'open'
+	}{
+		{"amount > 1", "amount > 1", false},
+		{"not amount > 1", "NOT amount > 1", false},
+		{"not not amount > 1", "NOT NOT amount > 1", false},
+		{"amount !~ /x/", "NOT REGEXP_CONTAINS(CAST(amount AS STRING), 'x')", false},
+		{"not amount ~ /x/", "NOT REGEXP_CONTAINS(CAST(amount AS STRING), 'x')", false},
+		{"amount > 1 or status = 'open'", "(amount > 1 OR status = 'open')", true},
+		{"not (amount > 1 and status = 'open')", "NOT (amount > 1 AND status = 'open')", false},
+		{"not (amount > 1 and status = 'closed')", "NOT (amount > 1 AND status = 'closed')", true},
+		{"not (amount > 1 or status = 'closed')", "NOT (amount > 1 OR status = 'closed')", false},
+		{"not amount is null", "NOT amount IS NULL", false},
+		{"amount is null and status != 'closed'", "(amount IS NULL AND status != 'closed')", true},
+	}
+	for _, c := range cases {
+		filter, err := ParseFilter(c.expr)
+		if err != nil {
+			t.Fatalf("%s: ParseFilter failed: %v", c.expr, err)
+		}
+		if got := filter.SQL(); got != c.sql {
+			t.Errorf("%s: expected SQL %s, got %s", c.expr, c.sql, got)
+		}
+		got, err := filter.Match(row)
+		if err != nil {
+			t.Fatalf("%s: Match failed: %v", c.expr, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: expected %v like SQL, got %v", c.expr, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package bigquery
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultMetadataWorkers is the default number of concurrent Metadata() calls
+	DefaultMetadataWorkers = 16
+	// DefaultMetadataRateLimit is the default maximum Metadata() calls per second
+	DefaultMetadataRateLimit = 50
+)
+
+// ItemError records a metadata fetch failure for a single dataset or table
+type ItemError struct {
+	ID  string
+	Err error
+}
+
+// PartialError is returned alongside results when metadata could not be fetched
+// for some items. ListDatasets still includes the failed datasets with only
+// their IDs populated, while GetTableDetails omits the failed tables so the
+// entries already listed for them are kept.
+type PartialError struct {
+	Kind   string
+	Errors []ItemError
+}
+
+func (e *PartialError) Error() string {
+	var ids []string
+	for i, itemErr := range e.Errors {
+		if i == 3 {
+			ids = append(ids, fmt.Sprintf("and %d more", len(e.Errors)-3))
+			break
+		}
+		ids = append(ids, itemErr.ID)
+	}
+	return fmt.Sprintf("failed to fetch metadata for %d %s (%s): %v",
+		len(e.Errors), e.Kind, strings.Join(ids, ", "), e.Errors[0].Err)
+}
+
+// SetMetadataConcurrency configures how many Metadata() calls run in parallel
+// and how many may start per second. A rate limit of 0 disables rate limiting.
+func (c *Client) SetMetadataConcurrency(workers int, perSecond float64) {
+	if workers <= 0 {
+		workers = DefaultMetadataWorkers
+	}
+	c.metadataWorkers = workers
+
+	if perSecond > 0 {
+		c.metadataLimiter = rate.NewLimiter(rate.Limit(perSecond), workers)
+	} else {
+		c.metadataLimiter = nil
+	}
+}
+
+// runMetadataJobs runs job for every index in [0, count) on a bounded worker pool
+// and returns the error of each job by index
+func (c *Client) runMetadataJobs(count int, job func(i int) error) []error {
+	errs := make([]error, count)
+	if count == 0 {
+		return errs
+	}
+
+	workers := c.metadataWorkers
+	if workers <= 0 {
+		workers = DefaultMetadataWorkers
+	}
+	if workers > count {
+		workers = count
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if c.metadataLimiter != nil {
+					if err := c.metadataLimiter.Wait(c.ctx); err != nil {
+						errs[i] = err
+						continue
+					}
+				}
+				errs[i] = job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
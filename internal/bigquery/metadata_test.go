@@ -0,0 +1,49 @@
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunMetadataJobsBoundedConcurrency(t *testing.T) {
+	client := &Client{ctx: context.Background()}
+	client.SetMetadataConcurrency(3, 0)
+
+	var running, peak int32
+	errs := client.runMetadataJobs(20, func(i int) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if i%5 == 0 {
+			return fmt.Errorf("item %d failed", i)
+		}
+		return nil
+	})
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent jobs, got %d", peak)
+	}
+
+	err := collectItemErrors("tables", errs, func(i int) string { return fmt.Sprintf("t%d", i) })
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected *PartialError, got %v", err)
+	}
+	if len(partial.Errors) != 4 {
+		t.Errorf("Expected 4 item errors, got %d", len(partial.Errors))
+	}
+	if partial.Errors[0].ID != "t0" {
+		t.Errorf("Expected first failed item 't0', got '%s'", partial.Errors[0].ID)
+	}
+}
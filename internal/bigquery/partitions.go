@@ -0,0 +1,228 @@
+package bigquery
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// Special partitions that don't correspond to a time unit or range
+const (
+	NullPartitionID          = "__NULL__"
+	UnpartitionedPartitionID = "__UNPARTITIONED__"
+)
+
+// Partitioning describes how a table is partitioned
+type Partitioning struct {
+	// Type is DAY, HOUR, MONTH or YEAR for time partitioning and RANGE for
+	// integer-range partitioning
+	Type string
+	// Field is the partitioning column; empty for ingestion-time partitioning
+	Field     string
+	FieldType bigquery.FieldType
+	// Range partitioning bounds
+	RangeStart    int64
+	RangeEnd      int64
+	RangeInterval int64
+	// RequireFilter is set when queries must filter on the partitioning column
+	RequireFilter bool
+}
+
+// Partition is one partition as listed by INFORMATION_SCHEMA.PARTITIONS
+type Partition struct {
+	ID           string
+	Rows         int64
+	Bytes        int64
+	LastModified time.Time
+}
+
+// GetPartitioning returns how a table is partitioned, or nil if it isn't
+func (c *Client) GetPartitioning(datasetID, tableID string) (*Partitioning, error) {
+	metadata, err := c.bqClient.Dataset(datasetID).Table(tableID).Metadata(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table metadata: %w", err)
+	}
+	return partitioningOf(metadata), nil
+}
+
+func partitioningOf(metadata *bigquery.TableMetadata) *Partitioning {
+	var p *Partitioning
+	switch {
+	case metadata.TimePartitioning != nil:
+		p = &Partitioning{
+			Type:  string(metadata.TimePartitioning.Type),
+			Field: metadata.TimePartitioning.Field,
+		}
+		if p.Type == "" {
+			p.Type = string(bigquery.DayPartitioningType)
+		}
+	case metadata.RangePartitioning != nil && metadata.RangePartitioning.Range != nil:
+		r := metadata.RangePartitioning.Range
+		p = &Partitioning{
+			Type:          "RANGE",
+			Field:         metadata.RangePartitioning.Field,
+			RangeStart:    r.Start,
+			RangeEnd:      r.End,
+			RangeInterval: r.Interval,
+		}
+	default:
+		return nil
+	}
+
+	p.RequireFilter = metadata.RequirePartitionFilter
+	for _, field := range metadata.Schema {
+		if field.Name == p.Field {
+			p.FieldType = field.Type
+		}
+	}
+	return p
+}
+
+// ListPartitions lists a table's partitions, newest first
+func (c *Client) ListPartitions(datasetID, tableID string) ([]*Partition, error) {
+	query := fmt.Sprintf("SELECT partition_id, total_rows, total_logical_bytes, last_modified_time FROM `%s.%s.INFORMATION_SCHEMA.PARTITIONS` WHERE table_name = @table ORDER BY partition_id DESC",
+		c.projectID, datasetID)
+
+	q := c.bqClient.Query(query)
+	q.UseStandardSQL = true
+	q.Parameters = []bigquery.QueryParameter{{Name: "table", Value: tableID}}
+
+	it, err := q.Read(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions of %s: %w", tableID, err)
+	}
+
+	var partitions []*Partition
+	for {
+		var row struct {
+			PartitionID      bigquery.NullString    `bigquery:"partition_id"`
+			TotalRows        bigquery.NullInt64     `bigquery:"total_rows"`
+			TotalBytes       bigquery.NullInt64     `bigquery:"total_logical_bytes"`
+			LastModifiedTime bigquery.NullTimestamp `bigquery:"last_modified_time"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partitions of %s: %w", tableID, err)
+		}
+		partitions = append(partitions, &Partition{
+			ID:           row.PartitionID.StringVal,
+			Rows:         row.TotalRows.Int64,
+			Bytes:        row.TotalBytes.Int64,
+			LastModified: row.LastModifiedTime.Timestamp,
+		})
+	}
+
+	return partitions, nil
+}
+
+// LatestPartition returns the newest partition with rows, skipping the special
+// NULL and unpartitioned ones. partitions must be sorted newest first.
+func LatestPartition(partitions []*Partition) *Partition {
+	for _, p := range partitions {
+		if p.Rows > 0 && p.ID != NullPartitionID && p.ID != UnpartitionedPartitionID {
+			return p
+		}
+	}
+	return nil
+}
+
+// partitionIDLayouts maps time partitioning types to their partition ID format
+var partitionIDLayouts = map[string]string{
+	"HOUR":  "2006010215",
+	"DAY":   "20060102",
+	"MONTH": "200601",
+	"YEAR":  "2006",
+}
+
+// Time-partitioned tables only partition values in this range; values outside
+// it go to the __UNPARTITIONED__ partition
+var (
+	earliestPartitionTime = time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)
+	latestPartitionTime   = time.Date(2160, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Filter returns a WHERE condition that selects exactly one partition, written
+// as a range on the partitioning column so BigQuery can prune the others
+func (p *Partitioning) Filter(partitionID string) (string, error) {
+	if p.Type == "RANGE" {
+		return p.rangeFilter(partitionID)
+	}
+
+	column := QuoteIdentifier(p.Field)
+	fieldType := p.FieldType
+	if p.Field == "" {
+		// Ingestion-time partitioning
+		column = "_PARTITIONTIME"
+		fieldType = bigquery.TimestampFieldType
+	}
+
+	switch partitionID {
+	case NullPartitionID:
+		return column + " IS NULL", nil
+	case UnpartitionedPartitionID:
+		if p.Field == "" {
+			return column + " IS NULL", nil // Rows still in the streaming buffer
+		}
+		return fmt.Sprintf("(%s < %s OR %s >= %s)",
+			column, timeLiteral(fieldType, earliestPartitionTime),
+			column, timeLiteral(fieldType, latestPartitionTime)), nil
+	}
+
+	layout, ok := partitionIDLayouts[p.Type]
+	if !ok {
+		return "", fmt.Errorf("unsupported partitioning type %s", p.Type)
+	}
+	start, err := time.Parse(layout, partitionID)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s partition ID %q", p.Type, partitionID)
+	}
+
+	var end time.Time
+	switch p.Type {
+	case "HOUR":
+		end = start.Add(time.Hour)
+	case "DAY":
+		end = start.AddDate(0, 0, 1)
+	case "MONTH":
+		end = start.AddDate(0, 1, 0)
+	default:
+		end = start.AddDate(1, 0, 0)
+	}
+
+	return fmt.Sprintf("%s >= %s AND %s < %s",
+		column, timeLiteral(fieldType, start), column, timeLiteral(fieldType, end)), nil
+}
+
+func (p *Partitioning) rangeFilter(partitionID string) (string, error) {
+	column := QuoteIdentifier(p.Field)
+	switch partitionID {
+	case NullPartitionID:
+		return column + " IS NULL", nil
+	case UnpartitionedPartitionID:
+		return fmt.Sprintf("(%s < %d OR %s >= %d)", column, p.RangeStart, column, p.RangeEnd), nil
+	}
+
+	start, err := strconv.ParseInt(partitionID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid range partition ID %q", partitionID)
+	}
+	return fmt.Sprintf("%s >= %d AND %s < %d", column, start, column, start+p.RangeInterval), nil
+}
+
+// timeLiteral writes a time as a literal of the partitioning column's type
+func timeLiteral(fieldType bigquery.FieldType, t time.Time) string {
+	switch fieldType {
+	case bigquery.DateFieldType:
+		return fmt.Sprintf("DATE '%s'", t.Format("2006-01-02"))
+	case bigquery.DateTimeFieldType:
+		return fmt.Sprintf("DATETIME '%s'", t.Format("2006-01-02 15:04:05"))
+	default:
+		return fmt.Sprintf("TIMESTAMP '%s'", t.Format("2006-01-02 15:04:05"))
+	}
+}
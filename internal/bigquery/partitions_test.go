@@ -0,0 +1,54 @@
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func TestPartitionFilter(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		partitioning Partitioning
+		partitionID  string
+		want         string
+	}{
+		{
+			name:         "daily on a DATE column",
+			partitioning: Partitioning{Type: "DAY", Field: "event_date", FieldType: bigquery.DateFieldType},
+			partitionID:  "20240131",
+			want:         "event_date >= DATE '2024-01-31' AND event_date < DATE '2024-02-01'",
+		},
+		{
+			name:         "hourly by ingestion time",
+			partitioning: Partitioning{Type: "HOUR"},
+			partitionID:  "2024013123",
+			want:         "_PARTITIONTIME >= TIMESTAMP '2024-01-31 23:00:00' AND _PARTITIONTIME < TIMESTAMP '2024-02-01 00:00:00'",
+		},
+		{
+			name:         "integer range",
+			partitioning: Partitioning{Type: "RANGE", Field: "customer_id", RangeStart: 0, RangeEnd: 100, RangeInterval: 10},
+			partitionID:  "40",
+			want:         "customer_id >= 40 AND customer_id < 50",
+		},
+		{
+			name:         "NULL partition",
+			partitioning: Partitioning{Type: "MONTH", Field: "created_at", FieldType: bigquery.TimestampFieldType},
+			partitionID:  NullPartitionID,
+			want:         "created_at IS NULL",
+		},
+	} {
+		got, err := tt.partitioning.Filter(tt.partitionID)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := (&Partitioning{Type: "DAY", Field: "d"}).Filter("2024-01-31"); err == nil {
+		t.Error("Expected an error for a malformed partition ID")
+	}
+}
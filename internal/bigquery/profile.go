@@ -0,0 +1,208 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// ProfileTopK is the number of most frequent values reported per column
+const ProfileTopK = 5
+
+// TableProfile summarizes the values of a table's columns
+type TableProfile struct {
+	RowCount int64
+	Columns  []*ColumnProfile
+}
+
+// ColumnProfile summarizes one column. Statistics that don't apply to the
+// column's type are nil.
+type ColumnProfile struct {
+	// Path is the dotted name of the column, for fields nested in records
+	Path          string
+	Type          bigquery.FieldType
+	NullCount     int64
+	DistinctCount *int64 // Approximate
+	Min           *string
+	Max           *string
+	AvgLength     *float64
+	TopValues     []TopValue
+}
+
+// NullFraction returns the share of rows where the column is NULL
+func (p *ColumnProfile) NullFraction(rowCount int64) float64 {
+	if rowCount == 0 {
+		return 0
+	}
+	return float64(p.NullCount) / float64(rowCount)
+}
+
+// TopValue is a frequent value and its approximate count
+type TopValue struct {
+	Value string
+	Count int64
+}
+
+// profileColumn is a leaf field that can be profiled, with the SQL expression
+// that reads it
+type profileColumn struct {
+	path string
+	expr string
+	typ  bigquery.FieldType
+}
+
+// profileableColumns flattens non-repeated records and drops fields that
+// aggregate functions can't read, such as arrays
+func profileableColumns(columns []*Column, pathPrefix, exprPrefix string) []profileColumn {
+	var profiled []profileColumn
+	for _, col := range columns {
+		if col.Repeated {
+			continue
+		}
+		path := pathPrefix + col.Name
+		expr := exprPrefix + QuoteIdentifier(col.Name)
+		if col.Type == bigquery.RecordFieldType {
+			profiled = append(profiled, profileableColumns(col.Fields, path+".", expr+".")...)
+			continue
+		}
+		profiled = append(profiled, profileColumn{path: path, expr: expr, typ: col.Type})
+	}
+	return profiled
+}
+
+// groupable reports whether values of the type can be counted by value
+func groupable(typ bigquery.FieldType) bool {
+	switch typ {
+	case bigquery.GeographyFieldType, bigquery.JSONFieldType, bigquery.RangeFieldType, bigquery.IntervalFieldType:
+		return false
+	}
+	return true
+}
+
+// profileQuery builds a single aggregate query computing every statistic of
+// every column. Each column contributes the same six expressions, with NULL
+// for statistics its type doesn't support, so results can be read by position.
+func profileQuery(fullTableName string, columns []profileColumn) string {
+	exprs := []string{"COUNT(*)"}
+	for _, col := range columns {
+		exprs = append(exprs, fmt.Sprintf("COUNTIF(%s IS NULL)", col.expr))
+
+		if groupable(col.typ) {
+			exprs = append(exprs, fmt.Sprintf("APPROX_COUNT_DISTINCT(%s)", col.expr))
+		} else {
+			exprs = append(exprs, "NULL")
+		}
+
+		if groupable(col.typ) && col.typ != bigquery.BytesFieldType {
+			exprs = append(exprs,
+				fmt.Sprintf("CAST(MIN(%s) AS STRING)", col.expr),
+				fmt.Sprintf("CAST(MAX(%s) AS STRING)", col.expr))
+		} else {
+			exprs = append(exprs, "NULL", "NULL")
+		}
+
+		if col.typ == bigquery.StringFieldType || col.typ == bigquery.BytesFieldType {
+			exprs = append(exprs, fmt.Sprintf("AVG(LENGTH(%s))", col.expr))
+		} else {
+			exprs = append(exprs, "NULL")
+		}
+
+		if groupable(col.typ) {
+			exprs = append(exprs, fmt.Sprintf("TO_JSON_STRING(APPROX_TOP_COUNT(%s, %d))", col.expr, ProfileTopK))
+		} else {
+			exprs = append(exprs, "NULL")
+		}
+	}
+
+	return fmt.Sprintf("SELECT\n  %s\nFROM `%s`", strings.Join(exprs, ",\n  "), fullTableName)
+}
+
+// ProfileTable profiles the given columns of a table (all of them when
+// columns is the full schema) with a single query
+func (c *Client) ProfileTable(datasetID, tableID string, columns []*Column) (*TableProfile, error) {
+	profiled := profileableColumns(columns, "", "")
+	if len(profiled) == 0 {
+		return nil, fmt.Errorf("no columns that can be profiled")
+	}
+
+	query := profileQuery(fmt.Sprintf("%s.%s.%s", c.projectID, datasetID, tableID), profiled)
+	q := c.bqClient.Query(query)
+	q.UseStandardSQL = true
+
+	it, err := q.Read(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run profile query: %w", err)
+	}
+	var row []bigquery.Value
+	if err := it.Next(&row); err != nil {
+		if err == iterator.Done {
+			return nil, fmt.Errorf("profile query returned no rows")
+		}
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	return parseProfile(profiled, row)
+}
+
+// parseProfile reads the row of a profile query
+func parseProfile(columns []profileColumn, row []bigquery.Value) (*TableProfile, error) {
+	if len(row) != 1+6*len(columns) {
+		return nil, fmt.Errorf("unexpected profile row with %d values", len(row))
+	}
+
+	rowCount, _ := row[0].(int64)
+	profile := &TableProfile{RowCount: rowCount}
+	for i, col := range columns {
+		values := row[1+6*i : 7+6*i]
+		p := &ColumnProfile{Path: col.path, Type: col.typ}
+		p.NullCount, _ = values[0].(int64)
+		if distinct, ok := values[1].(int64); ok {
+			p.DistinctCount = &distinct
+		}
+		if minValue, ok := values[2].(string); ok {
+			p.Min = &minValue
+		}
+		if maxValue, ok := values[3].(string); ok {
+			p.Max = &maxValue
+		}
+		if avg, ok := values[4].(float64); ok {
+			p.AvgLength = &avg
+		}
+		if top, ok := values[5].(string); ok {
+			topValues, err := parseTopValues(top)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read top values of %s: %w", col.path, err)
+			}
+			p.TopValues = topValues
+		}
+		profile.Columns = append(profile.Columns, p)
+	}
+	return profile, nil
+}
+
+// parseTopValues decodes APPROX_TOP_COUNT results serialized with TO_JSON_STRING
+func parseTopValues(data string) ([]TopValue, error) {
+	var entries []struct {
+		Value json.RawMessage `json:"value"`
+		Count int64           `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, err
+	}
+
+	values := make([]TopValue, 0, len(entries))
+	for _, entry := range entries {
+		value := string(entry.Value)
+		var s string
+		if value == "null" {
+			value = "NULL"
+		} else if json.Unmarshal(entry.Value, &s) == nil {
+			value = s // Unquote strings, dates and timestamps
+		}
+		values = append(values, TopValue{Value: value, Count: entry.Count})
+	}
+	return values, nil
+}
@@ -0,0 +1,58 @@
+package bigquery
+
+import (
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func TestProfileQueryAndParse(t *testing.T) {
+	columns := profileableColumns([]*Column{
+		{Name: "name", Type: bigquery.StringFieldType},
+		{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+		{Name: "geo", Type: bigquery.RecordFieldType, Fields: []*Column{
+			{Name: "location", Type: bigquery.GeographyFieldType},
+		}},
+	}, "", "")
+
+	if len(columns) != 2 || columns[0].path != "name" || columns[1].path != "geo.location" {
+		t.Fatalf("Unexpected profiled columns: %+v", columns)
+	}
+
+	query := profileQuery("proj.ds.people", columns)
+	for _, want := range []string{
+		"COUNTIF(name IS NULL)",
+		"AVG(LENGTH(name))",
+		"TO_JSON_STRING(APPROX_TOP_COUNT(name, 5))",
+		"COUNTIF(geo.location IS NULL)",
+		"FROM `proj.ds.people`",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected profile query to contain %q:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "APPROX_COUNT_DISTINCT(geo.location)") {
+		t.Error("Geography columns can't be counted by value")
+	}
+
+	row := []bigquery.Value{
+		int64(10),
+		int64(2), int64(7), "Ada", "Zoe", 4.5, `[{"value":"Bob","count":3},{"value":null,"count":2}]`,
+		int64(10), nil, nil, nil, nil, nil,
+	}
+	profile, err := parseProfile(columns, row)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	name := profile.Columns[0]
+	if profile.RowCount != 10 || name.NullFraction(profile.RowCount) != 0.2 || *name.DistinctCount != 7 || *name.Min != "Ada" {
+		t.Errorf("Unexpected profile: rows %d, column %+v", profile.RowCount, name)
+	}
+	if len(name.TopValues) != 2 || name.TopValues[0] != (TopValue{Value: "Bob", Count: 3}) || name.TopValues[1].Value != "NULL" {
+		t.Errorf("Unexpected top values: %+v", name.TopValues)
+	}
+	if location := profile.Columns[1]; location.DistinctCount != nil || location.Min != nil {
+		t.Errorf("Expected no distinct count or min for geography, got %+v", location)
+	}
+}
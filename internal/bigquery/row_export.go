@@ -0,0 +1,365 @@
+package bigquery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// RowFormat is a format selected rows can be copied as
+type RowFormat int
+
+const (
+	RowsTSV RowFormat = iota
+	RowsCSV
+	RowsMarkdown
+	RowsJSON
+	RowsInsert
+	RowsInList
+)
+
+// RowFormats lists every row format in menu order
+var RowFormats = []RowFormat{RowsTSV, RowsCSV, RowsMarkdown, RowsJSON, RowsInsert, RowsInList}
+
+func (f RowFormat) String() string {
+	switch f {
+	case RowsTSV:
+		return "TSV with header"
+	case RowsCSV:
+		return "CSV"
+	case RowsMarkdown:
+		return "Markdown table"
+	case RowsJSON:
+		return "JSON array of objects"
+	case RowsInsert:
+		return "SQL INSERT statements"
+	default:
+		return "IN (...) list of the current column"
+	}
+}
+
+// RowSet is a selection of rows to format
+type RowSet struct {
+	// Table is the fully qualified table INSERT statements write to
+	Table   string
+	Headers []string
+	// Fields describes the columns when the schema is known, for typed literals
+	Fields []*Column
+	Rows   [][]interface{}
+	// Column is the column an IN list is built from
+	Column int
+}
+
+// Format renders the rows in a format. It fails when there is nothing to
+// write, such as an IN list of a column that is NULL in every row.
+func (s RowSet) Format(f RowFormat) (string, error) {
+	switch f {
+	case RowsTSV:
+		return s.delimited('\t'), nil
+	case RowsCSV:
+		return s.delimited(','), nil
+	case RowsMarkdown:
+		return s.markdown(), nil
+	case RowsJSON:
+		return s.json(), nil
+	case RowsInsert:
+		return s.inserts(), nil
+	default:
+		return s.inList()
+	}
+}
+
+func (s RowSet) cell(row []interface{}, column int) interface{} {
+	if column < len(row) {
+		return row[column]
+	}
+	return nil
+}
+
+func (s RowSet) field(column int) *Column {
+	if column < len(s.Fields) {
+		return s.Fields[column]
+	}
+	return nil
+}
+
+// delimited writes the header and rows separated by comma or tab, quoting
+// values as CSV does. NULLs are empty.
+func (s RowSet) delimited(comma rune) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	w.Write(s.Headers)
+	for _, row := range s.Rows {
+		record := make([]string, len(s.Headers))
+		for i := range s.Headers {
+			record[i] = cellText(s.field(i), s.cell(row, i))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (s RowSet) markdown() string {
+	escape := strings.NewReplacer("|", `\|`, "\n", "<br>")
+	var b strings.Builder
+	var separators []string
+	for _, header := range s.Headers {
+		separators = append(separators, "---")
+		b.WriteString("| " + escape.Replace(header) + " ")
+	}
+	b.WriteString("|\n| " + strings.Join(separators, " | ") + " |")
+	for _, row := range s.Rows {
+		b.WriteString("\n")
+		for i := range s.Headers {
+			b.WriteString("| " + escape.Replace(cellText(s.field(i), s.cell(row, i))) + " ")
+		}
+		b.WriteString("|")
+	}
+	return b.String()
+}
+
+func (s RowSet) json() string {
+	objects := make([]jsonObject, 0, len(s.Rows))
+	for _, row := range s.Rows {
+		var object jsonObject
+		for i, header := range s.Headers {
+			object.add(header, jsonValue(s.field(i), s.cell(row, i)))
+		}
+		objects = append(objects, object)
+	}
+	data, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return string(data)
+}
+
+// inserts writes one INSERT statement per row
+func (s RowSet) inserts() string {
+	table := s.Table
+	if table == "" {
+		table = "project.dataset.table"
+	}
+	columns := make([]string, len(s.Headers))
+	for i, header := range s.Headers {
+		columns[i] = QuoteIdentifier(header)
+	}
+	prefix := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES", table, strings.Join(columns, ", "))
+
+	statements := make([]string, 0, len(s.Rows))
+	for _, row := range s.Rows {
+		values := make([]string, len(s.Headers))
+		for i := range s.Headers {
+			values[i] = sqlLiteral(s.field(i), s.cell(row, i))
+		}
+		statements = append(statements, fmt.Sprintf("%s (%s);", prefix, strings.Join(values, ", ")))
+	}
+	return strings.Join(statements, "\n")
+}
+
+// inList writes the distinct non-NULL values of the column as an IN list
+func (s RowSet) inList() (string, error) {
+	field := s.field(s.Column)
+	seen := make(map[string]bool)
+	var values []string
+	for _, row := range s.Rows {
+		v := s.cell(row, s.Column)
+		if v == nil {
+			continue // NULL never matches IN
+		}
+		literal := sqlLiteral(field, v)
+		if !seen[literal] {
+			seen[literal] = true
+			values = append(values, literal)
+		}
+	}
+	if len(values) == 0 {
+		// IN () is a syntax error, and no IN list matches NULL anyway
+		column := "the column"
+		if s.Column < len(s.Headers) {
+			column = s.Headers[s.Column]
+		}
+		return "", fmt.Errorf("%s is NULL in every selected row; there is nothing to list", column)
+	}
+	return "IN (" + strings.Join(values, ", ") + ")", nil
+}
+
+// cellText renders a value as plain text, with NULL as the empty string and
+// records and arrays as JSON
+func cellText(field *Column, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case *big.Rat:
+		return ratText(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case []bigquery.Value:
+		data, err := json.Marshal(jsonValue(field, v))
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// ratText renders a NUMERIC or BIGNUMERIC value as a decimal without
+// trailing zeros
+func ratText(r *big.Rat) string {
+	text := r.FloatString(38)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
+
+// jsonValue converts a value for JSON output: records become objects when
+// the schema is known and arrays otherwise
+func jsonValue(field *Column, v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, int64, string:
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprintf("%v", v) // Not representable in JSON
+		}
+		return v
+	case *big.Rat:
+		return json.Number(ratText(v))
+	case []bigquery.Value:
+		if field != nil && !field.Repeated && len(field.Fields) > 0 {
+			var object jsonObject
+			for i, value := range v {
+				if i < len(field.Fields) {
+					object.add(field.Fields[i].Name, jsonValue(field.Fields[i], value))
+				}
+			}
+			return object
+		}
+		element := elementField(field)
+		items := make([]interface{}, len(v))
+		for i, value := range v {
+			items[i] = jsonValue(element, value)
+		}
+		return items
+	}
+	return cellText(field, v)
+}
+
+// jsonObject is a JSON object that keeps its keys in column order
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *jsonObject) add(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// elementField describes the elements of a REPEATED column
+func elementField(field *Column) *Column {
+	if field == nil || !field.Repeated {
+		return nil
+	}
+	element := *field
+	element.Repeated = false
+	return &element
+}
+
+// sqlLiteral writes a value as a GoogleSQL literal, typed from the schema
+// when it's known
+func sqlLiteral(field *Column, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprintf("CAST('%v' AS FLOAT64)", v)
+		}
+		return fmt.Sprintf("%v", v)
+	case *big.Rat:
+		keyword := "NUMERIC"
+		if field != nil && field.Type == bigquery.BigNumericFieldType {
+			keyword = "BIGNUMERIC"
+		}
+		return keyword + " '" + ratText(v) + "'"
+	case time.Time:
+		return "TIMESTAMP '" + v.UTC().Format("2006-01-02 15:04:05.999999") + " UTC'"
+	case []byte:
+		return "FROM_BASE64('" + base64.StdEncoding.EncodeToString(v) + "')"
+	case []bigquery.Value:
+		if field != nil && !field.Repeated && len(field.Fields) > 0 {
+			parts := make([]string, len(v))
+			for i, value := range v {
+				var sub *Column
+				if i < len(field.Fields) {
+					sub = field.Fields[i]
+				}
+				parts[i] = sqlLiteral(sub, value)
+				if sub != nil {
+					parts[i] += " AS " + QuoteIdentifier(sub.Name)
+				}
+			}
+			return "STRUCT(" + strings.Join(parts, ", ") + ")"
+		}
+		element := elementField(field)
+		items := make([]string, len(v))
+		for i, value := range v {
+			items[i] = sqlLiteral(element, value)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	literal := quoteString(fmt.Sprintf("%v", v))
+	if field != nil {
+		switch field.Type {
+		case bigquery.DateFieldType, bigquery.TimeFieldType, bigquery.DateTimeFieldType, bigquery.JSONFieldType:
+			return string(field.Type) + " " + literal
+		}
+	}
+	return literal
+}
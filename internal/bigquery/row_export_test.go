@@ -0,0 +1,65 @@
+package bigquery
+
+import (
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func TestRowSetFormat(t *testing.T) {
+	set := RowSet{
+		Table:   "p.d.t",
+		Headers: []string{"id", "name", "address"},
+		Fields: []*Column{
+			{Name: "id", Type: bigquery.IntegerFieldType},
+			{Name: "name", Type: bigquery.StringFieldType},
+			{Name: "address", Type: bigquery.RecordFieldType, Fields: []*Column{
+				{Name: "city", Type: bigquery.StringFieldType},
+			}},
+		},
+		Rows: [][]interface{}{
+			{int64(1), "O'Neil, Jr", []bigquery.Value{"Paris"}},
+			{int64(2), nil, nil},
+			{int64(1), "a|b", nil},
+		},
+	}
+
+	cases := map[RowFormat]string{
+		RowsTSV: "id\tname\taddress\n1\tO'Neil, Jr\t\"{\"\"city\"\":\"\"Paris\"\"}\"\n2\t\t\n1\ta|b\t",
+		RowsCSV: "id,name,address\n1,\"O'Neil, Jr\",\"{\"\"city\"\":\"\"Paris\"\"}\"\n2,,\n1,a|b,",
+		RowsMarkdown: "| id | name | address |\n| --- | --- | --- |\n" +
+			"| 1 | O'Neil, Jr | {\"city\":\"Paris\"} |\n| 2 |  |  |\n| 1 | a\\|b |  |",
+		RowsInsert: "INSERT INTO `p.d.t` (id, name, address) VALUES (1, 'O\\'Neil, Jr', STRUCT('Paris' AS city));\n" +
+			"INSERT INTO `p.d.t` (id, name, address) VALUES (2, NULL, NULL);\n" +
+			"INSERT INTO `p.d.t` (id, name, address) VALUES (1, 'a|b', NULL);",
+	}
+	for format, want := range cases {
+		if got, err := set.Format(format); err != nil || got != want {
+			t.Errorf("%s:\n got %q\nwant %q", format, got, want)
+		}
+	}
+
+	if got, _ := set.Format(RowsJSON); !strings.Contains(got, `"id": 1,
+    "name": "O'Neil, Jr",
+    "address": {
+      "city": "Paris"
+    }`) {
+		t.Errorf("Unexpected JSON, columns should keep their order:\n%s", got)
+	}
+
+	set.Column = 0
+	if got, _ := set.Format(RowsInList); got != "IN (1, 2)" {
+		t.Errorf("Expected distinct ids, got %s", got)
+	}
+	set.Column = 1
+	if got, _ := set.Format(RowsInList); got != `IN ('O\'Neil, Jr', 'a|b')` {
+		t.Errorf("Expected names without NULL, got %s", got)
+	}
+
+	set.Column = 2
+	set.Rows = set.Rows[1:]
+	if got, err := set.Format(RowsInList); err == nil {
+		t.Errorf("Expected an error for a column that is only NULL, got %s", got)
+	}
+}
@@ -0,0 +1,138 @@
+package bigquery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+)
+
+// WriteDisposition says what happens when query results are saved to a
+// table that already exists
+type WriteDisposition string
+
+const (
+	// WriteEmpty fails unless the table is empty
+	WriteEmpty WriteDisposition = WriteDisposition(bigquery.WriteEmpty)
+	// WriteTruncate replaces the table's rows and schema
+	WriteTruncate WriteDisposition = WriteDisposition(bigquery.WriteTruncate)
+	// WriteAppend adds the rows to the table
+	WriteAppend WriteDisposition = WriteDisposition(bigquery.WriteAppend)
+)
+
+// WriteDispositions lists every write disposition, safest first
+var WriteDispositions = []WriteDisposition{WriteEmpty, WriteTruncate, WriteAppend}
+
+func (d WriteDisposition) String() string {
+	switch d {
+	case WriteTruncate:
+		return "replace its rows"
+	case WriteAppend:
+		return "append to it"
+	default:
+		return "fail unless it's empty"
+	}
+}
+
+// ErrPartitioningChange is returned when replacing a table would change its
+// partitioning, which BigQuery refuses
+var ErrPartitioningChange = errors.New("replacing a table can't change its partitioning")
+
+// MaxClusterFields is the number of columns a table can be clustered by
+const MaxClusterFields = 4
+
+// SaveResultsOptions configures the table query results are saved to
+type SaveResultsOptions struct {
+	WriteDisposition WriteDisposition
+	// PartitionField partitions a new or replaced table by day on a DATE,
+	// DATETIME or TIMESTAMP column; empty leaves it unpartitioned
+	PartitionField string
+	// ClusterFields clusters a new or replaced table by up to MaxClusterFields
+	// columns
+	ClusterFields []string
+}
+
+// SaveQueryResults runs query again with dst as its destination table. An
+// empty dst.ProjectID means the client's project. Partitioning and
+// clustering only apply when the table is created or replaced; rows appended
+// to an existing table keep its layout, and a replaced table must keep its
+// partitioning.
+func (c *Client) SaveQueryResults(query string, dst TableReference, opts SaveResultsOptions) error {
+	if len(opts.ClusterFields) > MaxClusterFields {
+		return fmt.Errorf("tables can be clustered by at most %d columns", MaxClusterFields)
+	}
+	projectID := dst.ProjectID
+	if projectID == "" {
+		projectID = c.projectID
+	}
+
+	q := c.bqClient.Query(query)
+	q.UseStandardSQL = true
+	q.Dst = c.bqClient.DatasetInProject(projectID, dst.DatasetID).Table(dst.TableID)
+	q.CreateDisposition = bigquery.CreateIfNeeded
+	q.WriteDisposition = bigquery.TableWriteDisposition(opts.WriteDisposition)
+	if q.WriteDisposition == "" {
+		q.WriteDisposition = bigquery.WriteEmpty
+	}
+
+	// BigQuery rejects a layout that differs from an existing table's, so it's
+	// only sent when the table is replaced or doesn't exist yet. Replacing a
+	// table can't change its partitioning either, so that is checked up front.
+	truncate := q.WriteDisposition == bigquery.WriteTruncate
+	if opts.PartitionField != "" || len(opts.ClusterFields) > 0 || truncate {
+		metadata, err := q.Dst.Metadata(c.ctx)
+		var apiErr *googleapi.Error
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound) {
+			return fmt.Errorf("failed to check destination table: %w", err)
+		}
+		exists := err == nil
+		if exists && truncate {
+			if err := checkReplacePartitioning(partitioningOf(metadata), opts.PartitionField); err != nil {
+				return err
+			}
+		}
+		setLayout := !exists || truncate
+		if setLayout && opts.PartitionField != "" {
+			q.TimePartitioning = &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: opts.PartitionField}
+		}
+		if setLayout && len(opts.ClusterFields) > 0 {
+			q.Clustering = &bigquery.Clustering{Fields: opts.ClusterFields}
+		}
+	}
+
+	job, err := q.Run(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run query: %w", err)
+	}
+	status, err := job.Wait(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for job completion: %w", err)
+	}
+	if status.Err() != nil {
+		return fmt.Errorf("query failed: %w", status.Err())
+	}
+	return nil
+}
+
+// checkReplacePartitioning fails unless a table partitioned as existing (nil
+// when it isn't) can be replaced by one partitioned by day on field, as
+// BigQuery refuses to change partitioning when it replaces a table
+func checkReplacePartitioning(existing *Partitioning, field string) error {
+	switch {
+	case existing == nil && field == "":
+		return nil
+	case existing == nil:
+		return fmt.Errorf("%w: the table isn't partitioned; clear the partition column or drop the table first", ErrPartitioningChange)
+	case existing.Type == string(bigquery.DayPartitioningType) && existing.Field != "" && existing.Field == field:
+		return nil
+	}
+
+	column := existing.Field
+	if column == "" {
+		column = "ingestion time"
+	}
+	current := fmt.Sprintf("%s (%s)", column, existing.Type)
+	return fmt.Errorf("%w: the table is partitioned by %s; partition by the same column or drop the table first", ErrPartitioningChange, current)
+}
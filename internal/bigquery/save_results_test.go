@@ -0,0 +1,33 @@
+package bigquery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckReplacePartitioning(t *testing.T) {
+	daily := &Partitioning{Type: "DAY", Field: "day"}
+	tests := []struct {
+		name     string
+		existing *Partitioning
+		field    string
+		ok       bool
+	}{
+		{"unpartitioned", nil, "", true},
+		{"same column", daily, "day", true},
+		{"add partitioning", nil, "day", false},
+		{"drop partitioning", daily, "", false},
+		{"other column", daily, "created", false},
+		{"monthly", &Partitioning{Type: "MONTH", Field: "day"}, "day", false},
+		{"ingestion time", &Partitioning{Type: "DAY"}, "", false},
+	}
+	for _, tt := range tests {
+		err := checkReplacePartitioning(tt.existing, tt.field)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrPartitioningChange) {
+			t.Errorf("%s: expected ErrPartitioningChange, got %v", tt.name, err)
+		}
+	}
+}
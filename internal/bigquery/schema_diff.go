@@ -0,0 +1,98 @@
+package bigquery
+
+import "strings"
+
+// FieldChange classifies a difference between two schemas
+type FieldChange int
+
+const (
+	FieldAdded FieldChange = iota
+	FieldRemoved
+	FieldModified
+)
+
+func (c FieldChange) String() string {
+	switch c {
+	case FieldAdded:
+		return "added"
+	case FieldRemoved:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// FieldDiff is one field that differs between two schemas. Path is the dotted
+// name of the field, Old is nil for added fields and New for removed ones.
+type FieldDiff struct {
+	Path   string
+	Change FieldChange
+	Old    *Column
+	New    *Column
+}
+
+// Mode returns the field's BigQuery mode: NULLABLE, REQUIRED or REPEATED
+func (c *Column) Mode() string {
+	switch {
+	case c.Repeated:
+		return "REPEATED"
+	case c.Required:
+		return "REQUIRED"
+	default:
+		return "NULLABLE"
+	}
+}
+
+// DiffSchemas lists the fields added, removed, or changed in type or mode
+// going from one schema to another, descending into nested records. Field
+// names are compared case-insensitively, as BigQuery does.
+func DiffSchemas(from, to *TableSchema) []FieldDiff {
+	var oldFields, newFields []*Column
+	if from != nil {
+		oldFields = from.Fields
+	}
+	if to != nil {
+		newFields = to.Fields
+	}
+	return diffFields("", oldFields, newFields)
+}
+
+func diffFields(prefix string, oldFields, newFields []*Column) []FieldDiff {
+	newByName := make(map[string]*Column, len(newFields))
+	for _, field := range newFields {
+		newByName[foldName(field.Name)] = field
+	}
+
+	var diffs []FieldDiff
+	seen := make(map[string]bool, len(oldFields))
+	for _, oldField := range oldFields {
+		name := foldName(oldField.Name)
+		seen[name] = true
+		path := prefix + oldField.Name
+
+		newField, ok := newByName[name]
+		if !ok {
+			diffs = append(diffs, FieldDiff{Path: path, Change: FieldRemoved, Old: oldField})
+			continue
+		}
+		if oldField.Type != newField.Type || oldField.Mode() != newField.Mode() {
+			diffs = append(diffs, FieldDiff{Path: path, Change: FieldModified, Old: oldField, New: newField})
+		}
+		// Records that stayed records are compared field by field
+		if len(oldField.Fields) > 0 && len(newField.Fields) > 0 {
+			diffs = append(diffs, diffFields(path+".", oldField.Fields, newField.Fields)...)
+		}
+	}
+
+	for _, newField := range newFields {
+		if !seen[foldName(newField.Name)] {
+			diffs = append(diffs, FieldDiff{Path: prefix + newField.Name, Change: FieldAdded, New: newField})
+		}
+	}
+
+	return diffs
+}
+
+func foldName(name string) string {
+	return strings.ToLower(name)
+}
@@ -0,0 +1,47 @@
+package bigquery
+
+import (
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	from := &TableSchema{Fields: []*Column{
+		{Name: "id", Type: bigquery.IntegerFieldType, Required: true},
+		{Name: "name", Type: bigquery.StringFieldType},
+		{Name: "address", Type: bigquery.RecordFieldType, Fields: []*Column{
+			{Name: "city", Type: bigquery.StringFieldType},
+			{Name: "zip", Type: bigquery.IntegerFieldType},
+		}},
+	}}
+	to := &TableSchema{Fields: []*Column{
+		{Name: "id", Type: bigquery.IntegerFieldType},
+		{Name: "Address", Type: bigquery.RecordFieldType, Fields: []*Column{
+			{Name: "city", Type: bigquery.StringFieldType},
+			{Name: "zip", Type: bigquery.StringFieldType},
+			{Name: "country", Type: bigquery.StringFieldType},
+		}},
+		{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+	}}
+
+	var got []string
+	for _, diff := range DiffSchemas(from, to) {
+		got = append(got, fmt.Sprintf("%s %s", diff.Change, diff.Path))
+	}
+	want := []string{
+		"changed id",
+		"removed name",
+		"changed address.zip",
+		"added address.country",
+		"added tags",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("DiffSchemas() = %v, want %v", got, want)
+	}
+
+	if diffs := DiffSchemas(to, to); len(diffs) != 0 {
+		t.Errorf("Expected no differences between identical schemas, got %d", len(diffs))
+	}
+}
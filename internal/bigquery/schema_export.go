@@ -0,0 +1,546 @@
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// SchemaFormat is a format a table schema can be exported as
+type SchemaFormat int
+
+const (
+	SchemaJSON SchemaFormat = iota
+	SchemaDDL
+	SchemaGoStruct
+	SchemaProtobuf
+	SchemaPythonDataclass
+)
+
+// SchemaFormats lists every export format in menu order
+var SchemaFormats = []SchemaFormat{SchemaJSON, SchemaDDL, SchemaGoStruct, SchemaProtobuf, SchemaPythonDataclass}
+
+func (f SchemaFormat) String() string {
+	switch f {
+	case SchemaJSON:
+		return "JSON schema (bq)"
+	case SchemaDDL:
+		return "CREATE TABLE DDL"
+	case SchemaGoStruct:
+		return "Go struct"
+	case SchemaProtobuf:
+		return "Protobuf message"
+	default:
+		return "Python dataclass"
+	}
+}
+
+// Extension returns the file extension for exported files, including the dot
+func (f SchemaFormat) Extension() string {
+	switch f {
+	case SchemaJSON:
+		return ".json"
+	case SchemaDDL:
+		return ".sql"
+	case SchemaGoStruct:
+		return ".go"
+	case SchemaProtobuf:
+		return ".proto"
+	default:
+		return ".py"
+	}
+}
+
+// ExportSchema renders a table's schema in the given format
+func ExportSchema(schema *TableSchema, format SchemaFormat, projectID, datasetID, tableID string) (string, error) {
+	if schema == nil {
+		return "", fmt.Errorf("no schema to export")
+	}
+
+	switch format {
+	case SchemaJSON:
+		return exportJSON(schema.Fields)
+	case SchemaDDL:
+		return exportDDL(schema.Fields, fmt.Sprintf("%s.%s.%s", projectID, datasetID, tableID)), nil
+	case SchemaGoStruct:
+		return exportGo(schema.Fields, datasetID, tableID), nil
+	case SchemaProtobuf:
+		return exportProto(schema.Fields, tableID), nil
+	case SchemaPythonDataclass:
+		return exportPython(schema.Fields, tableID), nil
+	}
+	return "", fmt.Errorf("unknown schema format %d", format)
+}
+
+// jsonField is a field of a `bq` schema file, as read by `bq mk --schema`
+type jsonField struct {
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Mode        string       `json:"mode"`
+	Description string       `json:"description,omitempty"`
+	Fields      []*jsonField `json:"fields,omitempty"`
+}
+
+func exportJSON(columns []*Column) (string, error) {
+	data, err := json.MarshalIndent(toJSONFields(columns), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+func toJSONFields(columns []*Column) []*jsonField {
+	fields := make([]*jsonField, 0, len(columns))
+	for _, col := range columns {
+		fields = append(fields, &jsonField{
+			Name:        col.Name,
+			Type:        string(col.Type),
+			Mode:        col.Mode(),
+			Description: col.Description,
+			Fields:      toJSONFields(col.Fields),
+		})
+	}
+	return fields
+}
+
+// reservedKeywords can't be used as unquoted identifiers in GoogleSQL
+var reservedKeywords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "ARRAY": true, "AS": true, "ASC": true,
+	"ASSERT_ROWS_MODIFIED": true, "AT": true, "BETWEEN": true, "BY": true, "CASE": true,
+	"CAST": true, "COLLATE": true, "CONTAINS": true, "CREATE": true, "CROSS": true,
+	"CUBE": true, "CURRENT": true, "DEFAULT": true, "DEFINE": true, "DESC": true,
+	"DISTINCT": true, "ELSE": true, "END": true, "ENUM": true, "ESCAPE": true,
+	"EXCEPT": true, "EXCLUDE": true, "EXISTS": true, "EXTRACT": true, "FALSE": true,
+	"FETCH": true, "FOLLOWING": true, "FOR": true, "FROM": true, "FULL": true,
+	"GROUP": true, "GROUPING": true, "GROUPS": true, "HASH": true, "HAVING": true,
+	"IF": true, "IGNORE": true, "IN": true, "INNER": true, "INTERSECT": true,
+	"INTERVAL": true, "INTO": true, "IS": true, "JOIN": true, "LATERAL": true,
+	"LEFT": true, "LIKE": true, "LIMIT": true, "LOOKUP": true, "MERGE": true,
+	"NATURAL": true, "NEW": true, "NO": true, "NOT": true, "NULL": true, "NULLS": true,
+	"OF": true, "ON": true, "OR": true, "ORDER": true, "OUTER": true, "OVER": true,
+	"PARTITION": true, "PRECEDING": true, "PROTO": true, "QUALIFY": true, "RANGE": true,
+	"RECURSIVE": true, "RESPECT": true, "RIGHT": true, "ROLLUP": true, "ROWS": true,
+	"SELECT": true, "SET": true, "SOME": true, "STRUCT": true, "TABLESAMPLE": true,
+	"THEN": true, "TO": true, "TREAT": true, "TRUE": true, "UNBOUNDED": true,
+	"UNION": true, "UNNEST": true, "USING": true, "WHEN": true, "WHERE": true,
+	"WINDOW": true, "WITH": true, "WITHIN": true,
+}
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteIdentifier backquotes a GoogleSQL identifier when it isn't a plain,
+// unreserved name
+func QuoteIdentifier(name string) string {
+	if plainIdentifier.MatchString(name) && !reservedKeywords[strings.ToUpper(name)] {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+func exportDDL(columns []*Column, fullTableName string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE `%s`\n(\n", fullTableName)
+	for i, col := range columns {
+		b.WriteString("  " + ddlColumn(col))
+		if i < len(columns)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// ddlColumn renders a column definition, as used both for top-level columns
+// and inside STRUCT<...>
+func ddlColumn(col *Column) string {
+	def := QuoteIdentifier(col.Name) + " " + ddlType(col)
+	if col.Required {
+		def += " NOT NULL"
+	}
+	if col.Description != "" {
+		def += fmt.Sprintf(" OPTIONS(description=%s)", strconv.Quote(col.Description))
+	}
+	return def
+}
+
+func ddlType(col *Column) string {
+	var typ string
+	switch col.Type {
+	case bigquery.RecordFieldType:
+		fields := make([]string, 0, len(col.Fields))
+		for _, field := range col.Fields {
+			fields = append(fields, ddlColumn(field))
+		}
+		typ = "STRUCT<" + strings.Join(fields, ", ") + ">"
+	case bigquery.IntegerFieldType:
+		typ = "INT64"
+	case bigquery.FloatFieldType:
+		typ = "FLOAT64"
+	case bigquery.BooleanFieldType:
+		typ = "BOOL"
+	default:
+		typ = string(col.Type)
+	}
+	if col.Repeated {
+		return "ARRAY<" + typ + ">"
+	}
+	return typ
+}
+
+// goInitialisms are spelled in capitals in Go names, per Go naming conventions
+var goInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "API": true, "HTTP": true, "JSON": true,
+	"SQL": true, "UUID": true, "IP": true, "UTC": true,
+}
+
+// camelCase turns a BigQuery name into an exported CamelCase identifier
+func camelCase(name string, initialisms bool) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms && goInitialisms[strings.ToUpper(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		b.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "F" + ident
+	}
+	return ident
+}
+
+// snakeIdentifier replaces characters that aren't valid in identifiers
+func snakeIdentifier(name string) string {
+	ident := strings.Map(func(r rune) rune {
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, name)
+	if ident == "" || unicode.IsDigit(rune(ident[0])) {
+		ident = "_" + ident
+	}
+	return ident
+}
+
+// structDef is a generated struct, message or class with its fields
+type structDef struct {
+	name   string
+	fields []*Column
+}
+
+// collectStructs lists the type for the top-level fields followed by one for
+// every nested record, named after the path to it
+func collectStructs(name string, fields []*Column, nameOf func(parent string, col *Column) string) []structDef {
+	defs := []structDef{{name: name, fields: fields}}
+	for _, col := range fields {
+		if col.Type == bigquery.RecordFieldType {
+			defs = append(defs, collectStructs(nameOf(name, col), col.Fields, nameOf)...)
+		}
+	}
+	return defs
+}
+
+func exportGo(columns []*Column, datasetID, tableID string) string {
+	typeName := camelCase(tableID, true)
+	nestedName := func(parent string, col *Column) string { return parent + camelCase(col.Name, true) }
+	defs := collectStructs(typeName, columns, nestedName)
+
+	imports := make(map[string]bool)
+	var body strings.Builder
+	for _, def := range defs {
+		fmt.Fprintf(&body, "\ntype %s struct {\n", def.name)
+		for _, col := range def.fields {
+			typ, pkg := goType(col, nestedName(def.name, col))
+			if pkg != "" {
+				imports[pkg] = true
+			}
+			fmt.Fprintf(&body, "\t%s %s `bigquery:%q`\n", camelCase(col.Name, true), typ, col.Name)
+		}
+		body.WriteString("}\n")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n", strings.ToLower(snakeIdentifier(datasetID)))
+	if len(imports) > 0 {
+		pkgs := make([]string, 0, len(imports))
+		for pkg := range imports {
+			pkgs = append(pkgs, pkg)
+		}
+		// Standard library imports go first, in their own group
+		sort.Slice(pkgs, func(i, j int) bool {
+			iStd, jStd := !strings.Contains(pkgs[i], "."), !strings.Contains(pkgs[j], ".")
+			if iStd != jStd {
+				return iStd
+			}
+			return pkgs[i] < pkgs[j]
+		})
+		b.WriteString("\nimport (\n")
+		for i, pkg := range pkgs {
+			if i > 0 && !strings.Contains(pkgs[i-1], ".") && strings.Contains(pkg, ".") {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "\t%q\n", pkg)
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString(body.String())
+
+	// Align the fields the way gofmt would
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
+
+// goType maps a column to the Go type the BigQuery client reads it into,
+// using the client's Null* types for nullable scalars
+func goType(col *Column, recordType string) (typ, pkg string) {
+	const bq = "cloud.google.com/go/bigquery"
+	const civil = "cloud.google.com/go/civil"
+
+	nullable := !col.Required && !col.Repeated
+	switch col.Type {
+	case bigquery.RecordFieldType:
+		typ = recordType
+		if nullable {
+			typ = "*" + typ
+		}
+	case bigquery.StringFieldType:
+		typ, pkg = "string", ""
+		if nullable {
+			typ, pkg = "bigquery.NullString", bq
+		}
+	case bigquery.IntegerFieldType:
+		typ, pkg = "int64", ""
+		if nullable {
+			typ, pkg = "bigquery.NullInt64", bq
+		}
+	case bigquery.FloatFieldType:
+		typ, pkg = "float64", ""
+		if nullable {
+			typ, pkg = "bigquery.NullFloat64", bq
+		}
+	case bigquery.BooleanFieldType:
+		typ, pkg = "bool", ""
+		if nullable {
+			typ, pkg = "bigquery.NullBool", bq
+		}
+	case bigquery.TimestampFieldType:
+		typ, pkg = "time.Time", "time"
+		if nullable {
+			typ, pkg = "bigquery.NullTimestamp", bq
+		}
+	case bigquery.DateFieldType:
+		typ, pkg = "civil.Date", civil
+		if nullable {
+			typ, pkg = "bigquery.NullDate", bq
+		}
+	case bigquery.TimeFieldType:
+		typ, pkg = "civil.Time", civil
+		if nullable {
+			typ, pkg = "bigquery.NullTime", bq
+		}
+	case bigquery.DateTimeFieldType:
+		typ, pkg = "civil.DateTime", civil
+		if nullable {
+			typ, pkg = "bigquery.NullDateTime", bq
+		}
+	case bigquery.GeographyFieldType:
+		typ, pkg = "string", ""
+		if nullable {
+			typ, pkg = "bigquery.NullGeography", bq
+		}
+	case bigquery.JSONFieldType:
+		typ, pkg = "string", ""
+		if nullable {
+			typ, pkg = "bigquery.NullJSON", bq
+		}
+	case bigquery.NumericFieldType, bigquery.BigNumericFieldType:
+		typ, pkg = "*big.Rat", "math/big"
+	case bigquery.BytesFieldType:
+		typ = "[]byte"
+	case bigquery.IntervalFieldType:
+		typ, pkg = "*bigquery.IntervalValue", bq
+	case bigquery.RangeFieldType:
+		typ, pkg = "*bigquery.RangeValue", bq
+	default:
+		typ = "bigquery.Value"
+		pkg = bq
+	}
+
+	if col.Repeated {
+		typ = "[]" + typ
+	}
+	return typ, pkg
+}
+
+func exportProto(columns []*Column, tableID string) string {
+	var b strings.Builder
+	b.WriteString("syntax = \"proto2\";\n\n")
+	writeProtoMessage(&b, camelCase(tableID, false), columns, "")
+	return b.String()
+}
+
+// writeProtoMessage writes a message in the layout the Storage Write API
+// expects, with nested records as nested messages
+func writeProtoMessage(b *strings.Builder, name string, columns []*Column, indent string) {
+	fmt.Fprintf(b, "%smessage %s {\n", indent, name)
+	for _, col := range columns {
+		if col.Type == bigquery.RecordFieldType {
+			writeProtoMessage(b, camelCase(col.Name, false), col.Fields, indent+"  ")
+		}
+	}
+	for i, col := range columns {
+		label := "optional"
+		switch {
+		case col.Repeated:
+			label = "repeated"
+		case col.Required:
+			label = "required"
+		}
+		fmt.Fprintf(b, "%s  %s %s %s = %d;\n", indent, label, protoType(col), snakeIdentifier(col.Name), i+1)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// protoType maps a column to the protobuf type the Storage Write API accepts for it
+func protoType(col *Column) string {
+	switch col.Type {
+	case bigquery.RecordFieldType:
+		return camelCase(col.Name, false)
+	case bigquery.IntegerFieldType, bigquery.TimestampFieldType:
+		return "int64" // Timestamps as microseconds since the epoch
+	case bigquery.DateFieldType:
+		return "int32" // Days since the epoch
+	case bigquery.FloatFieldType:
+		return "double"
+	case bigquery.BooleanFieldType:
+		return "bool"
+	case bigquery.BytesFieldType:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+// pythonKeywords can't be used as attribute names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+	"for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+	"is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+func exportPython(columns []*Column, tableID string) string {
+	nestedName := func(parent string, col *Column) string { return parent + camelCase(col.Name, false) }
+	defs := collectStructs(camelCase(tableID, false), columns, nestedName)
+
+	var body strings.Builder
+	imports := make(map[string]bool)
+	typingNames := make(map[string]bool)
+	needsField := false
+
+	// Nested classes come first so every annotation refers to a defined class
+	for i := len(defs) - 1; i >= 0; i-- {
+		def := defs[i]
+		fmt.Fprintf(&body, "\n\n@dataclass\nclass %s:\n", def.name)
+		if len(def.fields) == 0 {
+			body.WriteString("    pass\n")
+		}
+
+		// Fields with defaults must follow those without
+		var required, optional []string
+		for _, col := range def.fields {
+			name := snakeIdentifier(col.Name)
+			if pythonKeywords[name] {
+				name += "_"
+			}
+			typ := pythonType(col, nestedName(def.name, col), imports, typingNames)
+			switch {
+			case col.Repeated:
+				needsField = true
+				typingNames["List"] = true
+				optional = append(optional, fmt.Sprintf("    %s: List[%s] = field(default_factory=list)\n", name, typ))
+			case col.Required:
+				required = append(required, fmt.Sprintf("    %s: %s\n", name, typ))
+			default:
+				typingNames["Optional"] = true
+				optional = append(optional, fmt.Sprintf("    %s: Optional[%s] = None\n", name, typ))
+			}
+		}
+		body.WriteString(strings.Join(required, "") + strings.Join(optional, ""))
+	}
+
+	lines := make([]string, 0, len(imports)+2)
+	for line := range imports {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	if needsField {
+		lines = append(lines, "from dataclasses import dataclass, field")
+	} else {
+		lines = append(lines, "from dataclasses import dataclass")
+	}
+	if len(typingNames) > 0 {
+		names := make([]string, 0, len(typingNames))
+		for name := range typingNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines = append(lines, "from typing import "+strings.Join(names, ", "))
+	}
+
+	return strings.Join(lines, "\n") + "\n" + body.String()
+}
+
+// pythonType maps a column to the Python type the BigQuery client returns for it
+func pythonType(col *Column, recordType string, imports, typingNames map[string]bool) string {
+	switch col.Type {
+	case bigquery.RecordFieldType:
+		return recordType
+	case bigquery.StringFieldType, bigquery.GeographyFieldType:
+		return "str"
+	case bigquery.IntegerFieldType:
+		return "int"
+	case bigquery.FloatFieldType:
+		return "float"
+	case bigquery.BooleanFieldType:
+		return "bool"
+	case bigquery.BytesFieldType:
+		return "bytes"
+	case bigquery.TimestampFieldType, bigquery.DateTimeFieldType:
+		imports["import datetime"] = true
+		return "datetime.datetime"
+	case bigquery.DateFieldType:
+		imports["import datetime"] = true
+		return "datetime.date"
+	case bigquery.TimeFieldType:
+		imports["import datetime"] = true
+		return "datetime.time"
+	case bigquery.NumericFieldType, bigquery.BigNumericFieldType:
+		imports["import decimal"] = true
+		return "decimal.Decimal"
+	default:
+		typingNames["Any"] = true
+		return "Any"
+	}
+}
@@ -0,0 +1,38 @@
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+)
+
+func TestExportSchemaDDL(t *testing.T) {
+	schema := &TableSchema{Fields: []*Column{
+		{Name: "id", Type: bigquery.IntegerFieldType, Required: true, Description: `Primary "key"`},
+		{Name: "order", Type: bigquery.StringFieldType},
+		{Name: "items", Type: bigquery.RecordFieldType, Repeated: true, Fields: []*Column{
+			{Name: "sku", Type: bigquery.StringFieldType, Required: true},
+			{Name: "price", Type: bigquery.FloatFieldType},
+		}},
+	}}
+
+	got, err := ExportSchema(schema, SchemaDDL, "proj", "shop", "orders")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "CREATE TABLE `proj.shop.orders`\n(\n" +
+		"  id INT64 NOT NULL OPTIONS(description=\"Primary \\\"key\\\"\"),\n" +
+		"  `order` STRING,\n" +
+		"  items ARRAY<STRUCT<sku STRING NOT NULL, price FLOAT64>>\n" +
+		");\n"
+	if got != want {
+		t.Errorf("ExportSchema(DDL) =\n%s\nwant\n%s", got, want)
+	}
+
+	// Every format renders nested records without error
+	for _, format := range SchemaFormats {
+		if out, err := ExportSchema(schema, format, "proj", "shop", "orders"); err != nil || out == "" {
+			t.Errorf("ExportSchema(%s) failed: %v", format, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package bigquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// ShardedTableType is the Type of a list entry standing for a family of
+// date-sharded tables
+const ShardedTableType = "SHARDED"
+
+// shardLayouts are the date suffixes recognized on sharded tables, as in
+// events_20240131 and logs_2024_01_31
+var shardLayouts = []string{"20060102", "2006_01_02"}
+
+// ShardSet is a family of date-sharded tables such as events_20240101,
+// events_20240102, ... that can be queried together as the wildcard table
+// events_* and narrowed with _TABLE_SUFFIX
+type ShardSet struct {
+	// Prefix is the table ID without the date suffix, e.g. "events_"
+	Prefix string
+	// Layout is the date format of the suffixes
+	Layout string
+	// Tables are the shards, oldest first
+	Tables []*Table
+}
+
+// SplitShardID splits a date-sharded table ID into its prefix and date
+// suffix, reporting the suffix's layout. ok is false for other tables.
+func SplitShardID(tableID string) (prefix, suffix, layout string, ok bool) {
+	for _, layout := range shardLayouts {
+		n := len(layout)
+		if len(tableID) <= n || tableID[len(tableID)-n-1] != '_' {
+			continue
+		}
+		suffix := tableID[len(tableID)-n:]
+		if _, err := time.Parse(layout, suffix); err == nil {
+			return tableID[:len(tableID)-n], suffix, layout, true
+		}
+	}
+	return "", "", "", false
+}
+
+// Wildcard returns the wildcard table ID covering every shard
+func (s *ShardSet) Wildcard() string {
+	return s.Prefix + "*"
+}
+
+// Suffix returns the date suffix of one of the shards
+func (s *ShardSet) Suffix(table *Table) string {
+	return strings.TrimPrefix(table.ID, s.Prefix)
+}
+
+// Newest returns the most recent shard
+func (s *ShardSet) Newest() *Table {
+	return s.Tables[len(s.Tables)-1]
+}
+
+// DateRange returns the dates of the oldest and newest shards
+func (s *ShardSet) DateRange() (first, last time.Time) {
+	first, _ = time.Parse(s.Layout, s.Suffix(s.Tables[0]))
+	last, _ = time.Parse(s.Layout, s.Suffix(s.Newest()))
+	return first, last
+}
+
+// SuffixFilter returns a WHERE condition restricting a query over the
+// wildcard table to the shards between two suffixes, inclusive. Queries over a
+// wildcard should always carry one: events_* also matches other families with
+// the same prefix, such as events_intraday_*, whose suffixes sort after any
+// date and so fall outside the range.
+func SuffixFilter(from, to string) string {
+	if from > to {
+		from, to = to, from
+	}
+	if from == to {
+		return fmt.Sprintf("_TABLE_SUFFIX = '%s'", from)
+	}
+	return fmt.Sprintf("_TABLE_SUFFIX BETWEEN '%s' AND '%s'", from, to)
+}
+
+// GroupShards replaces each family of two or more date shards in a table list
+// with a single entry of type ShardedTableType, named after the wildcard and
+// placed where its first shard was. Other tables are returned unchanged.
+func GroupShards(tables []*Table) []*Table {
+	type key struct{ datasetID, prefix, layout string }
+	sets := make(map[key]*ShardSet)
+	for _, table := range tables {
+		prefix, _, layout, ok := SplitShardID(table.ID)
+		if !ok {
+			continue
+		}
+		k := key{table.DatasetID, prefix, layout}
+		if sets[k] == nil {
+			sets[k] = &ShardSet{Prefix: prefix, Layout: layout}
+		}
+		sets[k].Tables = append(sets[k].Tables, table)
+	}
+
+	grouped := make([]*Table, 0, len(tables))
+	added := make(map[key]bool)
+	for _, table := range tables {
+		prefix, _, layout, ok := SplitShardID(table.ID)
+		k := key{table.DatasetID, prefix, layout}
+		if !ok || len(sets[k].Tables) < 2 {
+			grouped = append(grouped, table)
+			continue
+		}
+		if added[k] {
+			continue
+		}
+		added[k] = true
+
+		set := sets[k]
+		sort.Slice(set.Tables, func(i, j int) bool { return set.Tables[i].ID < set.Tables[j].ID })
+		grouped = append(grouped, &Table{
+			ID:            set.Wildcard(),
+			DatasetID:     table.DatasetID,
+			ProjectID:     table.ProjectID,
+			Type:          ShardedTableType,
+			Shards:        set,
+			DetailsLoaded: true, // Nothing to fetch for the wildcard itself
+		})
+	}
+	return grouped
+}
+
+// ListShards lists the shards of a date-sharded table family with their
+// sizes, newest first. Partition IDs are the shards' date suffixes.
+func (c *Client) ListShards(datasetID, prefix string) ([]*Partition, error) {
+	// __TABLES__ holds the sizes of every table in the dataset, so one cheap
+	// metadata query covers all shards
+	query := fmt.Sprintf("SELECT table_id, row_count, size_bytes, last_modified_time FROM `%s.%s.__TABLES__` WHERE STARTS_WITH(table_id, @prefix)",
+		c.projectID, datasetID)
+
+	q := c.bqClient.Query(query)
+	q.UseStandardSQL = true
+	q.Parameters = []bigquery.QueryParameter{{Name: "prefix", Value: prefix}}
+
+	it, err := q.Read(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shards of %s*: %w", prefix, err)
+	}
+
+	var shards []*Partition
+	for {
+		var row struct {
+			TableID          string `bigquery:"table_id"`
+			RowCount         int64  `bigquery:"row_count"`
+			SizeBytes        int64  `bigquery:"size_bytes"`
+			LastModifiedTime int64  `bigquery:"last_modified_time"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shards of %s*: %w", prefix, err)
+		}
+
+		// Skip tables that only share the prefix, like events_intraday_20240101
+		// next to events_20240101
+		shardPrefix, suffix, _, ok := SplitShardID(row.TableID)
+		if !ok || shardPrefix != prefix {
+			continue
+		}
+		shards = append(shards, &Partition{
+			ID:           suffix,
+			Rows:         row.RowCount,
+			Bytes:        row.SizeBytes,
+			LastModified: time.UnixMilli(row.LastModifiedTime),
+		})
+	}
+
+	sort.Slice(shards, func(i, j int) bool { return shards[i].ID > shards[j].ID })
+	return shards, nil
+}
@@ -0,0 +1,38 @@
+package bigquery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupShards(t *testing.T) {
+	var tables []*Table
+	for _, id := range []string{"customers", "events_20240102", "events_20240101", "events_intraday_20240102", "logs_2024_01_31", "logs_2024_02_01", "users_20240101"} {
+		tables = append(tables, &Table{ID: id, DatasetID: "analytics"})
+	}
+
+	grouped := GroupShards(tables)
+	var ids []string
+	for _, table := range grouped {
+		ids = append(ids, table.ID)
+	}
+	// A lone shard is not worth a group
+	if want := "customers events_* events_intraday_20240102 logs_* users_20240101"; strings.Join(ids, " ") != want {
+		t.Fatalf("Expected %q, got %q", want, strings.Join(ids, " "))
+	}
+
+	events := grouped[1]
+	if events.Type != ShardedTableType || events.Shards.Prefix != "events_" || events.Shards.Newest().ID != "events_20240102" {
+		t.Errorf("Unexpected events group: %+v", events.Shards)
+	}
+	if first, last := grouped[3].Shards.DateRange(); first.Format("2006-01-02") != "2024-01-31" || last.Format("2006-01-02") != "2024-02-01" {
+		t.Errorf("Unexpected logs date range: %s to %s", first, last)
+	}
+
+	if _, _, _, ok := SplitShardID("events_20241350"); ok {
+		t.Error("Expected an invalid date not to be treated as a shard")
+	}
+	if got := SuffixFilter("20240131", "20240101"); got != "_TABLE_SUFFIX BETWEEN '20240101' AND '20240131'" {
+		t.Errorf("Unexpected suffix filter %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package bigquery
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TableReference names a table, or a dataset when TableID is empty. An empty
+// ProjectID means the current project.
+type TableReference struct {
+	ProjectID string
+	DatasetID string
+	TableID   string
+}
+
+// consoleResourcePattern finds the project, dataset and (optional) table in
+// the "ws" parameter of a BigQuery console URL, e.g. !1m5!1m4!4m3!1sp!2sd!3st
+var consoleResourcePattern = regexp.MustCompile(`!1s([^!]+)!2s([^!]+)(?:!3s([^!]+))?`)
+
+// ParseTableReference reads a table name as written in SQL
+// (project.dataset.table or dataset.table, optionally in backticks), as the bq
+// CLI writes it (project:dataset.table), or a BigQuery console URL
+func ParseTableReference(text string) (TableReference, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "https://") || strings.HasPrefix(text, "http://") {
+		return parseConsoleURL(text)
+	}
+
+	name := strings.Trim(text, "`")
+	var ref TableReference
+	// Domain-scoped projects contain a colon themselves (example.com:project),
+	// so the dataset starts after the last one
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		ref.ProjectID, name = name[:i], name[i+1:]
+	}
+
+	parts := strings.Split(name, ".")
+	if len(parts) == 3 {
+		if ref.ProjectID != "" {
+			ref.ProjectID += ":" + parts[0] // example.com:project.dataset.table
+		} else {
+			ref.ProjectID = parts[0]
+		}
+		parts = parts[1:]
+	}
+	// A project and dataset alone (project:dataset) name a dataset
+	if len(parts) > 2 || (len(parts) == 1 && ref.ProjectID == "") ||
+		slices.Contains(parts, "") || strings.HasSuffix(ref.ProjectID, ":") {
+		return TableReference{}, fmt.Errorf("%q is not a table name; expected project.dataset.table, project:dataset.table or a BigQuery console URL", text)
+	}
+	ref.DatasetID = parts[0]
+	if len(parts) == 2 {
+		ref.TableID = parts[1]
+	}
+	return ref, nil
+}
+
+// parseConsoleURL reads the table a BigQuery console link points at. Current
+// links name it in the "ws" parameter, older ones in "p", "d" and "t", and
+// the legacy console in the path (/table/project:dataset.table).
+func parseConsoleURL(text string) (TableReference, error) {
+	u, err := url.Parse(text)
+	if err != nil {
+		return TableReference{}, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	for _, prefix := range []string{"/table/", "/dataset/"} {
+		if name, ok := strings.CutPrefix(u.Path, prefix); ok {
+			return ParseTableReference(name)
+		}
+	}
+
+	q := u.Query()
+	ref := TableReference{ProjectID: q.Get("p"), DatasetID: q.Get("d"), TableID: q.Get("t")}
+	if ref.DatasetID == "" {
+		if match := consoleResourcePattern.FindStringSubmatch(q.Get("ws")); match != nil {
+			ref = TableReference{ProjectID: match[1], DatasetID: match[2], TableID: match[3]}
+		}
+	}
+	if ref.DatasetID == "" {
+		return TableReference{}, fmt.Errorf("the URL doesn't point at a BigQuery dataset or table")
+	}
+	if ref.ProjectID == "" {
+		ref.ProjectID = q.Get("project")
+	}
+	return ref, nil
+}
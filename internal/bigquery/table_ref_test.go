@@ -0,0 +1,41 @@
+package bigquery
+
+import "testing"
+
+func TestParseTableReference(t *testing.T) {
+	tests := []struct {
+		input string
+		want  TableReference
+	}{
+		{"proj.sales.orders", TableReference{"proj", "sales", "orders"}},
+		{" `proj.sales.orders` ", TableReference{"proj", "sales", "orders"}},
+		{"sales.orders", TableReference{"", "sales", "orders"}},
+		{"proj:sales.orders", TableReference{"proj", "sales", "orders"}},
+		{"proj:sales", TableReference{"proj", "sales", ""}},
+		{"example.com:proj:sales.orders", TableReference{"example.com:proj", "sales", "orders"}},
+		{"example.com:proj.sales.orders", TableReference{"example.com:proj", "sales", "orders"}},
+		{"https://console.cloud.google.com/bigquery?project=billing&ws=!1m5!1m4!4m3!1sbigquery-public-data!2ssamples!3sshakespeare",
+			TableReference{"bigquery-public-data", "samples", "shakespeare"}},
+		{"https://console.cloud.google.com/bigquery?project=billing&ws=%211m4%211m3%213m2%211sproj%212ssales",
+			TableReference{"proj", "sales", ""}},
+		{"https://console.cloud.google.com/bigquery?project=proj&p=proj&d=sales&t=orders&page=table",
+			TableReference{"proj", "sales", "orders"}},
+		{"https://bigquery.cloud.google.com/table/proj:sales.orders", TableReference{"proj", "sales", "orders"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTableReference(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.input, tt.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "orders", "a.b.c.d", "proj..orders", "proj:", "https://console.cloud.google.com/bigquery?project=proj"} {
+		if ref, err := ParseTableReference(input); err == nil {
+			t.Errorf("%q: expected an error, got %+v", input, ref)
+		}
+	}
+}
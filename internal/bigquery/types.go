@@ -30,6 +30,18 @@ type Table struct {
 	NumBytes    int64
 	Type        string
 	Labels      map[string]string
+	// DetailsLoaded is false when the table came from a list call and
+	// NumRows/NumBytes/Description have not been fetched yet
+	DetailsLoaded bool
+	// Shards is set on entries grouping date-sharded tables, whose ID is the
+	// wildcard table ID
+	Shards *ShardSet `json:"-"`
+}
+
+// TablePage is one page of a streamed table listing
+type TablePage struct {
+	Tables        []*Table
+	NextPageToken string
 }
 
 type Column struct {
@@ -43,16 +55,80 @@ type Column struct {
 
 type TableSchema struct {
 	Fields []*Column
+	// Version is the table revision the schema was read from
+	Version TableVersion
+}
+
+// TableVersion identifies a revision of a table's metadata
+type TableVersion struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// Matches reports whether both versions refer to the same table revision.
+// ETags are compared when both are known, otherwise last-modified times.
+func (v TableVersion) Matches(other TableVersion) bool {
+	if v.ETag != "" && other.ETag != "" {
+		return v.ETag == other.ETag
+	}
+	return !v.LastModified.IsZero() && v.LastModified.Equal(other.LastModified)
+}
+
+// ColumnInfo is a top-level column as listed by INFORMATION_SCHEMA.COLUMNS
+type ColumnInfo struct {
+	TableID string
+	Name    string
+	Type    string
 }
 
 type QueryResult struct {
 	Columns []string
 	Rows    [][]interface{}
 	JobID   string
+	// Schema describes the result columns; nil when the query returned no rows
+	Schema *TableSchema
 }
 
 type TablePreview struct {
-	Schema  *TableSchema
+	Schema *TableSchema
+	// Rows and Headers cover every top-level column; the UI decides how many
+	// it can show
 	Rows    [][]interface{}
 	Headers []string
+	// PartitionID is the partition the rows were read from, if the table is partitioned
+	PartitionID string
+	// Where and OrderBy are the filter and sort applied in BigQuery, if any
+	Where   string
+	OrderBy []OrderBy
+}
+
+// PreviewOptions narrows and orders a table preview
+type PreviewOptions struct {
+	// PartitionID selects a partition; empty picks the newest one with rows
+	PartitionID string
+	// Where is a condition the rows must match
+	Where string
+	// OrderBy sorts the table in BigQuery before the limit applies
+	OrderBy []OrderBy
+	Limit   int
+}
+
+// NestedValues returns the field values of a RECORD cell or the elements of
+// an ARRAY cell. ok is false for scalar cells.
+func NestedValues(cell interface{}) (values []interface{}, ok bool) {
+	list, ok := cell.([]bigquery.Value)
+	if !ok {
+		return nil, false
+	}
+	values = make([]interface{}, len(list))
+	for i, v := range list {
+		values[i] = v
+	}
+	return values, true
+}
+
+// OrderBy is one key of a sort done in BigQuery
+type OrderBy struct {
+	Column string
+	Desc   bool
 }
@@ -0,0 +1,28 @@
+package bigquery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTableVersionMatches(t *testing.T) {
+	modified := time.UnixMilli(1700000000000)
+
+	tests := []struct {
+		name string
+		a, b TableVersion
+		want bool
+	}{
+		{"same etag", TableVersion{ETag: "abc"}, TableVersion{ETag: "abc"}, true},
+		{"different etag", TableVersion{ETag: "abc", LastModified: modified}, TableVersion{ETag: "def", LastModified: modified}, false},
+		{"same last modified", TableVersion{LastModified: modified}, TableVersion{ETag: "abc", LastModified: modified}, true},
+		{"different last modified", TableVersion{LastModified: modified}, TableVersion{LastModified: modified.Add(time.Second)}, false},
+		{"unknown version", TableVersion{}, TableVersion{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Matches(tt.b); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,34 +1,152 @@
 package cache
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"bqui/internal/bigquery"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
 )
 
+// DefaultTTL is how long cached entries are considered fresh. Older entries are
+// still served, but callers should revalidate them in the background.
+const DefaultTTL = time.Hour
+
+// DefaultMaxSize is the default limit on the total size of cached entries
+const DefaultMaxSize = 100 << 20
+
+// Cache stores BigQuery metadata in a single bbolt database file. Every write is
+// a transaction, and the least recently used entries are evicted once the total
+// size of the entries, kept as a running total, exceeds the maximum size. Entries are namespaced by the
+// identity and endpoint they were fetched with, so one identity never sees
+// metadata cached by another.
 type Cache struct {
-	baseDir string
+	db        *bolt.DB
+	path      string
+	ttl       time.Duration
+	maxSize   int64
+	namespace string
+
+	// touched holds the time entries were last read, until it's written with
+	// the next write transaction
+	mu      sync.Mutex
+	touched map[string]time.Time
 }
 
-// New creates a new cache instance with OS-appropriate cache directory
-func New() (*Cache, error) {
+// Dir returns the OS-appropriate bqui cache directory
+func Dir() (string, error) {
 	cacheDir, err := getCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
 	}
+	return filepath.Join(cacheDir, "bqui"), nil
+}
 
-	// Create bqui cache subdirectory
-	bquiCacheDir := filepath.Join(cacheDir, "bqui")
-	if err := os.MkdirAll(bquiCacheDir, 0755); err != nil {
+// New opens the cache database in the OS-appropriate cache directory
+func New() (*Cache, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
+	// MkdirAll leaves an existing directory alone, and the cache holds metadata
+	// of every account used with bqui
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to restrict cache directory permissions: %w", err)
+	}
+	removeLegacyFiles(dir)
+
+	return Open(filepath.Join(dir, "cache.db"))
+}
+
+// ErrInUse is returned by Open when another process holds the cache database
+var ErrInUse = errors.New("the cache database is in use by another bqui process")
+
+// Open opens (creating if needed) the cache database at path. Only one process
+// can hold the database open; Open gives up after a short wait if another does
+// and returns ErrInUse.
+func Open(path string) (*Cache, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if errors.Is(err, bolterrors.ErrTimeout) {
+		return nil, fmt.Errorf("%w (%s)", ErrInUse, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+	// Databases created by older versions were world-readable
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict cache permissions: %w", err)
+	}
+
+	if err := db.Update(initialize); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache database: %w", err)
+	}
+
+	c := &Cache{db: db, path: path, ttl: DefaultTTL, maxSize: DefaultMaxSize, touched: make(map[string]time.Time)}
+	c.SetNamespace("", "")
+	return c, nil
+}
+
+// SetNamespace scopes all reads and writes to entries cached for the given
+// principal (e.g. "user:alice@example.com") and API endpoint
+func (c *Cache) SetNamespace(principal, endpoint string) {
+	sum := sha256.Sum256([]byte(principal + "\x00" + endpoint))
+	c.namespace = hex.EncodeToString(sum[:8])
+}
+
+// Close records which entries were read and releases the cache database
+func (c *Cache) Close() error {
+	flushErr := c.db.Update(c.flushAccess)
+	if err := c.db.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// Path returns the location of the cache database file
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// SetMaxSize sets the total size of cached entries above which the least
+// recently used entries are evicted. A size of 0 or less disables the limit.
+func (c *Cache) SetMaxSize(size int64) {
+	c.maxSize = size
+}
+
+// removeLegacyFiles deletes the per-entry JSON files written by older versions
+func removeLegacyFiles(dir string) {
+	for _, pattern := range []string{"datasets_*.json", "tables_*.json", "schema_*.json"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			_ = os.Remove(match)
+		}
+	}
+}
+
+// SetTTL sets how long cached entries are considered fresh
+func (c *Cache) SetTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
 
-	return &Cache{baseDir: bquiCacheDir}, nil
+// IsStale reports whether an entry cached at cachedAt should be revalidated
+func (c *Cache) IsStale(cachedAt time.Time) bool {
+	return time.Since(cachedAt) >= c.ttl
 }
 
 // getCacheDir returns the appropriate cache directory for the OS
@@ -62,211 +180,116 @@ func getCacheDir() (string, error) {
 	}
 }
 
-// CachedDatasets represents cached dataset information
-type CachedDatasets struct {
-	Datasets  []*bigquery.Dataset `json:"datasets"`
-	ProjectID string              `json:"project_id"`
-	CachedAt  time.Time           `json:"cached_at"`
-}
-
-// CachedTables represents cached table information for a dataset
-type CachedTables struct {
-	Tables    []*bigquery.Table `json:"tables"`
-	DatasetID string            `json:"dataset_id"`
-	ProjectID string            `json:"project_id"`
-	CachedAt  time.Time         `json:"cached_at"`
-}
+// Key prefixes for each kind of cached entry
+const (
+	kindDatasets = "datasets"
+	kindTables   = "tables"
+	kindSchema   = "schema"
+	kindColumns  = "columns"
+)
 
-// CachedSchema represents cached schema information for a table
-type CachedSchema struct {
-	Schema    *bigquery.TableSchema `json:"schema"`
-	TableID   string                `json:"table_id"`
-	DatasetID string                `json:"dataset_id"`
-	ProjectID string                `json:"project_id"`
-	CachedAt  time.Time             `json:"cached_at"`
+// entryKey joins the namespace, a kind and its IDs into a store key. BigQuery
+// IDs never contain "/", so keys can't collide and a dataset's entries share a prefix.
+func (c *Cache) entryKey(kind string, ids ...string) string {
+	return c.namespace + "/" + kind + "/" + strings.Join(ids, "/")
 }
 
-// GetDatasets retrieves cached datasets for a project
-func (c *Cache) GetDatasets(projectID string) ([]*bigquery.Dataset, bool) {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("datasets_%s.json", projectID))
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, false
-	}
-
-	var cached CachedDatasets
-	if err := json.Unmarshal(data, &cached); err != nil {
-		return nil, false
-	}
-
-	// Check if cache is still valid (24 hours)
-	if time.Since(cached.CachedAt) > 24*time.Hour {
-		return nil, false
-	}
-
-	return cached.Datasets, true
+// GetDatasets retrieves cached datasets for a project along with when they were cached
+func (c *Cache) GetDatasets(projectID string) ([]*bigquery.Dataset, time.Time, bool) {
+	var datasets []*bigquery.Dataset
+	cachedAt, found := c.get(c.entryKey(kindDatasets, projectID), &datasets)
+	return datasets, cachedAt, found
 }
 
 // SetDatasets caches datasets for a project
 func (c *Cache) SetDatasets(projectID string, datasets []*bigquery.Dataset) error {
-	cached := CachedDatasets{
-		Datasets:  datasets,
-		ProjectID: projectID,
-		CachedAt:  time.Now(),
-	}
-
-	data, err := json.MarshalIndent(cached, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal datasets: %w", err)
-	}
-
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("datasets_%s.json", projectID))
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := c.put(c.entryKey(kindDatasets, projectID), datasets); err != nil {
 		return fmt.Errorf("failed to write datasets cache: %w", err)
 	}
-
 	return nil
 }
 
-// GetTables retrieves cached tables for a dataset
-func (c *Cache) GetTables(projectID, datasetID string) ([]*bigquery.Table, bool) {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("tables_%s_%s.json", projectID, datasetID))
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, false
-	}
-
-	var cached CachedTables
-	if err := json.Unmarshal(data, &cached); err != nil {
-		return nil, false
-	}
-
-	// Check if cache is still valid (24 hours)
-	if time.Since(cached.CachedAt) > 24*time.Hour {
-		return nil, false
-	}
-
-	return cached.Tables, true
+// GetTables retrieves cached tables for a dataset along with when they were cached
+func (c *Cache) GetTables(projectID, datasetID string) ([]*bigquery.Table, time.Time, bool) {
+	var tables []*bigquery.Table
+	cachedAt, found := c.get(c.entryKey(kindTables, projectID, datasetID), &tables)
+	return tables, cachedAt, found
 }
 
 // SetTables caches tables for a dataset
 func (c *Cache) SetTables(projectID, datasetID string, tables []*bigquery.Table) error {
-	cached := CachedTables{
-		Tables:    tables,
-		DatasetID: datasetID,
-		ProjectID: projectID,
-		CachedAt:  time.Now(),
-	}
-
-	data, err := json.MarshalIndent(cached, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal tables: %w", err)
-	}
-
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("tables_%s_%s.json", projectID, datasetID))
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := c.put(c.entryKey(kindTables, projectID, datasetID), tables); err != nil {
 		return fmt.Errorf("failed to write tables cache: %w", err)
 	}
-
 	return nil
 }
 
-// GetSchema retrieves cached schema for a table
-func (c *Cache) GetSchema(projectID, datasetID, tableID string) (*bigquery.TableSchema, bool) {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("schema_%s_%s_%s.json", projectID, datasetID, tableID))
-
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, false
-	}
-
-	var cached CachedSchema
-	if err := json.Unmarshal(data, &cached); err != nil {
-		return nil, false
-	}
+// GetSchema retrieves cached schema for a table along with when it was cached
+func (c *Cache) GetSchema(projectID, datasetID, tableID string) (*bigquery.TableSchema, time.Time, bool) {
+	var schema *bigquery.TableSchema
+	cachedAt, found := c.get(c.entryKey(kindSchema, projectID, datasetID, tableID), &schema)
+	return schema, cachedAt, found
+}
 
-	// Check if cache is still valid (24 hours)
-	if time.Since(cached.CachedAt) > 24*time.Hour {
-		return nil, false
+// SetSchema caches schema for a table
+func (c *Cache) SetSchema(projectID, datasetID, tableID string, schema *bigquery.TableSchema) error {
+	if err := c.put(c.entryKey(kindSchema, projectID, datasetID, tableID), schema); err != nil {
+		return fmt.Errorf("failed to write schema cache: %w", err)
 	}
+	return nil
+}
 
-	return cached.Schema, true
+// GetColumns retrieves the cached column index of a dataset along with when it was cached
+func (c *Cache) GetColumns(projectID, datasetID string) ([]*bigquery.ColumnInfo, time.Time, bool) {
+	var columns []*bigquery.ColumnInfo
+	cachedAt, found := c.get(c.entryKey(kindColumns, projectID, datasetID), &columns)
+	return columns, cachedAt, found
 }
 
-// SetSchema caches schema for a table
-func (c *Cache) SetSchema(projectID, datasetID, tableID string, schema *bigquery.TableSchema) error {
-	cached := CachedSchema{
-		Schema:    schema,
-		TableID:   tableID,
-		DatasetID: datasetID,
-		ProjectID: projectID,
-		CachedAt:  time.Now(),
+// SetColumns caches the column index of a dataset
+func (c *Cache) SetColumns(projectID, datasetID string, columns []*bigquery.ColumnInfo) error {
+	if err := c.put(c.entryKey(kindColumns, projectID, datasetID), columns); err != nil {
+		return fmt.Errorf("failed to write columns cache: %w", err)
 	}
+	return nil
+}
 
-	data, err := json.MarshalIndent(cached, "", "  ")
+// Projects returns the IDs of all projects with cached datasets, sorted
+func (c *Cache) Projects() ([]string, error) {
+	prefix := c.entryKey(kindDatasets)
+	keys, err := c.keys(prefix)
 	if err != nil {
-		return fmt.Errorf("failed to marshal schema: %w", err)
+		return nil, err
 	}
-
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("schema_%s_%s_%s.json", projectID, datasetID, tableID))
-	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write schema cache: %w", err)
+	projects := make([]string, len(keys))
+	for i, key := range keys {
+		projects[i] = strings.TrimPrefix(key, prefix)
 	}
-
-	return nil
+	return projects, nil
 }
 
 // ClearDatasets removes cached datasets for a project
 func (c *Cache) ClearDatasets(projectID string) error {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("datasets_%s.json", projectID))
-	err := os.Remove(filename)
-	if os.IsNotExist(err) {
-		return nil // Not an error if file doesn't exist
-	}
-	return err
+	return c.delete(c.entryKey(kindDatasets, projectID))
 }
 
 // ClearTables removes cached tables for a dataset
 func (c *Cache) ClearTables(projectID, datasetID string) error {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("tables_%s_%s.json", projectID, datasetID))
-	err := os.Remove(filename)
-	if os.IsNotExist(err) {
-		return nil // Not an error if file doesn't exist
-	}
-	return err
+	return c.delete(c.entryKey(kindTables, projectID, datasetID))
 }
 
 // ClearSchema removes cached schema for a table
 func (c *Cache) ClearSchema(projectID, datasetID, tableID string) error {
-	filename := filepath.Join(c.baseDir, fmt.Sprintf("schema_%s_%s_%s.json", projectID, datasetID, tableID))
-	err := os.Remove(filename)
-	if os.IsNotExist(err) {
-		return nil // Not an error if file doesn't exist
-	}
-	return err
+	return c.delete(c.entryKey(kindSchema, projectID, datasetID, tableID))
 }
 
-// ClearAllTablesInDataset removes all cached tables and schemas for a dataset
+// ClearAllTablesInDataset removes all cached tables, schemas and columns for a dataset
 func (c *Cache) ClearAllTablesInDataset(projectID, datasetID string) error {
-	// Clear tables cache
 	if err := c.ClearTables(projectID, datasetID); err != nil {
 		return err
 	}
-
-	// Clear all schemas in this dataset
-	pattern := fmt.Sprintf("schema_%s_%s_*.json", projectID, datasetID)
-	matches, err := filepath.Glob(filepath.Join(c.baseDir, pattern))
-	if err != nil {
+	if err := c.delete(c.entryKey(kindColumns, projectID, datasetID)); err != nil {
 		return err
 	}
-
-	for _, match := range matches {
-		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
-			return err
-		}
-	}
-
-	return nil
+	return c.deletePrefix(c.entryKey(kindSchema, projectID, datasetID) + "/")
 }
@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bqui/internal/bigquery"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestCache(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	c := openTestCache(t)
+
+	datasets := []*bigquery.Dataset{{ID: "sales", ProjectID: "example.com:proj", Location: "EU"}}
+	if err := c.SetDatasets("example.com:proj", datasets); err != nil {
+		t.Fatalf("Failed to set datasets: %v", err)
+	}
+
+	got, cachedAt, found := c.GetDatasets("example.com:proj")
+	if !found {
+		t.Fatal("Expected cached datasets to be found")
+	}
+	if len(got) != 1 || got[0].ID != "sales" || got[0].Location != "EU" {
+		t.Errorf("Unexpected datasets: %+v", got)
+	}
+	if time.Since(cachedAt) > time.Minute {
+		t.Errorf("Unexpected cached time: %v", cachedAt)
+	}
+
+	if _, _, found := c.GetDatasets("other"); found {
+		t.Error("Expected no datasets for another project")
+	}
+}
+
+func TestClearAllTablesInDataset(t *testing.T) {
+	c := openTestCache(t)
+
+	schema := &bigquery.TableSchema{Fields: []*bigquery.Column{{Name: "id", Type: "INTEGER"}}}
+	for _, key := range [][2]string{{"sales", "orders"}, {"sales", "items"}, {"sales_eu", "orders"}} {
+		if err := c.SetSchema("proj", key[0], key[1], schema); err != nil {
+			t.Fatalf("Failed to set schema: %v", err)
+		}
+	}
+	if err := c.SetTables("proj", "sales", []*bigquery.Table{{ID: "orders"}}); err != nil {
+		t.Fatalf("Failed to set tables: %v", err)
+	}
+
+	if err := c.ClearAllTablesInDataset("proj", "sales"); err != nil {
+		t.Fatalf("Failed to clear dataset: %v", err)
+	}
+
+	if _, _, found := c.GetTables("proj", "sales"); found {
+		t.Error("Expected tables to be cleared")
+	}
+	if _, _, found := c.GetSchema("proj", "sales", "orders"); found {
+		t.Error("Expected schema in cleared dataset to be removed")
+	}
+	// A dataset whose name shares the prefix must be left alone
+	if _, _, found := c.GetSchema("proj", "sales_eu", "orders"); !found {
+		t.Error("Expected schema in another dataset to be kept")
+	}
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	c := openTestCache(t)
+
+	tables := make([]*bigquery.Table, 50)
+	for i := range tables {
+		tables[i] = &bigquery.Table{ID: "table_with_a_reasonably_long_name", DatasetID: "dataset"}
+	}
+
+	for _, datasetID := range []string{"a", "b", "c"} {
+		if err := c.SetTables("proj", datasetID, tables); err != nil {
+			t.Fatalf("Failed to set tables: %v", err)
+		}
+		time.Sleep(time.Millisecond) // Distinct access times
+	}
+
+	// Touch "a" so "b" becomes the least recently used entry
+	if _, _, found := c.GetTables("proj", "a"); !found {
+		t.Fatal("Expected tables for a")
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	// Room for two of the three entries, whose sizes differ by a few bytes
+	// because timestamps are encoded with a varying number of digits
+	c.SetMaxSize(stats.Size * 3 / 4)
+
+	removed, err := c.Prune(0)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 entry evicted, got %d", removed)
+	}
+	if _, _, found := c.GetTables("proj", "b"); found {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	for _, datasetID := range []string{"a", "c"} {
+		if _, _, found := c.GetTables("proj", datasetID); !found {
+			t.Errorf("Expected tables for %s to be kept", datasetID)
+		}
+	}
+}
+
+func TestNamespacesAreIsolated(t *testing.T) {
+	c := openTestCache(t)
+
+	c.SetNamespace("user:alice@example.com", "https://bigquery.googleapis.com")
+	if err := c.SetDatasets("proj", []*bigquery.Dataset{{ID: "private"}}); err != nil {
+		t.Fatalf("Failed to set datasets: %v", err)
+	}
+
+	c.SetNamespace("serviceAccount:etl@proj.iam.gserviceaccount.com", "https://bigquery.googleapis.com")
+	if _, _, found := c.GetDatasets("proj"); found {
+		t.Error("Expected another principal not to see cached datasets")
+	}
+	if projects, _ := c.Projects(); len(projects) != 0 {
+		t.Errorf("Expected no cached projects for another principal, got %v", projects)
+	}
+
+	c.SetNamespace("user:alice@example.com", "http://localhost:9050")
+	if _, _, found := c.GetDatasets("proj"); found {
+		t.Error("Expected another endpoint not to see cached datasets")
+	}
+
+	c.SetNamespace("user:alice@example.com", "https://bigquery.googleapis.com")
+	if _, _, found := c.GetDatasets("proj"); !found {
+		t.Error("Expected the original principal to see its cached datasets")
+	}
+}
+
+func TestStaleEntriesAreStillServed(t *testing.T) {
+	c := openTestCache(t)
+	c.SetTTL(time.Hour)
+
+	if err := c.SetSchema("proj", "sales", "orders", &bigquery.TableSchema{}); err != nil {
+		t.Fatalf("Failed to set schema: %v", err)
+	}
+	_, cachedAt, found := c.GetSchema("proj", "sales", "orders")
+	if !found || c.IsStale(cachedAt) {
+		t.Fatalf("Expected a fresh schema, found %v at %v", found, cachedAt)
+	}
+
+	// Entries outlive their TTL so they can be shown while they're revalidated
+	c.SetTTL(0)
+	if _, _, found := c.GetSchema("proj", "sales", "orders"); !found || !c.IsStale(cachedAt) {
+		t.Errorf("Expected the stale schema to still be served, found %v", found)
+	}
+}
+
+func TestProjects(t *testing.T) {
+	c := openTestCache(t)
+	for _, project := range []string{"zeta", "alpha"} {
+		if err := c.SetDatasets(project, nil); err != nil {
+			t.Fatalf("Failed to set datasets: %v", err)
+		}
+	}
+	if err := c.SetTables("beta", "sales", nil); err != nil {
+		t.Fatalf("Failed to set tables: %v", err)
+	}
+
+	projects, err := c.Projects()
+	if err != nil {
+		t.Fatalf("Projects failed: %v", err)
+	}
+	if len(projects) != 2 || projects[0] != "alpha" || projects[1] != "zeta" {
+		t.Errorf("Expected the projects with cached datasets, sorted, got %v", projects)
+	}
+}
+
+func TestTotalSizeIsKeptCurrent(t *testing.T) {
+	c := openTestCache(t)
+
+	tracked := func() int64 {
+		var total int64
+		_ = c.db.View(func(tx *bolt.Tx) error {
+			total = totalSize(tx)
+			return nil
+		})
+		return total
+	}
+	check := func(step string) {
+		t.Helper()
+		stats, err := c.Stats()
+		if err != nil {
+			t.Fatalf("Failed to get stats: %v", err)
+		}
+		if got := tracked(); got != stats.Size {
+			t.Errorf("%s: tracked size %d, entries add up to %d", step, got, stats.Size)
+		}
+	}
+
+	tables := []*bigquery.Table{{ID: "orders"}, {ID: "users"}}
+	for _, datasetID := range []string{"a", "b", "c"} {
+		if err := c.SetTables("proj", datasetID, tables); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check("after puts")
+	if err := c.SetTables("proj", "a", tables[:1]); err != nil {
+		t.Fatal(err)
+	}
+	check("after overwrite")
+	if err := c.ClearTables("proj", "b"); err != nil {
+		t.Fatal(err)
+	}
+	check("after delete")
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if tracked() != 0 {
+		t.Errorf("Expected no size after clear, got %d", tracked())
+	}
+}
+
+func TestRecencyIsWrittenOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := make([]*bigquery.Table, 50)
+	for i := range tables {
+		tables[i] = &bigquery.Table{ID: "table_with_a_reasonably_long_name", DatasetID: "dataset"}
+	}
+	for _, datasetID := range []string{"a", "b"} {
+		if err := c.SetTables("proj", datasetID, tables); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Millisecond)
+	c.GetTables("proj", "a")
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	stats, _ := c.Stats()
+	c.SetMaxSize(stats.Size * 3 / 4)
+	if _, err := c.Prune(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found := c.GetTables("proj", "a"); !found {
+		t.Error("Expected the entry read before closing to be kept")
+	}
+	if _, _, found := c.GetTables("proj", "b"); found {
+		t.Error("Expected the unread entry to be evicted")
+	}
+}
@@ -0,0 +1,410 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+var (
+	// entriesBucket maps keys to JSON-encoded entries
+	entriesBucket = []byte("entries")
+	// accessBucket maps keys to their last access time, for LRU eviction
+	accessBucket = []byte("access")
+	// metaBucket holds the key format version and the total size of the entries
+	metaBucket = []byte("meta")
+
+	versionKey = []byte("version")
+	sizeKey    = []byte("size")
+)
+
+// formatVersion is bumped whenever the key layout changes; entries written in an
+// older layout are dropped on open
+const formatVersion = "2"
+
+// initialize creates the buckets and discards entries from older key layouts
+func initialize(tx *bolt.Tx) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	if string(meta.Get(versionKey)) != formatVersion {
+		for _, name := range [][]byte{entriesBucket, accessBucket} {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolterrors.ErrBucketNotFound) {
+				return err
+			}
+		}
+		if err := meta.Put(versionKey, []byte(formatVersion)); err != nil {
+			return err
+		}
+		if err := meta.Delete(sizeKey); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range [][]byte{entriesBucket, accessBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	// Databases written before the total was kept are measured once
+	if meta.Get(sizeKey) == nil {
+		var total int64
+		err := tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+			total += int64(len(k) + len(v))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return setTotalSize(tx, total)
+	}
+	return nil
+}
+
+// totalSize returns the running total size of all entries
+func totalSize(tx *bolt.Tx) int64 {
+	buf := tx.Bucket(metaBucket).Get(sizeKey)
+	if len(buf) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(buf))
+}
+
+func setTotalSize(tx *bolt.Tx, total int64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(max(total, 0)))
+	return tx.Bucket(metaBucket).Put(sizeKey, buf)
+}
+
+// entry is the stored form of a cached value
+type entry struct {
+	CachedAt time.Time       `json:"cached_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Stats summarizes the contents of the cache
+type Stats struct {
+	Path     string
+	FileSize int64
+	// Size is the total size of all entries, which is what MaxSize limits
+	Size    int64
+	MaxSize int64
+	// Entries counts entries by kind: "datasets", "tables", "schema" and "columns"
+	Entries map[string]int
+	// Namespaces is the number of distinct identity/endpoint namespaces
+	Namespaces int
+	Oldest     time.Time
+	Newest     time.Time
+}
+
+func encodeTime(t time.Time) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(t.UnixNano()))
+	return buf
+}
+
+func decodeTime(buf []byte) time.Time {
+	if len(buf) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(buf)))
+}
+
+// get decodes the entry stored under key into v and marks it as recently
+// used. Recency is kept in memory and written with the next put, prune or
+// Close, so reads never need a write transaction.
+func (c *Cache) get(key string, v any) (time.Time, bool) {
+	var data []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		// The value is only valid during the transaction, so copy it out
+		data = bytes.Clone(tx.Bucket(entriesBucket).Get([]byte(key)))
+		return nil
+	})
+	if err != nil || data == nil {
+		return time.Time{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return time.Time{}, false
+	}
+
+	c.mu.Lock()
+	c.touched[key] = time.Now()
+	c.mu.Unlock()
+
+	return e.CachedAt, true
+}
+
+// flushAccess writes the recency of entries read since the last flush
+func (c *Cache) flushAccess(tx *bolt.Tx) error {
+	c.mu.Lock()
+	touched := c.touched
+	c.touched = make(map[string]time.Time)
+	c.mu.Unlock()
+
+	access := tx.Bucket(accessBucket)
+	for key, usedAt := range touched {
+		if access.Get([]byte(key)) == nil {
+			continue // Evicted or cleared in the meantime
+		}
+		if err := access.Put([]byte(key), encodeTime(usedAt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put stores v under key and evicts least recently used entries if the cache
+// has grown past its maximum size, all in one transaction
+func (c *Cache) put(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	now := time.Now()
+	value, err := json.Marshal(entry{CachedAt: now, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if err := c.flushAccess(tx); err != nil {
+			return err
+		}
+		entries := tx.Bucket(entriesBucket)
+		total := totalSize(tx) + int64(len(key)+len(value))
+		if old := entries.Get([]byte(key)); old != nil {
+			total -= int64(len(key) + len(old))
+		}
+		if err := entries.Put([]byte(key), value); err != nil {
+			return err
+		}
+		if err := tx.Bucket(accessBucket).Put([]byte(key), encodeTime(now)); err != nil {
+			return err
+		}
+		if err := setTotalSize(tx, total); err != nil {
+			return err
+		}
+		if c.maxSize > 0 && total > c.maxSize {
+			_, err := evict(tx, c.maxSize)
+			return err
+		}
+		return nil
+	})
+}
+
+// keys returns the keys starting with prefix in sorted order
+func (c *Cache) keys(prefix string) ([]string, error) {
+	var keys []string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for k, _ := cursor.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = cursor.Next() {
+			keys = append(keys, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	return keys, nil
+}
+
+// delete removes the entry stored under key, if any
+func (c *Cache) delete(key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return deleteKey(tx, []byte(key))
+	})
+}
+
+// deletePrefix removes all entries whose key starts with prefix
+func (c *Cache) deletePrefix(prefix string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		var keys [][]byte
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for k, _ := cursor.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = cursor.Next() {
+			keys = append(keys, bytes.Clone(k))
+		}
+		for _, k := range keys {
+			if err := deleteKey(tx, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteKey removes an entry and its recency, keeping the total size current
+func deleteKey(tx *bolt.Tx, key []byte) error {
+	entries := tx.Bucket(entriesBucket)
+	value := entries.Get(key)
+	if value == nil {
+		return nil
+	}
+	size := int64(len(key) + len(value))
+	if err := entries.Delete(key); err != nil {
+		return err
+	}
+	if err := tx.Bucket(accessBucket).Delete(key); err != nil {
+		return err
+	}
+	return setTotalSize(tx, totalSize(tx)-size)
+}
+
+// evict deletes least recently used entries until the entries fit in maxSize
+// and returns how many were deleted. Only a cache over its limit is scanned.
+func evict(tx *bolt.Tx, maxSize int64) (int, error) {
+	total := totalSize(tx)
+	if total <= maxSize {
+		return 0, nil
+	}
+
+	type usage struct {
+		key      []byte
+		size     int64
+		lastUsed time.Time
+	}
+
+	var entries []usage
+	access := tx.Bucket(accessBucket)
+	err := tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+		entries = append(entries, usage{key: bytes.Clone(k), size: int64(len(k) + len(v)), lastUsed: decodeTime(access.Get(k))})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+
+	evicted := 0
+	for _, e := range entries {
+		if total <= maxSize {
+			break
+		}
+		if err := deleteKey(tx, e.key); err != nil {
+			return evicted, err
+		}
+		total -= e.size
+		evicted++
+	}
+	return evicted, nil
+}
+
+// Stats reports the size and contents of the cache
+func (c *Cache) Stats() (Stats, error) {
+	stats := Stats{Path: c.path, MaxSize: c.maxSize, Entries: make(map[string]int)}
+	namespaces := make(map[string]bool)
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+			stats.Size += int64(len(k) + len(v))
+
+			namespace, rest, _ := strings.Cut(string(k), "/")
+			kind, _, _ := strings.Cut(rest, "/")
+			namespaces[namespace] = true
+			stats.Entries[kind]++
+
+			var e entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil // Count it, but it has no usable timestamp
+			}
+			if stats.Oldest.IsZero() || e.CachedAt.Before(stats.Oldest) {
+				stats.Oldest = e.CachedAt
+			}
+			if e.CachedAt.After(stats.Newest) {
+				stats.Newest = e.CachedAt
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to read cache: %w", err)
+	}
+	stats.Namespaces = len(namespaces)
+
+	if info, err := os.Stat(c.path); err == nil {
+		stats.FileSize = info.Size()
+	}
+
+	return stats, nil
+}
+
+// Prune removes entries that haven't been used within maxAge (if maxAge > 0)
+// and then evicts least recently used entries down to the maximum size.
+// It returns the number of entries removed.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	removed := 0
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		if err := c.flushAccess(tx); err != nil {
+			return err
+		}
+		if maxAge > 0 {
+			cutoff := time.Now().Add(-maxAge)
+			access := tx.Bucket(accessBucket)
+
+			var keys [][]byte
+			err := tx.Bucket(entriesBucket).ForEach(func(k, _ []byte) error {
+				if decodeTime(access.Get(k)).Before(cutoff) {
+					keys = append(keys, bytes.Clone(k))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err := deleteKey(tx, k); err != nil {
+					return err
+				}
+			}
+			removed += len(keys)
+		}
+
+		if c.maxSize > 0 {
+			evicted, err := evict(tx, c.maxSize)
+			removed += evicted
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune cache: %w", err)
+	}
+	return removed, nil
+}
+
+// Clear removes every entry from the cache
+func (c *Cache) Clear() error {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, accessBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return setTotalSize(tx, 0)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
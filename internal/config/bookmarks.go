@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Bookmark is a starred table, listed under Favorites in every project
+type Bookmark struct {
+	ProjectID string `json:"project"`
+	DatasetID string `json:"dataset"`
+	TableID   string `json:"table"`
+}
+
+func (b Bookmark) String() string {
+	return fmt.Sprintf("%s.%s.%s", b.ProjectID, b.DatasetID, b.TableID)
+}
+
+// BookmarksPath returns the file bookmarks are kept in
+func BookmarksPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bookmarks.json"), nil
+}
+
+// LoadBookmarks reads the bookmarks file. A missing file yields no bookmarks.
+func LoadBookmarks(path string) ([]Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	var bookmarks []Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks file %s: %w", path, err)
+	}
+	return bookmarks, nil
+}
+
+// SaveBookmarks writes the bookmarks file, creating the config directory if
+// needed. The file is replaced atomically so a crash never leaves it truncated.
+func SaveBookmarks(path string, bookmarks []Bookmark) error {
+	if bookmarks == nil {
+		bookmarks = []Bookmark{}
+	}
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	// A uniquely named temp file keeps concurrent saves from clobbering each other
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config holds user settings loaded from the bqui config file
+type Config struct {
+	// Keys overrides key bindings by action name, e.g. {"up": ["up", "k"]}
+	Keys map[string][]string `json:"keys,omitempty"`
+	// Theme is a built-in theme name, a theme file in ThemesDir, or a path to a theme file
+	Theme string `json:"theme,omitempty"`
+	// MetadataWorkers is the number of concurrent metadata fetches (0 = default)
+	MetadataWorkers int `json:"metadata_workers,omitempty"`
+	// MetadataRateLimit caps metadata fetches per second (0 = default, negative = unlimited)
+	MetadataRateLimit float64 `json:"metadata_rate_limit,omitempty"`
+	// CacheTTL is how long cached metadata is considered fresh, e.g. "30m" (empty = default)
+	CacheTTL string `json:"cache_ttl,omitempty"`
+	// CacheMaxSizeMB caps the size of the cache before old entries are evicted (0 = default, negative = unlimited)
+	CacheMaxSizeMB int `json:"cache_max_size_mb,omitempty"`
+	// Clipboard is how copied text reaches the clipboard: "auto" (default), "system" or "osc52"
+	Clipboard string `json:"clipboard,omitempty"`
+}
+
+// Dir returns the OS-appropriate bqui configuration directory
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "bqui"), nil
+}
+
+// DefaultPath returns the default location of the config file
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// ThemesDir returns the directory searched for user-defined theme files
+func ThemesDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "themes"), nil
+}
+
+// Load reads the config file at path, or the default location if path is empty.
+// A missing default config file is not an error and yields an empty config.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		defaultPath, err := DefaultPath()
+		if err != nil {
+			return &Config{}, nil
+		}
+		path = defaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
@@ -3,14 +3,16 @@ package tui
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"bqui/internal/bigquery"
 	"bqui/internal/cache"
-	"bqui/pkg/clipboard"
+	"bqui/internal/config"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -30,138 +32,6 @@ func max(a, b int) int {
 	return b
 }
 
-type KeyMap struct {
-	Up          key.Binding
-	Down        key.Binding
-	Left        key.Binding
-	Right       key.Binding
-	Enter       key.Binding
-	Tab         key.Binding
-	ShiftTab    key.Binding
-	Search      key.Binding
-	Copy        key.Binding
-	CopyAlt     key.Binding
-	Top         key.Binding
-	Bottom      key.Binding
-	VimTop      key.Binding
-	VimBottom   key.Binding
-	PageUp      key.Binding
-	PageDown    key.Binding
-	ProjectList key.Binding
-	Refresh     key.Binding
-	Escape      key.Binding
-	Back        key.Binding
-	Quit        key.Binding
-	Help        key.Binding
-}
-
-func DefaultKeyMap() KeyMap {
-	return KeyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-			key.WithHelp("↑/k", "move up"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-			key.WithHelp("↓/j", "move down"),
-		),
-		Left: key.NewBinding(
-			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "move left"),
-		),
-		Right: key.NewBinding(
-			key.WithKeys("right", "l"),
-			key.WithHelp("→/l", "move right"),
-		),
-		Enter: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
-		),
-		Tab: key.NewBinding(
-			key.WithKeys("tab"),
-			key.WithHelp("tab", "next tab"),
-		),
-		ShiftTab: key.NewBinding(
-			key.WithKeys("shift+tab"),
-			key.WithHelp("shift+tab", "previous tab"),
-		),
-		Search: key.NewBinding(
-			key.WithKeys("/"),
-			key.WithHelp("/", "search"),
-		),
-		Copy: key.NewBinding(
-			key.WithKeys("y"),
-			key.WithHelp("y", "copy table name"),
-		),
-		CopyAlt: key.NewBinding(
-			key.WithKeys("ctrl+y"),
-			key.WithHelp("ctrl+y", "copy table name"),
-		),
-		Top: key.NewBinding(
-			key.WithKeys("home"),
-			key.WithHelp("home", "go to top"),
-		),
-		Bottom: key.NewBinding(
-			key.WithKeys("end"),
-			key.WithHelp("end", "go to bottom"),
-		),
-		VimTop: key.NewBinding(
-			key.WithKeys("g"),
-			key.WithHelp("g", "go to top"),
-		),
-		VimBottom: key.NewBinding(
-			key.WithKeys("G"),
-			key.WithHelp("G", "go to bottom"),
-		),
-		PageUp: key.NewBinding(
-			key.WithKeys("pgup"),
-			key.WithHelp("pgup", "page up"),
-		),
-		PageDown: key.NewBinding(
-			key.WithKeys("pgdown"),
-			key.WithHelp("pgdown", "page down"),
-		),
-		ProjectList: key.NewBinding(
-			key.WithKeys("ctrl+space", "alt+p"),
-			key.WithHelp("ctrl+space/alt+p", "project selector"),
-		),
-		Refresh: key.NewBinding(
-			key.WithKeys("r", "ctrl+r"),
-			key.WithHelp("r/ctrl+r", "refresh/clear cache"),
-		),
-		Escape: key.NewBinding(
-			key.WithKeys("esc"),
-			key.WithHelp("esc", "back/cancel/clear"),
-		),
-		Back: key.NewBinding(
-			key.WithKeys("backspace"),
-			key.WithHelp("backspace", "back to left pane"),
-		),
-		Quit: key.NewBinding(
-			key.WithKeys("q", "ctrl+c"),
-			key.WithHelp("q/ctrl+c", "quit"),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "help"),
-		),
-	}
-}
-
-func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.Enter, k.Tab, k.ShiftTab, k.Search, k.Copy, k.Quit, k.Help}
-}
-
-func (k KeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{
-		{k.Up, k.Down, k.Left, k.Right},
-		{k.Enter, k.Tab, k.ShiftTab, k.Search, k.Escape},
-		{k.Copy, k.CopyAlt, k.Top, k.Bottom},
-		{k.VimTop, k.VimBottom, k.PageUp, k.PageDown},
-		{k.ProjectList, k.Back, k.Quit, k.Help},
-	}
-}
-
 type FocusState int
 
 const (
@@ -169,6 +39,13 @@ const (
 	FocusTableDetail
 	FocusProjectSelector
 	FocusSearch
+	FocusPalette
+	FocusSchemaDiff
+	FocusProfile
+	FocusPartitions
+	FocusRecord
+	FocusGoTo
+	FocusSaveResults
 )
 
 type Model struct {
@@ -195,43 +72,135 @@ type Model struct {
 	loadingPreview        bool
 	lastSelectedDatasetID string
 	lastSelectedTableID   string
+	// tablesLoadID identifies the current streamed table listing
+	tablesLoadID int
+	// detailsRequested tracks tables whose row counts/sizes have been requested
+	detailsRequested map[string]bool
+	// Freshness of the cached data shown in each view
+	datasetsCache cacheState
+	tablesCache   cacheState
+	schemaCache   cacheState
+	// offline serves everything from the cache; bqClient is nil
+	offline          bool
+	offlineProjectID string
+	// Global search palette and its per-dataset index
+	palette        PaletteModel
+	showPalette    bool
+	searchIndex    map[string][]paletteEntry
+	indexProjectID string
+	indexQueue     []string
+	indexRunning   map[string]bool
+	indexErrors    []error
+	// pendingJump is a table to select once its dataset's tables are listed,
+	// and pendingColumn a column to focus once its schema is loaded
+	pendingJump   *jumpTarget
+	pendingColumn string
+	// jumpOnDatasets is a jump made once the project's datasets are listed:
+	// into another project after switching to it, or to the table named on
+	// the command line
+	jumpOnDatasets *jumpTarget
+	// startupQuery runs the query given on the command line
+	startupQuery tea.Cmd
+	// markedTable is the base table of a schema diff
+	markedTable    *tableRef
+	schemaDiff     SchemaDiffModel
+	showSchemaDiff bool
+	profile        ProfileModel
+	showProfile    bool
+	partitions     PartitionsModel
+	showPartitions bool
+	record         RecordModel
+	showRecord     bool
+	// saveResults is the form for saving query results to a table
+	saveResults     SaveResultsModel
+	showSaveResults bool
+	// bookmarks are the starred tables, saved to bookmarksPath
+	bookmarks     []config.Bookmark
+	bookmarksPath string
+	bookmarkSaver *bookmarkSaver
+	// output receives escape sequences for the terminal, such as OSC 52
+	output io.Writer
+	// goTo is the prompt for a table name or console URL to open
+	goTo            textinput.Model
+	goToReturnFocus FocusState
 }
 
-func NewModel(ctx context.Context, bqClient *bigquery.Client) Model {
-	// Initialize cache
-	cacheInstance, err := cache.New()
-	if err != nil {
-		// If cache initialization fails, we'll continue without caching
-		// but log the error for debugging
-		cacheInstance = nil
-	}
+// Options configures the TUI model
+type Options struct {
+	KeyMap KeyMap
+	// Cache stores metadata between runs; nil disables caching
+	Cache *cache.Cache
+	// Offline browses the cache without a BigQuery client, starting at ProjectID
+	Offline   bool
+	ProjectID string
+	// Bookmarks are the starred tables, saved to BookmarksPath when they
+	// change; an empty path keeps them for this session only
+	Bookmarks     []config.Bookmark
+	BookmarksPath string
+	// Table is opened on the Schema tab once the datasets are listed
+	Table *bigquery.TableReference
+	// Query is run at startup and shown in the Results tab
+	Query string
+	// Output is the program's output, where OSC 52 clipboard sequences are
+	// written; nil drops them
+	Output io.Writer
+}
+
+func NewModel(ctx context.Context, bqClient *bigquery.Client, opts Options) Model {
+	keyMap := opts.KeyMap
 
 	m := Model{
-		ctx:             ctx,
-		bqClient:        bqClient,
-		cache:           cacheInstance,
-		datasetList:     NewDatasetListModel(),
-		tableDetail:     NewTableDetailModel(),
-		projectSelector: NewProjectSelectorModel(),
-		search:          NewSearchModel(),
-		focus:           FocusDatasetList,
-		keyMap:          DefaultKeyMap(),
-		help:            help.New(),
-		showHelp:        false,
-		ready:           false,
-		loadingDatasets: true, // Start with loading state
-		loadingTables:   false,
-		loadingSchema:   false,
-		loadingPreview:  false,
+		ctx:              ctx,
+		bqClient:         bqClient,
+		cache:            opts.Cache,
+		datasetList:      NewDatasetListModel(keyMap),
+		tableDetail:      NewTableDetailModel(keyMap),
+		projectSelector:  NewProjectSelectorModel(keyMap),
+		search:           NewSearchModel(),
+		focus:            FocusDatasetList,
+		keyMap:           keyMap,
+		help:             help.New(),
+		showHelp:         false,
+		ready:            false,
+		loadingDatasets:  true, // Start with loading state
+		loadingTables:    false,
+		loadingSchema:    false,
+		loadingPreview:   false,
+		offline:          opts.Offline,
+		offlineProjectID: opts.ProjectID,
+		bookmarks:        opts.Bookmarks,
+		bookmarksPath:    opts.BookmarksPath,
+		bookmarkSaver:    &bookmarkSaver{},
+		output:           opts.Output,
+	}
+	m.tableDetail.offline = opts.Offline
+	if bqClient != nil || opts.Offline {
+		m.syncFavorites()
+	}
+	if ref := opts.Table; ref != nil {
+		m.jumpOnDatasets = &jumpTarget{projectID: ref.ProjectID, datasetID: ref.DatasetID, tableID: ref.TableID}
+	}
+	if opts.Query != "" {
+		m.tableDetail, m.startupQuery = m.tableDetail.runQuery(opts.Query)
+		m.focus = FocusTableDetail
 	}
 
 	return m
 }
 
+// currentProjectID returns the project being browsed
+func (m Model) currentProjectID() string {
+	if m.offline {
+		return m.offlineProjectID
+	}
+	return m.bqClient.GetProjectID()
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.datasetList.Init(),
 		m.loadDatasets(),
+		m.startupQuery,
 	)
 }
 
@@ -243,13 +212,47 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
-		// Heights will be calculated dynamically in View() method
-		return m, nil
+		// Heights are recalculated exactly in View(); keep an estimate (project header,
+		// status bar and pane borders) on the model so scrolling and lazy loading
+		// know how many rows are visible
+		m.datasetList.height = max(m.height-6, 5)
+		m.tableDetail.height = max(m.height-6, 5)
+		m.tableDetail.width = m.width - m.width/3 - 8 // Right pane minus frame and padding, as in View()
+		m.schemaDiff.height = m.height
+		m.profile.height = m.height
+		m.partitions.height = m.height
+		m.record.height = m.height
+		m.record.width = m.width
+		return m, m.loadVisibleTableDetails()
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 
 	case tea.KeyMsg:
 		if m.focus == FocusSearch {
 			return m.handleSearchInput(msg)
 		}
+		if m.focus == FocusGoTo {
+			return m.handleGoToInput(msg)
+		}
+		if m.focus == FocusPalette {
+			return m.handlePaletteInput(msg)
+		}
+		if m.focus == FocusSchemaDiff {
+			return m.handleSchemaDiffInput(msg)
+		}
+		if m.focus == FocusProfile {
+			return m.handleProfileInput(msg)
+		}
+		if m.focus == FocusPartitions {
+			return m.handlePartitionsInput(msg)
+		}
+		if m.focus == FocusRecord {
+			return m.handleRecordInput(msg)
+		}
+		if m.focus == FocusSaveResults {
+			return m.handleSaveResultsInput(msg)
+		}
 
 		switch {
 		case key.Matches(msg, m.keyMap.Quit):
@@ -288,6 +291,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// If the table detail component changed state, it handled the ESC
 				if updatedTableDetail.visualMode != m.tableDetail.visualMode ||
+					updatedTableDetail.showColumnDialog != m.tableDetail.showColumnDialog ||
+					updatedTableDetail.showExportDialog != m.tableDetail.showExportDialog ||
+					updatedTableDetail.showCopyDialog != m.tableDetail.showCopyDialog ||
 					updatedTableDetail.schemaFilter != m.tableDetail.schemaFilter ||
 					updatedTableDetail.previewFilter != m.tableDetail.previewFilter ||
 					updatedTableDetail.showSchemaFilter != m.tableDetail.showSchemaFilter ||
@@ -309,6 +315,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case key.Matches(msg, m.keyMap.Palette):
+			return m.openPalette()
+
+		case key.Matches(msg, m.keyMap.GoTo):
+			return m.openGoTo()
+
+		case key.Matches(msg, m.keyMap.Mark) && !m.typingInTableDetail():
+			return m.toggleMark()
+
+		case key.Matches(msg, m.keyMap.Compare) && !m.typingInTableDetail():
+			return m.compareWithMarked()
+
+		case key.Matches(msg, m.keyMap.Profile) && !m.typingInTableDetail():
+			inSchema := m.focus == FocusTableDetail && m.tableDetail.activeTab == SchemaTab
+			return m.openProfile(!inSchema)
+
+		case key.Matches(msg, m.keyMap.ProfileAll) && !m.typingInTableDetail():
+			return m.openProfile(true)
+
+		case key.Matches(msg, m.keyMap.Partitions) && !m.typingInTableDetail():
+			return m.openPartitions()
+
+		case key.Matches(msg, m.keyMap.Record) && !m.typingInTableDetail():
+			return m.openRecord()
+
+		case key.Matches(msg, m.keyMap.Bookmark) && !m.typingInTableDetail():
+			return m.toggleBookmark()
+
+		case key.Matches(msg, m.keyMap.SaveResults) && !m.typingInTableDetail():
+			return m.openSaveResults()
+
 		case key.Matches(msg, m.keyMap.Search):
 			// If we're focused on table detail and in schema tab, let table detail handle the search
 			if m.focus == FocusTableDetail && m.tableDetail.activeTab == SchemaTab {
@@ -326,46 +363,162 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateFocusedComponent(msg)
 
 	case DatasetsLoadedMsg:
+		// Ignore responses for a project we've since switched away from
+		if msg.ProjectID != m.currentProjectID() {
+			return m, nil
+		}
+		if msg.Revalidated {
+			return m.applyRevalidatedDatasets(msg)
+		}
+
 		m.datasetList.datasets = msg.Datasets
 		m.loadingDatasets = false
 		m.statusMessage = fmt.Sprintf("Loaded %d datasets", len(msg.Datasets))
-		return m, nil
+		if m.offline && msg.CachedAt.IsZero() {
+			m.statusMessage = fmt.Sprintf("No cached datasets for project %s (offline)", msg.ProjectID)
+		}
+		if msg.Warning != nil {
+			m.statusMessage += fmt.Sprintf(" (warning: %s)", msg.Warning.Error())
+		}
+
+		m.datasetsCache = cacheState{cachedAt: msg.CachedAt}
+		var cmd tea.Cmd
+		if m.needsRevalidation(msg.CachedAt) {
+			m.datasetsCache.refreshing = true
+			cmd = m.fetchDatasets(true)
+		}
+		if target := m.jumpOnDatasets; target != nil {
+			m.jumpOnDatasets = nil
+			model, jumpCmd := m.jumpTo(*target)
+			return model, tea.Batch(cmd, jumpCmd)
+		}
+		return m, cmd
 
 	case TablesLoadedMsg:
 		realDatasetID := msg.DatasetID
 
 		// Only accept tables if they match the currently selected dataset
 		if m.datasetList.selectedDataset != nil && m.datasetList.selectedDataset.ID == realDatasetID {
-			m.datasetList.tables = msg.Tables
+			if msg.Revalidated {
+				return m.applyRevalidatedTables(msg)
+			}
+
+			m.datasetList.setTables(msg.Tables)
 			m.loadingTables = false
 			m.statusMessage = fmt.Sprintf("Loaded %d tables from %s", len(msg.Tables), realDatasetID)
+			if m.offline && msg.CachedAt.IsZero() {
+				m.statusMessage = fmt.Sprintf("Tables of %s are not cached (offline)", realDatasetID)
+			}
+
+			cmds := []tea.Cmd{m.loadVisibleTableDetails()}
+			m.tablesCache = cacheState{cachedAt: msg.CachedAt}
+			if m.needsRevalidation(msg.CachedAt) {
+				m.tablesCache.refreshing = true
+				cmds = append(cmds, m.revalidateTables(realDatasetID))
+			}
 
-			// Auto-select the first table if we're showing tables and have tables
-			if m.datasetList.showingTables && len(msg.Tables) > 0 {
-				m.datasetList.selectedTable = msg.Tables[0]
+			// Auto-select the first table if we're showing tables and have tables,
+			// unless we're jumping to a specific one
+			if m.pendingJump != nil {
+				cmds = append(cmds, m.resolvePendingJump(true))
+			} else if m.datasetList.showingTables && len(m.datasetList.getFilteredTables()) > 0 {
+				m.datasetList.selectedTable = m.datasetList.getFilteredTables()[0]
 				m.loadingSchema = true
 				m.loadingPreview = true
 				// Load schema and preview for the first table
-				return m, tea.Batch(
-					m.loadTableSchema(),
-					m.loadTablePreview(),
-				)
+				cmds = append(cmds, m.loadTableSchema(), m.loadTablePreview())
 			}
+			return m, tea.Batch(cmds...)
 		}
 		return m, nil
 
+	case TablesPageMsg:
+		// Ignore pages from a listing that has been superseded or a dataset no longer shown
+		if msg.LoadID != m.tablesLoadID || m.datasetList.selectedDataset == nil ||
+			m.datasetList.selectedDataset.ID != msg.DatasetID {
+			return m, nil
+		}
+
+		firstPage := len(m.datasetList.tables) == 0
+		m.datasetList.setTables(append(m.datasetList.tables, msg.Tables...))
+
+		var cmds []tea.Cmd
+		if msg.NextPageToken != "" {
+			m.statusMessage = fmt.Sprintf("Loading tables from %s... (%d so far)", msg.DatasetID, len(m.datasetList.tables))
+			cmds = append(cmds, m.fetchTablesPage(msg.DatasetID, msg.LoadID, msg.NextPageToken))
+		} else {
+			m.loadingTables = false
+			m.statusMessage = fmt.Sprintf("Loaded %d tables from %s", len(m.datasetList.tables), msg.DatasetID)
+			cmds = append(cmds, m.cacheTables(msg.DatasetID))
+		}
+
+		// Auto-select the first table once the first page arrives, unless we're
+		// jumping to a specific one
+		if m.pendingJump != nil {
+			cmds = append(cmds, m.resolvePendingJump(msg.NextPageToken == ""))
+		} else if firstPage && m.datasetList.showingTables && len(m.datasetList.getFilteredTables()) > 0 {
+			m.datasetList.selectedTable = m.datasetList.getFilteredTables()[0]
+			m.loadingSchema = true
+			m.loadingPreview = true
+			cmds = append(cmds, m.loadTableSchema(), m.loadTablePreview())
+		}
+
+		cmds = append(cmds, m.loadVisibleTableDetails())
+		return m, tea.Batch(cmds...)
+
+	case TableDetailsLoadedMsg:
+		// Forget failed tables so they are requested again when next on screen
+		loaded := make(map[string]bool, len(msg.Tables))
+		for _, table := range msg.Tables {
+			loaded[table.ID] = true
+		}
+		for _, id := range msg.Requested {
+			if !loaded[id] {
+				delete(m.detailsRequested, id)
+			}
+		}
+
+		if m.datasetList.selectedDataset == nil || m.datasetList.selectedDataset.ID != msg.DatasetID {
+			return m, nil
+		}
+		m.datasetList.mergeTableDetails(msg.Tables)
+		if msg.Warning != nil {
+			m.statusMessage = fmt.Sprintf("Could not load details of some tables in %s: %s", msg.DatasetID, msg.Warning.Error())
+		}
+		// Keep row counts and sizes for next time, once the listing is complete
+		var cmd tea.Cmd
+		if len(msg.Tables) > 0 && !m.loadingTables {
+			cmd = m.cacheTables(msg.DatasetID)
+		}
+		return m, cmd
+
 	case TableSchemaLoadedMsg:
 		// Only accept schema if it matches the currently selected table
-		if m.datasetList.selectedTable != nil &&
-			m.datasetList.selectedTable.DatasetID == msg.DatasetID &&
-			m.datasetList.selectedTable.ID == msg.TableID {
+		if table := m.datasetList.detailTable(); table != nil &&
+			table.DatasetID == msg.DatasetID &&
+			table.ID == msg.TableID {
+			if msg.Revalidated {
+				return m.applyRevalidatedSchema(msg)
+			}
+
 			m.tableDetail.schema = msg.Schema
 			m.tableDetail.currentTableName = msg.TableID
-			m.tableDetail.currentProjectID = m.bqClient.GetProjectID()
+			m.tableDetail.currentProjectID = m.currentProjectID()
 			m.tableDetail.currentDatasetID = msg.DatasetID
 			m.tableDetail.schemaRowCursor = 0 // Reset schema row cursor for new data
 			m.loadingSchema = false
 			m.statusMessage = fmt.Sprintf("Loaded schema for %s.%s", msg.DatasetID, msg.TableID)
+			if m.offline && msg.CachedAt.IsZero() {
+				m.statusMessage = fmt.Sprintf("Schema of %s.%s is not cached (offline)", msg.DatasetID, msg.TableID)
+			}
+
+			m.focusPendingColumn()
+
+			m.schemaCache = cacheState{cachedAt: msg.CachedAt}
+			if m.needsRevalidation(msg.CachedAt) {
+				m.schemaCache.refreshing = true
+				return m, m.fetchTableSchema(msg.DatasetID, msg.TableID, true)
+			}
 		} else {
 			// Ignore stale response from previous table selection
 			m.statusMessage = fmt.Sprintf("Ignored stale schema response for %s.%s", msg.DatasetID, msg.TableID)
@@ -374,19 +527,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case TablePreviewLoadedMsg:
 		// Only accept preview if it matches the currently selected table
-		if m.datasetList.selectedTable != nil &&
-			m.datasetList.selectedTable.DatasetID == msg.DatasetID &&
-			m.datasetList.selectedTable.ID == msg.TableID {
+		if table := m.datasetList.detailTable(); table != nil &&
+			table.DatasetID == msg.DatasetID &&
+			table.ID == msg.TableID {
 			m.tableDetail.preview = msg.Preview
 			if m.tableDetail.currentTableName == "" {
 				m.tableDetail.currentTableName = msg.TableID
-				m.tableDetail.currentProjectID = m.bqClient.GetProjectID()
+				m.tableDetail.currentProjectID = m.currentProjectID()
 				m.tableDetail.currentDatasetID = msg.DatasetID
 			}
+			m.tableDetail.previewSort = nil    // Rows are in the order they were read
 			m.tableDetail.previewRowCursor = 0 // Reset row cursor for new data
 			m.tableDetail.previewColCursor = 0 // Reset column cursor for new data
 			m.loadingPreview = false
-			m.statusMessage = fmt.Sprintf("Loaded preview for %s.%s", msg.DatasetID, msg.TableID)
+			if !m.offline {
+				m.statusMessage = fmt.Sprintf("Loaded preview for %s.%s", msg.DatasetID, msg.TableID)
+			}
 		} else {
 			// Ignore stale response from previous table selection
 			m.statusMessage = fmt.Sprintf("Ignored stale preview response for %s.%s", msg.DatasetID, msg.TableID)
@@ -401,27 +557,146 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ErrorMsg:
 		m.err = msg.Error
 		m.statusMessage = fmt.Sprintf("Error: %s", msg.Error.Error())
+		// A failed background refresh leaves the cached data on screen
+		m.datasetsCache.refreshing = false
+		m.tablesCache.refreshing = false
+		m.schemaCache.refreshing = false
 		return m, nil
 
 	case CopySuccessMsg:
+		if msg.OSC52 != "" && m.output != nil {
+			if _, err := io.WriteString(m.output, msg.OSC52); err != nil {
+				m.statusMessage = fmt.Sprintf("Error: failed to copy: %v", err)
+				return m, nil
+			}
+		}
 		m.statusMessage = fmt.Sprintf("Copied: %s", msg.Text)
 		return m, nil
 
+	case FileSavedMsg:
+		m.statusMessage = fmt.Sprintf("Saved: %s", msg.Path)
+		return m, nil
+
+	case ResultsSavedMsg:
+		m.statusMessage = fmt.Sprintf("Saved query results to %s", msg.Table)
+		return m, nil
+
+	case SaveResultsRejectedMsg:
+		return m.reopenSaveResults(msg.Error)
+
+	case ExportSchemaMsg:
+		return m, m.exportSchema(msg.Format, msg.ToFile)
+
+	case CopyRowsMsg:
+		return m.copyRows(msg.Format)
+
 	case ProjectSelectedMsg:
 		return m, m.switchProject(msg.Project.ID)
 
 	case ProjectSwitchedMsg:
+		if m.offline {
+			m.offlineProjectID = msg.ProjectID
+		}
+		m.pendingJump = nil
+		m.pendingColumn = ""
+		if m.jumpOnDatasets != nil && m.jumpOnDatasets.projectID != msg.ProjectID {
+			m.jumpOnDatasets = nil
+		}
 		m.statusMessage = fmt.Sprintf("Switched to project: %s", msg.ProjectID)
 		m.showProjectList = false
 		m.focus = FocusDatasetList
-		m.datasetList = NewDatasetListModel() // Reset dataset list
-		m.tableDetail = NewTableDetailModel() // Reset table detail
+		m.datasetList = NewDatasetListModel(m.keyMap) // Reset dataset list
+		m.tableDetail = NewTableDetailModel(m.keyMap) // Reset table detail
+		m.tableDetail.offline = m.offline
+		m.datasetList.markedTable = m.markedTableID()
+		m.syncFavorites()
 		m.loadingDatasets = true
 		m.lastSelectedDatasetID = ""
 		m.lastSelectedTableID = ""
+		m.datasetsCache = cacheState{}
+		m.tablesCache = cacheState{}
+		m.schemaCache = cacheState{}
+		m.resetSearchIndex()
 		return m, m.loadDatasets()
 
+	case PaletteSelectedMsg:
+		m.closePalette()
+		return m.jumpTo(jumpTarget{datasetID: msg.DatasetID, tableID: msg.TableID, column: msg.Column})
+
+	case BookmarkSelectedMsg:
+		b := msg.Bookmark
+		return m.jumpTo(jumpTarget{projectID: b.ProjectID, datasetID: b.DatasetID, tableID: b.TableID})
+
+	case DatasetIndexedMsg:
+		return m.handleDatasetIndexed(msg)
+
+	case ProfileLoadedMsg:
+		// Ignore a profile the user has since closed or replaced
+		if !m.showProfile || msg.Table != m.profile.table || msg.Column != m.profile.column {
+			return m, nil
+		}
+		m.profile.setProfile(msg.Profile, msg.Err)
+		if msg.Err == nil {
+			m.statusMessage = fmt.Sprintf("Profiled %d columns", len(msg.Profile.Columns))
+		}
+		return m, nil
+
+	case PartitionsLoadedMsg:
+		// Ignore partitions of a table the user has since moved away from
+		if !m.showPartitions || msg.Table != m.partitions.table {
+			return m, nil
+		}
+		m.partitions.loading = false
+		m.partitions.partitioning = msg.Partitioning
+		m.partitions.partitions = msg.Partitions
+		m.partitions.err = msg.Err
+		return m, nil
+
+	case PartitionSelectedMsg:
+		return m.selectPartition(msg)
+
+	case SchemaDiffLoadedMsg:
+		// Ignore a diff the user has since closed or replaced
+		if !m.showSchemaDiff || msg.From != m.schemaDiff.from || msg.To != m.schemaDiff.to {
+			return m, nil
+		}
+		m.schemaDiff.loading = false
+		m.schemaDiff.diffs = msg.Diffs
+		m.schemaDiff.err = msg.Err
+		if msg.Err == nil {
+			m.statusMessage = fmt.Sprintf("Schema diff: %s", summarizeDiffs(msg.Diffs))
+		}
+		return m, nil
+
+	case SortPreviewMsg:
+		if m.offline || m.tableDetail.preview == nil {
+			return m, nil
+		}
+		m.loadingPreview = true
+		m.statusMessage = "Sorting preview in BigQuery..."
+		return m, m.loadTablePreviewWith(bigquery.PreviewOptions{
+			PartitionID: m.tableDetail.preview.PartitionID,
+			Where:       m.tableDetail.preview.Where,
+			OrderBy:     msg.OrderBy,
+		})
+
+	case FilterPreviewMsg:
+		if m.offline || m.tableDetail.preview == nil {
+			return m, nil
+		}
+		m.loadingPreview = true
+		m.statusMessage = "Filtering preview in BigQuery..."
+		return m, m.loadTablePreviewWith(bigquery.PreviewOptions{
+			PartitionID: m.tableDetail.preview.PartitionID,
+			Where:       msg.Where,
+			OrderBy:     m.tableDetail.preview.OrderBy,
+		})
+
 	case ExecuteQueryMsg:
+		if m.offline {
+			m.statusMessage = "Queries are disabled in offline mode"
+			return m, nil
+		}
 		m.statusMessage = "Executing query..."
 		return m, m.executeQuery(msg.Query)
 
@@ -459,42 +734,7 @@ func (m Model) updateFocusedComponent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case FocusDatasetList:
 		oldShowingTables := m.datasetList.showingTables
 		m.datasetList, cmd = m.datasetList.Update(msg)
-		// Clear the last selected dataset ID when exiting tables view so tables reload when re-entering
-		if oldShowingTables && !m.datasetList.showingTables {
-			m.lastSelectedDatasetID = ""
-		}
-		// Check if user explicitly selected a table (pressed Enter)
-		if m.datasetList.tableSelected {
-			m.datasetList.tableSelected = false // Reset flag
-			m.focus = FocusTableDetail
-			return m, tea.Batch(cmd, m.loadTableSchema(), m.loadTablePreview())
-		}
-		// Load preview when hovering over tables, but don't switch focus
-		if m.datasetList.selectedDataset != nil && m.datasetList.selectedTable != nil {
-			// Only load if table changed to prevent race conditions
-			tableKey := fmt.Sprintf("%s.%s", m.datasetList.selectedDataset.ID, m.datasetList.selectedTable.ID)
-			if m.lastSelectedTableID != tableKey {
-				m.lastSelectedTableID = tableKey
-				m.loadingSchema = true
-				m.loadingPreview = true
-				return m, tea.Batch(cmd, m.loadTableSchema(), m.loadTablePreview())
-			}
-		}
-		if m.datasetList.selectedDataset != nil && m.datasetList.showingTables {
-			// Only load tables if we're in tables view AND dataset changed
-			currentID := m.datasetList.selectedDataset.ID
-			if m.lastSelectedDatasetID != currentID {
-				m.lastSelectedDatasetID = currentID
-				m.lastSelectedTableID = "" // Clear last selected table
-				// Clear table details immediately when dataset changes
-				m.tableDetail.schema = nil
-				m.tableDetail.preview = nil
-				m.tableDetail.currentTableName = ""
-				m.loadingTables = true
-				return m, tea.Batch(cmd, m.loadTables())
-			}
-		}
-		return m, cmd
+		return m.syncDatasetList(oldShowingTables, cmd)
 
 	case FocusTableDetail:
 		m.tableDetail, cmd = m.tableDetail.Update(msg)
@@ -508,46 +748,61 @@ func (m Model) updateFocusedComponent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// syncDatasetList loads whatever the dataset list's new cursor position or
+// selection calls for: tables of a dataset, or the schema and preview of a table
+func (m Model) syncDatasetList(oldShowingTables bool, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	cmd = tea.Batch(cmd, m.loadVisibleTableDetails())
+	// Clear the last selected dataset ID when exiting tables view so tables reload when re-entering
+	if oldShowingTables && !m.datasetList.showingTables {
+		m.lastSelectedDatasetID = ""
+	}
+	// Check if user explicitly selected a table (pressed Enter)
+	if m.datasetList.tableSelected {
+		m.datasetList.tableSelected = false // Reset flag
+		m.focus = FocusTableDetail
+		return m, tea.Batch(cmd, m.loadTableSchema(), m.loadTablePreview())
+	}
+	// Load preview when hovering over tables, but don't switch focus
+	if m.datasetList.selectedDataset != nil && m.datasetList.selectedTable != nil {
+		// Only load if table changed to prevent race conditions
+		tableKey := fmt.Sprintf("%s.%s", m.datasetList.selectedDataset.ID, m.datasetList.selectedTable.ID)
+		if m.lastSelectedTableID != tableKey {
+			m.lastSelectedTableID = tableKey
+			m.loadingSchema = true
+			m.loadingPreview = true
+			return m, tea.Batch(cmd, m.loadTableSchema(), m.loadTablePreview())
+		}
+	}
+	if m.datasetList.selectedDataset != nil && m.datasetList.showingTables {
+		// Only load tables if we're in tables view AND dataset changed
+		currentID := m.datasetList.selectedDataset.ID
+		if m.lastSelectedDatasetID != currentID {
+			m.lastSelectedDatasetID = currentID
+			m.lastSelectedTableID = "" // Clear last selected table
+			// Clear table details immediately when dataset changes
+			m.tableDetail.schema = nil
+			m.tableDetail.preview = nil
+			m.tableDetail.currentTableName = ""
+			loadCmd := m.startLoadingTables()
+			return m, tea.Batch(cmd, loadCmd)
+		}
+	}
+	return m, cmd
+}
+
 func (m Model) handleCopy() (tea.Model, tea.Cmd) {
 	if m.focus == FocusDatasetList && m.datasetList.selectedTable != nil {
 		fullTableName := fmt.Sprintf("%s.%s.%s",
-			m.bqClient.GetProjectID(),
+			m.currentProjectID(),
 			m.datasetList.selectedTable.DatasetID,
 			m.datasetList.selectedTable.ID)
-		return m, func() tea.Msg {
-			if err := clipboard.Copy(fullTableName); err != nil {
-				return ErrorMsg{Error: err}
-			}
-			return CopySuccessMsg{Text: fullTableName}
-		}
+		return m, copyToClipboard(fullTableName, fullTableName)
 	}
 
 	if m.focus == FocusTableDetail && m.tableDetail.activeTab == PreviewTab && m.tableDetail.preview != nil {
-		// Check if in visual mode - copy all selected rows
+		// In visual mode, choose a format for the selected rows
 		if m.tableDetail.visualMode {
-			filteredRows := m.tableDetail.getFilteredPreviewRows()
-			start := min(m.tableDetail.visualStartRow, m.tableDetail.visualEndRow)
-			end := max(m.tableDetail.visualStartRow, m.tableDetail.visualEndRow)
-
-			var selectedData []string
-			for i := start; i <= end && i < len(filteredRows); i++ {
-				row := filteredRows[i]
-				var rowData []string
-				for _, cell := range row {
-					rowData = append(rowData, fmt.Sprintf("%v", cell))
-				}
-				selectedData = append(selectedData, strings.Join(rowData, "\t"))
-			}
-
-			if len(selectedData) > 0 {
-				copyText := strings.Join(selectedData, "\n")
-				return m, func() tea.Msg {
-					if err := clipboard.Copy(copyText); err != nil {
-						return ErrorMsg{Error: err}
-					}
-					return CopySuccessMsg{Text: fmt.Sprintf("Copied %d rows", len(selectedData))}
-				}
-			}
+			return m.copySelection()
 		} else {
 			// Single cell copy mode
 			filteredRows := m.tableDetail.getFilteredPreviewRows()
@@ -555,54 +810,23 @@ func (m Model) handleCopy() (tea.Model, tea.Cmd) {
 				row := filteredRows[m.tableDetail.previewRowCursor]
 				if len(row) > m.tableDetail.previewColCursor {
 					cellValue := fmt.Sprintf("%v", row[m.tableDetail.previewColCursor])
-					return m, func() tea.Msg {
-						if err := clipboard.Copy(cellValue); err != nil {
-							return ErrorMsg{Error: err}
-						}
-						return CopySuccessMsg{Text: fmt.Sprintf("Copied cell: %s", cellValue)}
-					}
+					return m, copyToClipboard(cellValue, fmt.Sprintf("Copied cell: %s", cellValue))
 				}
 			}
 		}
 	}
 
 	if m.focus == FocusTableDetail && m.tableDetail.activeTab == ResultsTab && m.tableDetail.queryResults != nil {
-		// Check if in visual mode - copy all selected rows
+		// In visual mode, choose a format for the selected rows
 		if m.tableDetail.visualMode {
-			start := min(m.tableDetail.visualStartRow, m.tableDetail.visualEndRow)
-			end := max(m.tableDetail.visualStartRow, m.tableDetail.visualEndRow)
-
-			var selectedData []string
-			for i := start; i <= end && i < len(m.tableDetail.queryResults.Rows); i++ {
-				row := m.tableDetail.queryResults.Rows[i]
-				var rowData []string
-				for _, cell := range row {
-					rowData = append(rowData, fmt.Sprintf("%v", cell))
-				}
-				selectedData = append(selectedData, strings.Join(rowData, "\t"))
-			}
-
-			if len(selectedData) > 0 {
-				copyText := strings.Join(selectedData, "\n")
-				return m, func() tea.Msg {
-					if err := clipboard.Copy(copyText); err != nil {
-						return ErrorMsg{Error: err}
-					}
-					return CopySuccessMsg{Text: fmt.Sprintf("Copied %d rows", len(selectedData))}
-				}
-			}
+			return m.copySelection()
 		} else {
 			// Single cell copy mode
 			if len(m.tableDetail.queryResults.Rows) > m.tableDetail.resultsRowCursor {
-				row := m.tableDetail.queryResults.Rows[m.tableDetail.resultsRowCursor]
+				row := m.tableDetail.getResultRows()[m.tableDetail.resultsRowCursor]
 				if len(row) > m.tableDetail.resultsColCursor {
 					cellValue := fmt.Sprintf("%v", row[m.tableDetail.resultsColCursor])
-					return m, func() tea.Msg {
-						if err := clipboard.Copy(cellValue); err != nil {
-							return ErrorMsg{Error: err}
-						}
-						return CopySuccessMsg{Text: fmt.Sprintf("Copied cell: %s", cellValue)}
-					}
+					return m, copyToClipboard(cellValue, fmt.Sprintf("Copied cell: %s", cellValue))
 				}
 			}
 		}
@@ -612,24 +836,14 @@ func (m Model) handleCopy() (tea.Model, tea.Cmd) {
 		filteredFields := m.tableDetail.getFilteredSchemaFields()
 		if len(filteredFields) > m.tableDetail.schemaRowCursor {
 			fieldName := filteredFields[m.tableDetail.schemaRowCursor].Name
-			return m, func() tea.Msg {
-				if err := clipboard.Copy(fieldName); err != nil {
-					return ErrorMsg{Error: err}
-				}
-				return CopySuccessMsg{Text: fmt.Sprintf("Copied field name: %s", fieldName)}
-			}
+			return m, copyToClipboard(fieldName, fmt.Sprintf("Copied field name: %s", fieldName))
 		}
 	}
 
 	if m.focus == FocusTableDetail && m.tableDetail.activeTab == QueryTab {
 		queryText := m.tableDetail.queryInput.Value()
 		if queryText != "" {
-			return m, func() tea.Msg {
-				if err := clipboard.Copy(queryText); err != nil {
-					return ErrorMsg{Error: err}
-				}
-				return CopySuccessMsg{Text: "Copied query"}
-			}
+			return m, copyToClipboard(queryText, "Copied query")
 		}
 	}
 
@@ -645,6 +859,30 @@ func (m Model) View() string {
 		return m.projectSelector.View()
 	}
 
+	if m.showPalette {
+		return m.palette.View()
+	}
+
+	if m.showSchemaDiff {
+		return m.schemaDiff.View()
+	}
+
+	if m.showProfile {
+		return m.profile.View()
+	}
+
+	if m.showPartitions {
+		return m.partitions.View()
+	}
+
+	if m.showRecord {
+		return m.record.View()
+	}
+
+	if m.showSaveResults {
+		return m.saveResults.View()
+	}
+
 	if m.showHelp {
 		return m.renderCustomHelp()
 	}
@@ -655,8 +893,11 @@ func (m Model) View() string {
 	// Calculate actual content height first, then derive pane heights
 	statusBar := m.renderStatusBar()
 	searchBar := ""
-	if m.focus == FocusSearch {
+	switch m.focus {
+	case FocusSearch:
 		searchBar = SearchBoxStyle.Render(fmt.Sprintf("Search: %s", m.search.View()))
+	case FocusGoTo:
+		searchBar = SearchBoxStyle.Render(fmt.Sprintf("Go to: %s", m.goTo.View()))
 	}
 
 	// Calculate heights of UI elements
@@ -708,20 +949,16 @@ func (m Model) View() string {
 }
 
 func (m Model) renderProjectHeader() string {
-	projectID := m.bqClient.GetProjectID()
+	projectID := m.currentProjectID()
 	if projectID == "" {
 		projectID = "No project selected"
 	}
 
 	headerText := fmt.Sprintf("🔗 Google Cloud Project: %s", projectID)
-	headerStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#2D2D2D")).
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Bold(true).
-		Padding(0, 1).
-		Width(m.width)
-
-	return headerStyle.Render(headerText)
+	if m.offline {
+		headerText += "  [OFFLINE - browsing cached metadata, queries disabled]"
+	}
+	return ProjectHeaderStyle.Width(m.width).Render(headerText)
 }
 
 func (m Model) renderStatusBar() string {
@@ -730,7 +967,10 @@ func (m Model) renderStatusBar() string {
 		left = ErrorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error()))
 	}
 
-	helpText := "Press ? for help"
+	helpText := fmt.Sprintf("Press %s for help", m.keyMap.Help.Help().Key)
+	if indicator := m.currentCacheState().String(); indicator != "" {
+		helpText = indicator + " • " + helpText
+	}
 	helpStyled := HelpStyle.Render(helpText)
 
 	// Check if status message and help text fit on one line
@@ -757,33 +997,18 @@ func (m Model) renderCustomHelp() string {
 
 	content.WriteString(HeaderStyle.Render("🔧 bqui - BigQuery Terminal UI Help") + "\n\n")
 
-	content.WriteString(HeaderStyle.Render("Navigation:") + "\n")
-	content.WriteString("  ↑↓ or j/k         Move up/down in lists\n")
-	content.WriteString("  ←→ or h/l         Horizontal scroll (in schema/preview)\n")
-	content.WriteString("  Enter             Select dataset/table\n")
-	content.WriteString("  Esc               Go back / cancel\n\n")
-
-	content.WriteString(HeaderStyle.Render("Tabs (Right Pane):") + "\n")
-	content.WriteString("  Tab               Next tab (Schema → Preview → Query)\n")
-	content.WriteString("  Shift+Tab         Previous tab (Query → Preview → Schema)\n\n")
-
-	content.WriteString(HeaderStyle.Render("Search & Filter:") + "\n")
-	content.WriteString("  /                 Search/filter datasets or columns\n")
-	content.WriteString("  Esc               Clear filter\n\n")
-
-	content.WriteString(HeaderStyle.Render("Actions:") + "\n")
-	content.WriteString("  y or Ctrl+Y       Copy table name to clipboard\n")
-	content.WriteString("  Ctrl+Space/Alt+P  Switch projects\n\n")
-
-	content.WriteString(HeaderStyle.Render("Vim Shortcuts:") + "\n")
-	content.WriteString("  g / Home          Go to top\n")
-	content.WriteString("  G / End           Go to bottom\n")
-	content.WriteString("  Page Up/Down      Page navigation\n\n")
-
-	content.WriteString(HeaderStyle.Render("Other:") + "\n")
-	content.WriteString("  r or Ctrl+R       Refresh/clear cache\n")
-	content.WriteString("  ?                 Show/hide this help\n")
-	content.WriteString("  q or Ctrl+C       Quit\n\n")
+	// Generated from the active key map so remapped keys are always shown correctly
+	for _, section := range m.keyMap.HelpSections() {
+		content.WriteString(HeaderStyle.Render(section.Title+":") + "\n")
+		for _, binding := range section.Bindings {
+			if !binding.Enabled() {
+				continue
+			}
+			bindingHelp := binding.Help()
+			content.WriteString(fmt.Sprintf("  %-18s %s\n", bindingHelp.Key, bindingHelp.Desc))
+		}
+		content.WriteString("\n")
+	}
 
 	content.WriteString(HelpStyle.Render("Press any key to close help"))
 
@@ -792,12 +1017,17 @@ func (m Model) renderCustomHelp() string {
 
 // handleRefresh clears cache and reloads data based on current focus
 func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
+	if m.offline {
+		// Clearing the cache would leave nothing to browse
+		m.statusMessage = "Refresh is unavailable in offline mode"
+		return m, nil
+	}
 	if m.cache == nil {
 		// No cache to clear, just reload
 		return m.reloadCurrentView()
 	}
 
-	projectID := m.bqClient.GetProjectID()
+	projectID := m.currentProjectID()
 	var err error
 
 	switch m.focus {
@@ -812,7 +1042,8 @@ func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
 	case FocusTableDetail:
 		if m.datasetList.selectedTable != nil {
 			// Clear schema cache for this table regardless of active tab
-			err = m.cache.ClearSchema(projectID, m.datasetList.selectedTable.DatasetID, m.datasetList.selectedTable.ID)
+			table := m.datasetList.detailTable()
+			err = m.cache.ClearSchema(projectID, table.DatasetID, table.ID)
 		}
 	}
 
@@ -832,8 +1063,9 @@ func (m Model) reloadCurrentView() (tea.Model, tea.Cmd) {
 	case FocusDatasetList:
 		if m.datasetList.showingTables && m.datasetList.selectedDataset != nil {
 			// Reload tables
-			m.loadingTables = true
-			return m, m.loadTables()
+			m.datasetList.setTables(make([]*bigquery.Table, 0))
+			loadCmd := m.startLoadingTables()
+			return m, loadCmd
 		} else {
 			// Reload datasets
 			m.loadingDatasets = true
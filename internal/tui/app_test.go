@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bqui/internal/bigquery"
+	"bqui/internal/cache"
+)
+
+func TestTablePagesAndLazyDetails(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap()})
+	dataset := &bigquery.Dataset{ID: "ds"}
+	m.datasetList.datasets = []*bigquery.Dataset{dataset}
+	m.datasetList.selectDatasetByID("ds", true)
+	m.datasetList.height = 14
+	m.startLoadingTables()
+
+	page := func(from, to int) []*bigquery.Table {
+		var tables []*bigquery.Table
+		for i := from; i < to; i++ {
+			tables = append(tables, &bigquery.Table{ID: fmt.Sprintf("t%03d", i), DatasetID: "ds"})
+		}
+		return tables
+	}
+
+	// A page from a superseded listing is dropped
+	model, _ := m.Update(TablesPageMsg{DatasetID: "ds", LoadID: m.tablesLoadID - 1, Tables: page(0, 5)})
+	m = model.(Model)
+	if len(m.datasetList.tables) != 0 {
+		t.Fatalf("Expected a stale page to be ignored, got %d tables", len(m.datasetList.tables))
+	}
+
+	model, cmd := m.Update(TablesPageMsg{DatasetID: "ds", LoadID: m.tablesLoadID, Tables: page(0, 50), NextPageToken: "next"})
+	m = model.(Model)
+	if cmd == nil || !m.loadingTables || len(m.datasetList.tables) != 50 {
+		t.Fatalf("Expected the first page to be shown while the next loads, got %d tables (loading %v)", len(m.datasetList.tables), m.loadingTables)
+	}
+	visible := m.datasetList.visibleTables()
+	if len(visible) == 0 || len(visible) >= 50 {
+		t.Fatalf("Expected only part of the list on screen, got %d tables", len(visible))
+	}
+	for _, table := range visible {
+		if !m.detailsRequested[table.ID] {
+			t.Errorf("Expected details of visible table %s to be requested", table.ID)
+		}
+	}
+	if m.detailsRequested["t049"] {
+		t.Error("Expected details of off-screen tables to wait until they are shown")
+	}
+
+	model, _ = m.Update(TablesPageMsg{DatasetID: "ds", LoadID: m.tablesLoadID, Tables: page(50, 60)})
+	m = model.(Model)
+	if m.loadingTables || len(m.datasetList.tables) != 60 {
+		t.Fatalf("Expected the last page to finish loading with 60 tables, got %d (loading %v)", len(m.datasetList.tables), m.loadingTables)
+	}
+
+	// t000 loads; t001 fails and is forgotten so it can be requested again
+	loaded := &bigquery.Table{ID: "t000", DatasetID: "ds", NumRows: 42, DetailsLoaded: true}
+	model, _ = m.Update(TableDetailsLoadedMsg{
+		DatasetID: "ds",
+		Requested: []string{"t000", "t001"},
+		Tables:    []*bigquery.Table{loaded},
+		Warning:   errors.New("t001: permission denied"),
+	})
+	m = model.(Model)
+	if m.datasetList.tables[0].NumRows != 42 {
+		t.Errorf("Expected details to be merged, got %+v", m.datasetList.tables[0])
+	}
+	if m.statusMessage == "" || !m.detailsRequested["t000"] || m.detailsRequested["t001"] {
+		t.Errorf("Expected a warning and t001 to be retried, got status %q and requested %v", m.statusMessage, m.detailsRequested)
+	}
+	if cmd := m.loadVisibleTableDetails(); cmd == nil || !m.detailsRequested["t001"] {
+		t.Error("Expected the failed table to be requested again")
+	}
+}
+
+func TestOfflineBrowsesCacheOnly(t *testing.T) {
+	c, err := cache.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+	c.SetTTL(0) // Everything is stale, yet nothing may be refreshed offline
+	if err := c.SetDatasets("cached", []*bigquery.Dataset{{ID: "ds", ProjectID: "cached"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Cache: c, Offline: true, ProjectID: "cached"})
+	msg, ok := m.loadDatasets()().(DatasetsLoadedMsg)
+	if !ok || len(msg.Datasets) != 1 || msg.CachedAt.IsZero() {
+		t.Fatalf("Expected the cached datasets, got %#v", msg)
+	}
+	model, cmd := m.Update(msg)
+	m = model.(Model)
+	if cmd != nil || m.datasetsCache.refreshing {
+		t.Error("Expected no refresh in offline mode")
+	}
+	if header := m.renderProjectHeader(); !strings.Contains(header, "OFFLINE") {
+		t.Errorf("Expected the header to say offline, got %q", header)
+	}
+
+	m.datasetList.selectDatasetByID("ds", true)
+	m.startLoadingTables()
+	tables, ok := m.loadTables()().(TablesLoadedMsg)
+	if !ok {
+		t.Fatalf("Expected an empty listing for uncached tables, got %#v", tables)
+	}
+	model, _ = m.Update(tables)
+	if status := model.(Model).statusMessage; status != "Tables of ds are not cached (offline)" {
+		t.Errorf("Unexpected status %q", status)
+	}
+
+	model, cmd = m.Update(ExecuteQueryMsg{Query: "SELECT 1"})
+	if status := model.(Model).statusMessage; cmd != nil || status != "Queries are disabled in offline mode" {
+		t.Errorf("Expected queries to be disabled, got %q", status)
+	}
+	model, _ = m.handleRefresh()
+	if _, _, found := c.GetDatasets("cached"); !found || !strings.Contains(model.(Model).statusMessage, "offline") {
+		t.Error("Expected refresh to keep the cache in offline mode")
+	}
+}
+
+func TestTableDetailsAreCached(t *testing.T) {
+	c, err := cache.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+
+	m := NewModel(context.Background(), &bigquery.Client{}, Options{KeyMap: DefaultKeyMap(), Cache: c})
+	m.datasetList.datasets = []*bigquery.Dataset{{ID: "ds"}}
+	m.datasetList.selectDatasetByID("ds", true)
+	m.datasetList.height = 14
+	m.startLoadingTables()
+
+	model, cmd := m.Update(TablesPageMsg{DatasetID: "ds", LoadID: m.tablesLoadID, Tables: []*bigquery.Table{{ID: "a", DatasetID: "ds"}}})
+	m = model.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the listing to be cached")
+	}
+	model, cmd = m.Update(TableDetailsLoadedMsg{
+		DatasetID: "ds",
+		Requested: []string{"a"},
+		Tables:    []*bigquery.Table{{ID: "a", DatasetID: "ds", NumRows: 42, DetailsLoaded: true}},
+	})
+	m = model.(Model)
+	if cmd == nil {
+		t.Fatal("Expected the merged details to be cached")
+	}
+	cmd()
+
+	// The cached copy doesn't change when the list on screen does
+	m.datasetList.tables[0] = &bigquery.Table{ID: "changed"}
+	tables, _, found := c.GetTables("", "ds")
+	if !found || len(tables) != 1 || tables[0].NumRows != 42 || !tables[0].DetailsLoaded {
+		t.Errorf("Expected the table with its details in the cache, got %+v", tables)
+	}
+}
+
+func TestShardGroupsFollowTableList(t *testing.T) {
+	m := NewDatasetListModel(DefaultKeyMap())
+	m.showingTables = true
+	m.setTables([]*bigquery.Table{
+		{ID: "events_20240101", DatasetID: "ds"},
+		{ID: "events_20240102", DatasetID: "ds"},
+		{ID: "users", DatasetID: "ds"},
+	})
+
+	entries := m.getFilteredTables()
+	if len(entries) != 2 || entries[0].ID != "events_*" {
+		t.Fatalf("Expected the shards grouped as events_*, got %d entries", len(entries))
+	}
+
+	m.expanded["ds.events_*"] = true
+	entries = m.getFilteredTables()
+	if nested := nestedShards(entries); len(entries) != 4 || nested[0] || !nested[1] || !nested[2] || nested[3] {
+		t.Fatalf("Expected both shards nested under the expanded group, got %v", nested)
+	}
+
+	m.mergeTableDetails([]*bigquery.Table{{ID: "users", DatasetID: "ds", NumRows: 7, DetailsLoaded: true}})
+	entries = m.getFilteredTables()
+	if users := entries[len(entries)-1]; users.NumRows != 7 {
+		t.Errorf("Expected merged details in the grouped entries, got %+v", users)
+	}
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"bqui/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleBookmark stars the selected table, or unstars it (or the favorite
+// under the cursor) if it's already a favorite
+func (m Model) toggleBookmark() (tea.Model, tea.Cmd) {
+	var bookmark config.Bookmark
+	if favorite, ok := m.datasetList.selectedFavorite(); ok && m.focus == FocusDatasetList {
+		bookmark = favorite
+	} else {
+		// Shard groups are starred as their wildcard entry
+		table := m.datasetList.selectedTable
+		if table == nil || !m.datasetList.showingTables {
+			m.statusMessage = "Select a table to star"
+			return m, nil
+		}
+		bookmark = config.Bookmark{ProjectID: m.currentProjectID(), DatasetID: table.DatasetID, TableID: table.ID}
+	}
+
+	// Work on a copy, since a save may still be reading the old list
+	if i := slices.Index(m.bookmarks, bookmark); i >= 0 {
+		m.bookmarks = slices.Delete(slices.Clone(m.bookmarks), i, i+1)
+		m.statusMessage = fmt.Sprintf("Removed %s from favorites", bookmark)
+	} else {
+		m.bookmarks = append(slices.Clone(m.bookmarks), bookmark)
+		m.statusMessage = fmt.Sprintf("Added %s to favorites", bookmark)
+	}
+
+	m.syncFavorites()
+	if items := len(m.datasetList.getFilteredItems()); m.datasetList.cursor >= items {
+		m.datasetList.cursor = max(items-1, 0)
+	}
+	m.datasetList.updateSelection()
+	m.datasetList.ensureCursorVisible(len(m.datasetList.getFilteredItems()))
+	return m, m.saveBookmarks()
+}
+
+// syncFavorites shows the current bookmarks in the dataset list
+func (m *Model) syncFavorites() {
+	m.datasetList.favorites = m.bookmarks
+	m.datasetList.projectID = m.currentProjectID()
+}
+
+// bookmarkSaver serializes bookmark saves. Commands run concurrently, so each
+// save is numbered when it's issued and one older than the last written is
+// dropped rather than overwriting newer bookmarks.
+type bookmarkSaver struct {
+	mu      sync.Mutex
+	issued  int
+	written int
+}
+
+// saveBookmarks writes the bookmarks to the config directory
+func (m Model) saveBookmarks() tea.Cmd {
+	if m.bookmarksPath == "" {
+		return nil
+	}
+	saver := m.bookmarkSaver
+	saver.mu.Lock()
+	saver.issued++
+	seq := saver.issued
+	saver.mu.Unlock()
+
+	path, bookmarks := m.bookmarksPath, m.bookmarks
+	return func() tea.Msg {
+		saver.mu.Lock()
+		defer saver.mu.Unlock()
+		if seq < saver.written {
+			return nil
+		}
+		if err := config.SaveBookmarks(path, bookmarks); err != nil {
+			return ErrorMsg{Error: err}
+		}
+		saver.written = seq
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bqui/internal/bigquery"
+	"bqui/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFavoritesListedAboveDatasets(t *testing.T) {
+	m := NewDatasetListModel(DefaultKeyMap())
+	m.projectID = "here"
+	m.datasets = []*bigquery.Dataset{{ID: "sales"}, {ID: "logs"}}
+	m.favorites = []config.Bookmark{
+		{ProjectID: "elsewhere", DatasetID: "web", TableID: "events"},
+		{ProjectID: "here", DatasetID: "sales", TableID: "orders"},
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "web.events  elsewhere") || strings.Contains(view, "orders  here") {
+		t.Errorf("Expected only favorites of other projects to name their project:\n%s", view)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(BookmarkSelectedMsg); !ok || msg.Bookmark.TableID != "events" {
+		t.Fatalf("Expected Enter on a favorite to select it, got %#v", msg)
+	}
+
+	m.cursor = 2
+	m.updateSelection()
+	if m.selectedDataset == nil || m.selectedDataset.ID != "sales" {
+		t.Fatalf("Expected the first dataset after the favorites, got %v", m.selectedDataset)
+	}
+
+	m.filter = "log"
+	m.replaceDatasets(m.datasets)
+	if items := m.getFilteredItems(); len(items) != 1 || items[0] != "logs" {
+		t.Errorf("Expected the filter to apply to favorites too, got %v", items)
+	}
+
+	m.filter = ""
+	m.showingTables = true
+	if !m.isStarred(&bigquery.Table{DatasetID: "sales", ID: "orders"}) ||
+		m.isStarred(&bigquery.Table{DatasetID: "web", ID: "events"}) {
+		t.Error("Expected only favorites of the browsed project to be starred")
+	}
+}
+
+func TestBookmarkSavesKeepNewest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "p", BookmarksPath: path})
+
+	older := config.Bookmark{ProjectID: "p", DatasetID: "d", TableID: "older"}
+	newer := config.Bookmark{ProjectID: "p", DatasetID: "d", TableID: "newer"}
+	m.bookmarks = []config.Bookmark{older}
+	first := m.saveBookmarks()
+	m.bookmarks = []config.Bookmark{older, newer}
+	second := m.saveBookmarks()
+
+	// Commands run concurrently, so the earlier save may finish last
+	second()
+	first()
+
+	saved, err := config.LoadBookmarks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[1] != newer {
+		t.Errorf("Expected the newest bookmarks to be kept, got %v", saved)
+	}
+	if files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(files) != 0 {
+		t.Errorf("Expected no temp files left behind, got %v", files)
+	}
+}
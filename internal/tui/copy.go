@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"bqui/internal/bigquery"
+	"bqui/pkg/clipboard"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyToClipboard copies text in the background, reporting status once done.
+// An OSC 52 sequence comes back in the message so Update can write it to the
+// terminal between frames.
+func copyToClipboard(text, status string) tea.Cmd {
+	return func() tea.Msg {
+		seq, err := clipboard.Copy(text)
+		if err != nil {
+			return ErrorMsg{Error: err}
+		}
+		return CopySuccessMsg{Text: status, OSC52: seq}
+	}
+}
+
+// copySelection opens the format chooser for the visual selection, or copies
+// in the highlighted format when it's already open
+func (m Model) copySelection() (tea.Model, tea.Cmd) {
+	if m.tableDetail.showCopyDialog {
+		m.tableDetail.showCopyDialog = false
+		return m.copyRows(bigquery.RowFormats[m.tableDetail.copyCursor])
+	}
+	m.tableDetail.showCopyDialog = true
+	return m, nil
+}
+
+// copyRows copies the rows of the visual selection in a format
+func (m Model) copyRows(format bigquery.RowFormat) (tea.Model, tea.Cmd) {
+	set, ok := m.selectedRowSet()
+	if !ok {
+		return m, nil
+	}
+	text, err := set.Format(format)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Nothing copied: %v", err)
+		return m, nil
+	}
+
+	count := len(set.Rows)
+	return m, copyToClipboard(text, fmt.Sprintf("Copied %d rows as %s", count, format))
+}
+
+// selectedRowSet collects the rows of the visual selection with every column
+// of the Preview or Results tab, not only those the grid shows
+func (m Model) selectedRowSet() (bigquery.RowSet, bool) {
+	td := m.tableDetail
+	start := min(td.visualStartRow, td.visualEndRow)
+	end := max(td.visualStartRow, td.visualEndRow)
+
+	var set bigquery.RowSet
+	var rows [][]interface{}
+	switch td.activeTab {
+	case PreviewTab:
+		if td.preview == nil {
+			return set, false
+		}
+		rows = td.getFilteredPreviewRows()
+		set.Headers = td.preview.Headers
+		if td.preview.Schema != nil {
+			set.Fields = td.preview.Schema.Fields
+		}
+		set.Column = td.previewColCursor
+		if ref, ok := m.selectedTableRef(); ok && ref.tableID == td.currentTableName {
+			set.Table = ref.String()
+		}
+	case ResultsTab:
+		if td.queryResults == nil {
+			return set, false
+		}
+		rows = td.getResultRows()
+		set.Headers, set.Fields = td.resultsColumns()
+		set.Column = td.resultsColCursor
+	default:
+		return set, false
+	}
+	if start >= len(rows) {
+		return set, false
+	}
+	set.Rows = rows[start:min(end+1, len(rows))]
+	return set, true
+}
+
+// handleCopyDialogKey picks the format to copy the visual selection in
+func (m TableDetailModel) handleCopyDialogKey(msg tea.KeyMsg) (TableDetailModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Up):
+		if m.copyCursor > 0 {
+			m.copyCursor--
+		}
+	case key.Matches(msg, m.keyMap.Down):
+		if m.copyCursor < len(bigquery.RowFormats)-1 {
+			m.copyCursor++
+		}
+	case key.Matches(msg, m.keyMap.Enter):
+		format := bigquery.RowFormats[m.copyCursor]
+		m.showCopyDialog = false
+		return m, func() tea.Msg { return CopyRowsMsg{Format: format} }
+	}
+	return m, nil
+}
+
+// copyDialogHeight is the number of lines the format chooser takes
+func (m TableDetailModel) copyDialogHeight() int {
+	if !m.showCopyDialog {
+		return 0
+	}
+	return 6 + len(bigquery.RowFormats)
+}
+
+func (m TableDetailModel) renderCopyDialog() string {
+	var content strings.Builder
+
+	start := min(m.visualStartRow, m.visualEndRow)
+	end := max(m.visualStartRow, m.visualEndRow)
+	content.WriteString(HeaderStyle.Render(fmt.Sprintf("Copy %d rows as", end-start+1)) + "\n\n")
+	for i, format := range bigquery.RowFormats {
+		if i == m.copyCursor {
+			content.WriteString(SelectedItemStyle.Render(fmt.Sprintf("► %s", format)) + "\n")
+		} else {
+			content.WriteString(ItemStyle.Render(fmt.Sprintf("  %s", format)) + "\n")
+		}
+	}
+
+	content.WriteString("\n" + HelpStyle.Render("↑↓ to choose • Enter or y to copy • Esc to cancel"))
+
+	return content.String()
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"bqui/internal/bigquery"
+
+	bq "cloud.google.com/go/bigquery"
+)
+
+func TestCopyWidePreviewSelection(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "p"})
+	m.focus = FocusTableDetail
+	m.tableDetail.activeTab = PreviewTab
+
+	schema := &bigquery.TableSchema{}
+	var headers []string
+	var rows [][]interface{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("col%02d", i)
+		schema.Fields = append(schema.Fields, &bigquery.Column{Name: name, Type: bq.IntegerFieldType})
+		headers = append(headers, name)
+	}
+	for r := 0; r < 3; r++ {
+		var row []interface{}
+		for i := 0; i < 20; i++ {
+			row = append(row, int64(100*r+i))
+		}
+		rows = append(rows, row)
+	}
+	m.tableDetail.preview = &bigquery.TablePreview{Schema: schema, Headers: headers, Rows: rows}
+	m.tableDetail.visualMode = true
+	m.tableDetail.visualStartRow, m.tableDetail.visualEndRow = 1, 2
+
+	set, ok := m.selectedRowSet()
+	if !ok || len(set.Rows) != 2 || len(set.Headers) != 20 {
+		t.Fatalf("Expected 2 rows of 20 columns, got %d rows of %d", len(set.Rows), len(set.Headers))
+	}
+
+	insert, err := set.Format(bigquery.RowsInsert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(insert, "...") || !strings.Contains(insert, "col19) VALUES (100,") || !strings.HasSuffix(insert, ", 219);") {
+		t.Errorf("Expected INSERTs of every column:\n%s", insert)
+	}
+	if csv, _ := set.Format(bigquery.RowsCSV); !strings.HasPrefix(csv, "col00,") || !strings.Contains(csv, ",col19\n") {
+		t.Errorf("Expected a CSV header of every column:\n%s", csv)
+	}
+}
+
+func TestCopyWritesOSC52ThroughUpdate(t *testing.T) {
+	var out strings.Builder
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "p", Output: &out})
+
+	model, _ := m.Update(CopySuccessMsg{Text: "a.b.c", OSC52: "\x1b]52;c;YS5iLmM=\a"})
+	if got := out.String(); got != "\x1b]52;c;YS5iLmM=\a" {
+		t.Errorf("Expected the sequence on the program's output, got %q", got)
+	}
+	if status := model.(Model).statusMessage; status != "Copied: a.b.c" {
+		t.Errorf("Unexpected status %q", status)
+	}
+
+	out.Reset()
+	m.Update(CopySuccessMsg{Text: "a.b.c"})
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written when the system clipboard was used, got %q", out.String())
+	}
+}
@@ -3,16 +3,20 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"bqui/internal/bigquery"
+	"bqui/internal/config"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type DatasetListModel struct {
-	datasets        []*bigquery.Dataset
-	tables          []*bigquery.Table
+	datasets []*bigquery.Dataset
+	tables   []*bigquery.Table
+	// entries is tables with date shards grouped, rebuilt by setTables
+	entries         []*bigquery.Table
 	selectedDataset *bigquery.Dataset
 	selectedTable   *bigquery.Table
 	cursor          int
@@ -21,12 +25,22 @@ type DatasetListModel struct {
 	viewOffset      int
 	tableSelected   bool
 	height          int
+	// markedTable is the "dataset.table" ID of the table marked for schema diff
+	markedTable string
+	// expanded holds the "dataset.wildcard" IDs of shard groups showing their shards
+	expanded map[string]bool
+	// favorites are the starred tables of every project, listed above the
+	// datasets; projectID is the project being browsed, to flag its own
+	favorites []config.Bookmark
+	projectID string
+	keyMap    KeyMap
 }
 
-func NewDatasetListModel() DatasetListModel {
+func NewDatasetListModel(keyMap KeyMap) DatasetListModel {
 	return DatasetListModel{
 		datasets:        make([]*bigquery.Dataset, 0),
 		tables:          make([]*bigquery.Table, 0),
+		entries:         make([]*bigquery.Table, 0),
 		selectedDataset: nil,
 		selectedTable:   nil,
 		cursor:          0,
@@ -35,6 +49,8 @@ func NewDatasetListModel() DatasetListModel {
 		viewOffset:      0,
 		tableSelected:   false,
 		height:          20,
+		expanded:        make(map[string]bool),
+		keyMap:          keyMap,
 	}
 }
 
@@ -55,7 +71,10 @@ func (m DatasetListModel) handleKeypress(msg tea.KeyMsg) (DatasetListModel, tea.
 
 	// Always allow navigation keys that don't depend on items
 	switch {
-	case key.Matches(msg, DefaultKeyMap().Left):
+	case key.Matches(msg, m.keyMap.Left):
+		if m.collapseShards() {
+			return m, nil
+		}
 		if m.showingTables {
 			m.showingTables = false
 			m.selectedTable = nil
@@ -63,11 +82,15 @@ func (m DatasetListModel) handleKeypress(msg tea.KeyMsg) (DatasetListModel, tea.
 		}
 		return m, nil
 
-	case key.Matches(msg, DefaultKeyMap().Right):
+	case key.Matches(msg, m.keyMap.Right):
+		if m.showingTables && m.selectedTable != nil && m.selectedTable.Shards != nil {
+			m.expanded[shardGroupKey(m.selectedTable)] = true
+			return m, nil
+		}
 		if !m.showingTables && m.selectedDataset != nil {
 			m.showingTables = true
 			m.cursor = 0
-			m.tables = make([]*bigquery.Table, 0) // Clear old table list immediately
+			m.setTables(make([]*bigquery.Table, 0)) // Clear old table list immediately
 		}
 		return m, nil
 	}
@@ -78,21 +101,21 @@ func (m DatasetListModel) handleKeypress(msg tea.KeyMsg) (DatasetListModel, tea.
 	}
 
 	switch {
-	case key.Matches(msg, DefaultKeyMap().Up):
+	case key.Matches(msg, m.keyMap.Up):
 		if m.cursor > 0 {
 			m.cursor--
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Down):
+	case key.Matches(msg, m.keyMap.Down):
 		if m.cursor < len(filteredItems)-1 {
 			m.cursor++
 		}
 
-	case key.Matches(msg, DefaultKeyMap().VimTop), key.Matches(msg, DefaultKeyMap().Top):
+	case key.Matches(msg, m.keyMap.VimTop), key.Matches(msg, m.keyMap.Top):
 		m.cursor = 0
 		m.viewOffset = 0
 
-	case key.Matches(msg, DefaultKeyMap().VimBottom), key.Matches(msg, DefaultKeyMap().Bottom):
+	case key.Matches(msg, m.keyMap.VimBottom), key.Matches(msg, m.keyMap.Bottom):
 		m.cursor = len(filteredItems) - 1
 		// Scroll to show the bottom item
 		maxVisible := m.getMaxVisible()
@@ -102,26 +125,31 @@ func (m DatasetListModel) handleKeypress(msg tea.KeyMsg) (DatasetListModel, tea.
 			m.viewOffset = 0
 		}
 
-	case key.Matches(msg, DefaultKeyMap().PageUp):
+	case key.Matches(msg, m.keyMap.PageUp):
 		m.cursor -= 10
 		if m.cursor < 0 {
 			m.cursor = 0
 		}
 
-	case key.Matches(msg, DefaultKeyMap().PageDown):
+	case key.Matches(msg, m.keyMap.PageDown):
 		m.cursor += 10
 		if m.cursor >= len(filteredItems) {
 			m.cursor = len(filteredItems) - 1
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Enter):
+	case key.Matches(msg, m.keyMap.Enter):
 		if !m.showingTables {
-			if m.cursor < len(m.getFilteredDatasets()) {
-				m.selectedDataset = m.getFilteredDatasets()[m.cursor]
+			favorites := m.getFilteredFavorites()
+			if m.cursor < len(favorites) {
+				bookmark := favorites[m.cursor]
+				return m, func() tea.Msg { return BookmarkSelectedMsg{Bookmark: bookmark} }
+			}
+			if i := m.cursor - len(favorites); i < len(m.getFilteredDatasets()) {
+				m.selectedDataset = m.getFilteredDatasets()[i]
 				m.showingTables = true
 				m.cursor = 0
-				m.selectedTable = nil                 // Reset table selection
-				m.tables = make([]*bigquery.Table, 0) // Clear old table list immediately
+				m.selectedTable = nil                   // Reset table selection
+				m.setTables(make([]*bigquery.Table, 0)) // Clear old table list immediately
 				return m, nil
 			}
 		} else {
@@ -142,8 +170,8 @@ func (m DatasetListModel) handleKeypress(msg tea.KeyMsg) (DatasetListModel, tea.
 func (m *DatasetListModel) updateSelection() {
 	if !m.showingTables {
 		datasets := m.getFilteredDatasets()
-		if m.cursor < len(datasets) {
-			m.selectedDataset = datasets[m.cursor]
+		if i := m.cursor - len(m.getFilteredFavorites()); i >= 0 && i < len(datasets) {
+			m.selectedDataset = datasets[i]
 		}
 	} else {
 		tables := m.getFilteredTables()
@@ -156,6 +184,9 @@ func (m *DatasetListModel) updateSelection() {
 func (m DatasetListModel) getFilteredItems() []string {
 	if !m.showingTables {
 		var items []string
+		for _, bookmark := range m.getFilteredFavorites() {
+			items = append(items, bookmark.DatasetID+"."+bookmark.TableID)
+		}
 		for _, dataset := range m.getFilteredDatasets() {
 			items = append(items, dataset.ID)
 		}
@@ -169,6 +200,40 @@ func (m DatasetListModel) getFilteredItems() []string {
 	}
 }
 
+// getFilteredFavorites returns the favorites listed at the top of the
+// datasets view
+func (m DatasetListModel) getFilteredFavorites() []config.Bookmark {
+	if m.showingTables {
+		return nil
+	}
+	var filtered []config.Bookmark
+	for _, bookmark := range m.favorites {
+		if strings.Contains(strings.ToLower(bookmark.String()), strings.ToLower(m.filter)) {
+			filtered = append(filtered, bookmark)
+		}
+	}
+	return filtered
+}
+
+// selectedFavorite returns the favorite under the cursor, if any
+func (m DatasetListModel) selectedFavorite() (config.Bookmark, bool) {
+	favorites := m.getFilteredFavorites()
+	if m.cursor < len(favorites) {
+		return favorites[m.cursor], true
+	}
+	return config.Bookmark{}, false
+}
+
+// isStarred reports whether a table of the browsed project is a favorite
+func (m DatasetListModel) isStarred(table *bigquery.Table) bool {
+	for _, bookmark := range m.favorites {
+		if bookmark.ProjectID == m.projectID && bookmark.DatasetID == table.DatasetID && bookmark.TableID == table.ID {
+			return true
+		}
+	}
+	return false
+}
+
 func (m DatasetListModel) getFilteredDatasets() []*bigquery.Dataset {
 	if m.filter == "" {
 		return m.datasets
@@ -183,20 +248,267 @@ func (m DatasetListModel) getFilteredDatasets() []*bigquery.Dataset {
 	return filtered
 }
 
+// getFilteredTables returns the table list entries: date-sharded tables are
+// grouped under one wildcard entry, followed by its shards when expanded. While
+// filtering, groups are listed with their matching shards.
 func (m DatasetListModel) getFilteredTables() []*bigquery.Table {
-	if m.filter == "" {
-		return m.tables
+	filter := strings.ToLower(m.filter)
+	matches := func(table *bigquery.Table) bool {
+		return strings.Contains(strings.ToLower(table.ID), filter)
 	}
 
 	var filtered []*bigquery.Table
-	for _, table := range m.tables {
-		if strings.Contains(strings.ToLower(table.ID), strings.ToLower(m.filter)) {
-			filtered = append(filtered, table)
+	for _, entry := range m.entries {
+		if entry.Shards == nil {
+			if matches(entry) {
+				filtered = append(filtered, entry)
+			}
+			continue
+		}
+
+		expanded := m.expanded[shardGroupKey(entry)]
+		groupMatches := matches(entry)
+		var shards []*bigquery.Table
+		if expanded || filter != "" {
+			for _, shard := range entry.Shards.Tables {
+				if (expanded && groupMatches) || matches(shard) {
+					shards = append(shards, shard)
+				}
+			}
+		}
+		if groupMatches || len(shards) > 0 {
+			filtered = append(filtered, entry)
+			filtered = append(filtered, shards...)
 		}
 	}
 	return filtered
 }
 
+// detailTable returns the table whose schema and preview stand for the
+// selected entry, which is the newest shard for a shard group
+func (m DatasetListModel) detailTable() *bigquery.Table {
+	if m.selectedTable != nil && m.selectedTable.Shards != nil {
+		return m.selectedTable.Shards.Newest()
+	}
+	return m.selectedTable
+}
+
+// shardGroupKey identifies a shard group across table list reloads
+func shardGroupKey(group *bigquery.Table) string {
+	return group.DatasetID + "." + group.ID
+}
+
+// shardGroupOf returns the key of the group a date-sharded table belongs to
+func shardGroupOf(table *bigquery.Table) (string, bool) {
+	prefix, _, _, ok := bigquery.SplitShardID(table.ID)
+	if !ok {
+		return "", false
+	}
+	return table.DatasetID + "." + prefix + "*", true
+}
+
+// nestedShards reports for each entry whether it is a shard listed under its
+// group, in one pass over the entries
+func nestedShards(tables []*bigquery.Table) []bool {
+	nested := make([]bool, len(tables))
+	var group string
+	for i, table := range tables {
+		if table.Shards != nil {
+			group = shardGroupKey(table)
+			continue
+		}
+		key, ok := shardGroupOf(table)
+		if !ok || key != group {
+			group = ""
+			continue
+		}
+		nested[i] = true
+	}
+	return nested
+}
+
+// collapseShards collapses the expanded group under the cursor, or the group
+// of the shard under it, and moves the cursor onto the group
+func (m *DatasetListModel) collapseShards() bool {
+	if !m.showingTables || m.selectedTable == nil {
+		return false
+	}
+	groupKey := shardGroupKey(m.selectedTable)
+	if m.selectedTable.Shards == nil {
+		var ok bool
+		if groupKey, ok = shardGroupOf(m.selectedTable); !ok {
+			return false
+		}
+	}
+	if !m.expanded[groupKey] {
+		return false
+	}
+
+	delete(m.expanded, groupKey)
+	tables := m.getFilteredTables()
+	for i, table := range tables {
+		if shardGroupKey(table) == groupKey {
+			m.cursor = i
+			m.selectedTable = table
+			break
+		}
+	}
+	m.ensureCursorVisible(len(tables))
+	return true
+}
+
+// visibleTables returns the tables currently rendered in the list
+func (m DatasetListModel) visibleTables() []*bigquery.Table {
+	if !m.showingTables {
+		return nil
+	}
+
+	tables := m.getFilteredTables()
+	start := m.viewOffset
+	if start > len(tables) {
+		start = len(tables)
+	}
+	end := start + m.getMaxVisible()
+	if end > len(tables) {
+		end = len(tables)
+	}
+	return tables[start:end]
+}
+
+// selectDatasetByID opens the tables view of a dataset, clearing any filter.
+// With reset the old table list is dropped so it can be reloaded.
+func (m *DatasetListModel) selectDatasetByID(datasetID string, reset bool) bool {
+	for _, dataset := range m.datasets {
+		if dataset.ID != datasetID {
+			continue
+		}
+		m.filter = ""
+		m.selectedDataset = dataset
+		m.showingTables = true
+		if reset {
+			m.cursor = 0
+			m.viewOffset = 0
+			m.selectedTable = nil
+			m.setTables(make([]*bigquery.Table, 0))
+		}
+		return true
+	}
+	return false
+}
+
+// selectTableByID moves the cursor to a listed table and selects it
+func (m *DatasetListModel) selectTableByID(tableID string) bool {
+	// Expand the group of a date shard so it is listed
+	for _, table := range m.tables {
+		if table.ID != tableID {
+			continue
+		}
+		if groupKey, ok := shardGroupOf(table); ok {
+			m.expanded[groupKey] = true
+		}
+		break
+	}
+
+	tables := m.getFilteredTables()
+	for i, table := range tables {
+		if table.ID == tableID {
+			m.cursor = i
+			m.selectedTable = table
+			m.ensureCursorVisible(len(tables))
+			return true
+		}
+	}
+	return false
+}
+
+// replaceDatasets swaps the dataset list while keeping the cursor on the selected dataset
+func (m *DatasetListModel) replaceDatasets(datasets []*bigquery.Dataset) {
+	m.datasets = datasets
+	if m.selectedDataset != nil {
+		for _, dataset := range datasets {
+			if dataset.ID == m.selectedDataset.ID {
+				m.selectedDataset = dataset
+				break
+			}
+		}
+	}
+
+	if !m.showingTables {
+		favorites := len(m.getFilteredFavorites())
+		m.restoreCursor(func(i int) bool {
+			return m.selectedDataset != nil && i >= favorites && m.getFilteredDatasets()[i-favorites].ID == m.selectedDataset.ID
+		}, favorites+len(m.getFilteredDatasets()))
+	}
+}
+
+// replaceTables swaps the table list, keeping details already loaded for
+// unchanged tables and the cursor on the selected table
+func (m *DatasetListModel) replaceTables(tables []*bigquery.Table) {
+	existing := make(map[string]*bigquery.Table, len(m.tables))
+	for _, table := range m.tables {
+		existing[table.ID] = table
+	}
+	for i, table := range tables {
+		if old, ok := existing[table.ID]; ok && old.DetailsLoaded && !table.DetailsLoaded {
+			tables[i] = old
+		}
+	}
+
+	m.setTables(tables)
+	if m.showingTables {
+		entries := m.getFilteredTables()
+		m.restoreCursor(func(i int) bool {
+			return m.selectedTable != nil && entries[i].ID == m.selectedTable.ID
+		}, len(entries))
+		// Shard groups are rebuilt from the new list, so pick up the new shards
+		if m.selectedTable != nil && m.selectedTable.Shards != nil &&
+			m.cursor < len(entries) && entries[m.cursor].ID == m.selectedTable.ID {
+			m.selectedTable = entries[m.cursor]
+		}
+	}
+}
+
+// restoreCursor moves the cursor to the first item matching isSelected, or
+// clamps it to the list when the selected item is gone
+func (m *DatasetListModel) restoreCursor(isSelected func(i int) bool, count int) {
+	for i := 0; i < count; i++ {
+		if isSelected(i) {
+			m.cursor = i
+			m.ensureCursorVisible(count)
+			return
+		}
+	}
+	if m.cursor >= count {
+		m.cursor = max(count-1, 0)
+	}
+	m.ensureCursorVisible(count)
+}
+
+// mergeTableDetails copies lazily fetched details into the listed tables
+func (m *DatasetListModel) mergeTableDetails(details []*bigquery.Table) {
+	byID := make(map[string]*bigquery.Table, len(details))
+	for _, table := range details {
+		byID[table.ID] = table
+	}
+
+	for i, table := range m.tables {
+		if detail, ok := byID[table.ID]; ok {
+			m.tables[i] = detail
+			if m.selectedTable != nil && m.selectedTable.ID == detail.ID {
+				m.selectedTable = detail
+			}
+		}
+	}
+	m.setTables(m.tables)
+}
+
+// setTables replaces the table list and groups its date shards once, rather
+// than on every lookup of the listed entries
+func (m *DatasetListModel) setTables(tables []*bigquery.Table) {
+	m.tables = tables
+	m.entries = bigquery.GroupShards(tables)
+}
+
 func (m *DatasetListModel) getMaxVisible() int {
 	// Calculate actual space used by our UI elements within the content area
 	titleHeight := 2 // Title + blank line after
@@ -216,19 +528,21 @@ func (m *DatasetListModel) getMaxVisible() int {
 	return available
 }
 
-func (m *DatasetListModel) ensureCursorVisible(totalItems int) {
+// visibleItems is the number of items View renders, which getMaxVisible
+// bounds by the pane height
+func (m *DatasetListModel) visibleItems() int {
 	maxVisible := m.getMaxVisible()
-
-	// Apply same bounds checking as in View
 	if maxVisible <= 0 {
-		maxVisible = 5
+		maxVisible = 5 // Conservative fallback
 	}
-	if maxVisible > m.height-4 {
-		maxVisible = m.height - 4
-		if maxVisible < 1 {
-			maxVisible = 1
-		}
+	if maxVisible > m.height-4 { // Conservative height check
+		maxVisible = max(m.height-4, 1)
 	}
+	return maxVisible
+}
+
+func (m *DatasetListModel) ensureCursorVisible(totalItems int) {
+	maxVisible := m.visibleItems()
 
 	// If cursor is above the visible area, scroll up
 	if m.cursor < m.viewOffset {
@@ -295,20 +609,7 @@ func (m DatasetListModel) viewWithLoadingState(loadingDatasets, loadingTables bo
 		return content.String()
 	}
 
-	maxVisible := m.getMaxVisible()
-
-	// Debug: Let's add some bounds checking
-	if maxVisible <= 0 {
-		maxVisible = 5 // Conservative fallback
-	}
-
-	// Ensure we don't try to render more items than can fit
-	if maxVisible > m.height-4 { // Conservative height check
-		maxVisible = m.height - 4
-		if maxVisible < 1 {
-			maxVisible = 1
-		}
-	}
+	maxVisible := m.visibleItems()
 
 	visibleStart := m.viewOffset
 	visibleEnd := visibleStart + maxVisible
@@ -317,6 +618,13 @@ func (m DatasetListModel) viewWithLoadingState(loadingDatasets, loadingTables bo
 		visibleEnd = len(filteredItems)
 	}
 
+	var tables []*bigquery.Table
+	var nested []bool
+	if m.showingTables {
+		tables = m.getFilteredTables()
+		nested = nestedShards(tables)
+	}
+	favorites := m.getFilteredFavorites()
 	itemsRendered := 0
 	for i := visibleStart; i < visibleEnd && itemsRendered < maxVisible; i++ {
 		item := filteredItems[i]
@@ -326,14 +634,39 @@ func (m DatasetListModel) viewWithLoadingState(loadingDatasets, loadingTables bo
 			style = SelectedItemStyle
 		}
 
-		var prefix string
+		var prefix, details string
 		if m.showingTables {
+			table := tables[i]
 			prefix = "  🗂  "
+			switch {
+			case table.Shards != nil && m.expanded[shardGroupKey(table)]:
+				prefix = "▾ 🗃  "
+			case table.Shards != nil:
+				prefix = "▸ 🗃  "
+			case table.DatasetID+"."+table.ID == m.markedTable:
+				prefix = "  📌 "
+			case m.isStarred(table):
+				prefix = "  ★  "
+			}
+			if nested[i] {
+				prefix = "    " + prefix
+			}
+			details = formatTableDetails(table)
+		} else if i < len(favorites) {
+			prefix = "  ★  "
+			// Favorites of other projects say which project they're in
+			if project := favorites[i].ProjectID; project != m.projectID {
+				details = project
+			}
 		} else {
 			prefix = "  📁 "
 		}
 
-		content.WriteString(style.Render(prefix+item) + "\n")
+		line := style.Render(prefix + item)
+		if details != "" {
+			line += SubtleItemStyle.Render("  " + details)
+		}
+		content.WriteString(line + "\n")
 		itemsRendered++
 	}
 
@@ -345,7 +678,10 @@ func (m DatasetListModel) viewWithLoadingState(loadingDatasets, loadingTables bo
 	if m.showingTables {
 		info = fmt.Sprintf("Tables: %d", len(filteredItems))
 	} else {
-		info = fmt.Sprintf("Datasets: %d", len(filteredItems))
+		info = fmt.Sprintf("Datasets: %d", len(m.getFilteredDatasets()))
+		if len(favorites) > 0 {
+			info += fmt.Sprintf(" · Favorites: %d", len(favorites))
+		}
 	}
 
 	content.WriteString("\n" + SubtleItemStyle.Render(info))
@@ -361,3 +697,46 @@ func (m DatasetListModel) viewWithLoadingState(loadingDatasets, loadingTables bo
 
 	return result
 }
+
+// formatTableDetails summarizes a table's type, row count and size for the list
+func formatTableDetails(table *bigquery.Table) string {
+	if table.Shards != nil {
+		first, last := table.Shards.DateRange()
+		return fmt.Sprintf("%d shards · %s → %s", len(table.Shards.Tables), first.Format(time.DateOnly), last.Format(time.DateOnly))
+	}
+	if table.Type == "VIEW" || table.Type == "MATERIALIZED_VIEW" {
+		return strings.ToLower(strings.ReplaceAll(table.Type, "_", " "))
+	}
+	if !table.DetailsLoaded {
+		return ""
+	}
+	return fmt.Sprintf("%s rows · %s", formatCount(table.NumRows), FormatBytes(table.NumBytes))
+}
+
+// formatCount renders a count with a K/M/B suffix
+func formatCount(n uint64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", float64(n)/1_000_000_000)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatBytes renders a byte size using binary units, e.g. "1.5 MiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
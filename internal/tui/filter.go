@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// matchPreviewRows returns the preview rows matching a structured filter,
+// looking up columns by name. It fails on columns the preview doesn't show.
+func (m TableDetailModel) matchPreviewRows(filter *bigquery.Filter) ([][]interface{}, error) {
+	var matched [][]interface{}
+	for _, row := range m.preview.Rows {
+		ok, err := filter.Match(func(column string) (interface{}, bool) {
+			for i, header := range m.preview.Headers {
+				if strings.EqualFold(header, column) && i < len(row) {
+					return row[i], true
+				}
+			}
+			return nil, false
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+	return matched, nil
+}
+
+// describePreviewFilter explains how the active preview filter is applied
+func (m TableDetailModel) describePreviewFilter() string {
+	filter, err := bigquery.ParseFilter(m.previewFilter)
+	if err != nil {
+		return fmt.Sprintf("Filter: %s (press / to edit, esc to clear)", m.previewFilter)
+	}
+	if _, err := m.matchPreviewRows(filter); err != nil {
+		return fmt.Sprintf("Filter: %s • %s; %s filters in BigQuery", m.previewFilter, err, m.keyMap.PushFilter.Help().Key)
+	}
+	return fmt.Sprintf("Filter: %s • %s to fetch matching rows from BigQuery", m.previewFilter, m.keyMap.PushFilter.Help().Key)
+}
+
+// pushPreviewFilter re-fetches the preview with the structured filter as a
+// WHERE clause, so it finds matches beyond the rows loaded so far. Without a
+// filter it drops a WHERE clause pushed earlier.
+func (m TableDetailModel) pushPreviewFilter() (TableDetailModel, tea.Cmd) {
+	if m.preview == nil {
+		return m, nil
+	}
+	if m.previewFilter == "" {
+		if m.preview.Where == "" {
+			return m, nil
+		}
+		return m, func() tea.Msg { return FilterPreviewMsg{} }
+	}
+	filter, err := bigquery.ParseFilter(m.previewFilter)
+	if err != nil {
+		return m, func() tea.Msg {
+			return ErrorMsg{Error: fmt.Errorf("only structured filters can run in BigQuery: %w", err)}
+		}
+	}
+	return m, func() tea.Msg { return FilterPreviewMsg{Where: filter.SQL()} }
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"bqui/internal/bigquery"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cacheState tracks whether the data shown in a view came from cache
+type cacheState struct {
+	cachedAt   time.Time
+	refreshing bool
+}
+
+// String renders the status bar indicator, or "" for live data
+func (s cacheState) String() string {
+	if s.cachedAt.IsZero() {
+		return ""
+	}
+	text := "cached " + formatAge(time.Since(s.cachedAt))
+	if s.refreshing {
+		text += ", refreshing..."
+	}
+	return text
+}
+
+// formatAge renders a duration as a human-friendly "N units ago"
+func formatAge(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	default:
+		return plural(int(d.Hours()/24), "day")
+	}
+}
+
+// currentCacheState returns the freshness of the data in the focused view
+func (m Model) currentCacheState() cacheState {
+	if m.focus == FocusTableDetail {
+		if m.tableDetail.activeTab == SchemaTab {
+			return m.schemaCache
+		}
+		return cacheState{}
+	}
+	if m.datasetList.showingTables {
+		return m.tablesCache
+	}
+	return m.datasetsCache
+}
+
+// needsRevalidation reports whether data served from cache should be refreshed
+func (m Model) needsRevalidation(cachedAt time.Time) bool {
+	return !m.offline && !cachedAt.IsZero() && m.cache != nil && m.cache.IsStale(cachedAt)
+}
+
+// applyRevalidatedDatasets swaps in freshly fetched datasets if they differ from
+// the cached ones on screen, keeping the cursor on the same dataset
+func (m Model) applyRevalidatedDatasets(msg DatasetsLoadedMsg) (tea.Model, tea.Cmd) {
+	m.datasetsCache = cacheState{}
+	if sameDatasets(m.datasetList.datasets, msg.Datasets) {
+		return m, nil
+	}
+
+	m.datasetList.replaceDatasets(msg.Datasets)
+	m.statusMessage = fmt.Sprintf("Datasets changed since last visit, refreshed (%d datasets)", len(msg.Datasets))
+	return m, nil
+}
+
+// applyRevalidatedTables swaps in a freshly listed table set if it differs from
+// the cached one on screen, keeping already loaded details
+func (m Model) applyRevalidatedTables(msg TablesLoadedMsg) (tea.Model, tea.Cmd) {
+	m.tablesCache = cacheState{}
+	if sameTables(m.datasetList.tables, msg.Tables) {
+		return m, nil
+	}
+
+	m.datasetList.replaceTables(msg.Tables)
+	m.statusMessage = fmt.Sprintf("Tables in %s changed since last visit, refreshed (%d tables)", msg.DatasetID, len(msg.Tables))
+	return m, m.loadVisibleTableDetails()
+}
+
+// applyRevalidatedSchema swaps in a freshly fetched schema if it differs from
+// the cached one on screen
+func (m Model) applyRevalidatedSchema(msg TableSchemaLoadedMsg) (tea.Model, tea.Cmd) {
+	m.schemaCache = cacheState{}
+	if reflect.DeepEqual(m.tableDetail.schema, msg.Schema) {
+		return m, nil
+	}
+
+	m.tableDetail.schema = msg.Schema
+	if fields := m.tableDetail.getFilteredSchemaFields(); m.tableDetail.schemaRowCursor >= len(fields) {
+		m.tableDetail.schemaRowCursor = max(len(fields)-1, 0)
+	}
+	m.statusMessage = fmt.Sprintf("Schema of %s.%s changed since last visit, refreshed", msg.DatasetID, msg.TableID)
+	return m, nil
+}
+
+func sameDatasets(a, b []*bigquery.Dataset) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Location != b[i].Location || a[i].Description != b[i].Description {
+			return false
+		}
+	}
+	return true
+}
+
+func sameTables(a, b []*bigquery.Table) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
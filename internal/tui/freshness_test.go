@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bqui/internal/bigquery"
+	"bqui/internal/cache"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{50 * time.Hour, "2 days ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestStaleTablesAreShownThenRevalidated(t *testing.T) {
+	c, err := cache.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+	c.SetTTL(time.Hour)
+
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Cache: c})
+	m.datasetList.datasets = []*bigquery.Dataset{{ID: "ds"}}
+	m.datasetList.selectDatasetByID("ds", true)
+	m.datasetList.height = 20
+	m.startLoadingTables()
+
+	// A fresh cache entry is shown as is
+	fresh := []*bigquery.Table{{ID: "a", DatasetID: "ds", NumRows: 1, DetailsLoaded: true}}
+	model, _ := m.Update(TablesLoadedMsg{DatasetID: "ds", Tables: fresh, CachedAt: time.Now()})
+	if got := model.(Model).tablesCache; got.refreshing || got.String() != "cached just now" {
+		t.Errorf("Expected a fresh entry not to be refreshed, got %q", got.String())
+	}
+
+	// A stale one is shown at once while it's refreshed in the background
+	cached := []*bigquery.Table{
+		{ID: "a", DatasetID: "ds", NumRows: 1, DetailsLoaded: true},
+		{ID: "b", DatasetID: "ds", NumRows: 2, DetailsLoaded: true},
+	}
+	model, cmd := m.Update(TablesLoadedMsg{DatasetID: "ds", Tables: cached, CachedAt: time.Now().Add(-2 * time.Hour)})
+	m = model.(Model)
+	if cmd == nil || len(m.datasetList.tables) != 2 || m.loadingTables {
+		t.Fatalf("Expected the cached tables to be shown while refreshing, got %d tables", len(m.datasetList.tables))
+	}
+	if got := m.currentCacheState().String(); got != "cached 2 hours ago, refreshing..." {
+		t.Errorf("Unexpected cache indicator %q", got)
+	}
+
+	// An unchanged listing only clears the indicator
+	model, _ = m.Update(TablesLoadedMsg{DatasetID: "ds", Tables: []*bigquery.Table{{ID: "a"}, {ID: "b"}}, Revalidated: true})
+	if unchanged := model.(Model); unchanged.currentCacheState().String() != "" || strings.Contains(unchanged.statusMessage, "changed") {
+		t.Errorf("Expected no change to be reported, got %q", unchanged.statusMessage)
+	}
+
+	// A changed one is applied in place, keeping the cursor and loaded details
+	m.datasetList.cursor = 1
+	m.datasetList.selectedTable = m.datasetList.tables[1]
+	model, _ = m.Update(TablesLoadedMsg{
+		DatasetID:   "ds",
+		Tables:      []*bigquery.Table{{ID: "0new", DatasetID: "ds"}, {ID: "a", DatasetID: "ds"}, {ID: "b", DatasetID: "ds"}},
+		Revalidated: true,
+	})
+	m = model.(Model)
+	if len(m.datasetList.tables) != 3 || !strings.Contains(m.statusMessage, "changed since last visit") {
+		t.Fatalf("Expected the new table to be shown, got %d tables and status %q", len(m.datasetList.tables), m.statusMessage)
+	}
+	if m.datasetList.selectedTable == nil || m.datasetList.selectedTable.ID != "b" || m.datasetList.cursor != 2 {
+		t.Errorf("Expected the cursor to stay on b, got %d", m.datasetList.cursor)
+	}
+	if m.datasetList.tables[2].NumRows != 2 {
+		t.Error("Expected already loaded details to be kept")
+	}
+}
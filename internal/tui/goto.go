@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openGoTo shows the prompt for a table name or console URL to open
+func (m Model) openGoTo() (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = "project.dataset.table, project:dataset.table or a console URL"
+	input.Width = max(m.width-16, 20)
+	m.goTo = input
+	m.goToReturnFocus = m.focus
+	m.focus = FocusGoTo
+	m.goTo.Focus() // No blink command: a steady cursor is enough here
+	return m, nil
+}
+
+// handleGoToInput edits the go-to prompt; Enter opens the table it names
+func (m Model) handleGoToInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Escape):
+		m.focus = m.goToReturnFocus
+		return m, nil
+
+	case key.Matches(msg, m.keyMap.Enter):
+		ref, err := bigquery.ParseTableReference(m.goTo.Value())
+		if err != nil {
+			// Keep the prompt open so the name can be fixed
+			m.statusMessage = err.Error()
+			return m, nil
+		}
+		m.focus = m.goToReturnFocus
+		return m.jumpTo(jumpTarget{projectID: ref.ProjectID, datasetID: ref.DatasetID, tableID: ref.TableID})
+	}
+
+	var cmd tea.Cmd
+	m.goTo, cmd = m.goTo.Update(msg)
+	return m, cmd
+}
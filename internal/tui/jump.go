@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jumpTarget is a dataset, table or column to navigate to. An empty
+// projectID means the current project.
+type jumpTarget struct {
+	projectID string
+	datasetID string
+	tableID   string
+	column    string
+}
+
+// jumpTo opens the target's dataset and, once its tables are listed, selects
+// the target table and column. A target in another project switches to it
+// first.
+func (m Model) jumpTo(target jumpTarget) (tea.Model, tea.Cmd) {
+	if target.projectID != "" && target.projectID != m.currentProjectID() {
+		m.jumpOnDatasets = &target
+		m.statusMessage = fmt.Sprintf("Switching to project %s...", target.projectID)
+		return m, m.switchProject(target.projectID)
+	}
+
+	alreadyListed := m.datasetList.showingTables &&
+		m.datasetList.selectedDataset != nil &&
+		m.datasetList.selectedDataset.ID == target.datasetID &&
+		!m.loadingTables
+
+	if !m.datasetList.selectDatasetByID(target.datasetID, !alreadyListed) {
+		m.statusMessage = fmt.Sprintf("Dataset %s not found", target.datasetID)
+		return m, nil
+	}
+	m.focus = FocusDatasetList
+
+	m.pendingJump = nil
+	if target.tableID != "" {
+		m.pendingJump = &target
+	}
+
+	if alreadyListed {
+		return m, m.resolvePendingJump(true)
+	}
+
+	m.lastSelectedDatasetID = target.datasetID
+	m.lastSelectedTableID = ""
+	m.tableDetail.schema = nil
+	m.tableDetail.preview = nil
+	m.tableDetail.currentTableName = ""
+	loadCmd := m.startLoadingTables()
+	return m, loadCmd
+}
+
+// resolvePendingJump selects the pending jump's table if it has been listed.
+// final reports whether the listing is complete, so a missing table is an error.
+func (m *Model) resolvePendingJump(final bool) tea.Cmd {
+	target := m.pendingJump
+	if target == nil || m.datasetList.selectedDataset == nil || m.datasetList.selectedDataset.ID != target.datasetID {
+		return nil
+	}
+
+	if !m.datasetList.selectTableByID(target.tableID) {
+		if final {
+			m.pendingJump = nil
+			m.statusMessage = fmt.Sprintf("Table %s not found in %s", target.tableID, target.datasetID)
+		}
+		return nil
+	}
+
+	m.pendingJump = nil
+	m.pendingColumn = target.column
+	m.lastSelectedTableID = fmt.Sprintf("%s.%s", target.datasetID, target.tableID)
+	m.focus = FocusTableDetail
+	if target.column != "" {
+		m.tableDetail.activeTab = SchemaTab
+	}
+	m.loadingSchema = true
+	m.loadingPreview = true
+	return tea.Batch(m.loadTableSchema(), m.loadTablePreview(), m.loadVisibleTableDetails())
+}
+
+// focusPendingColumn moves the schema cursor to the column a jump asked for
+func (m *Model) focusPendingColumn() {
+	if m.pendingColumn == "" {
+		return
+	}
+	if !m.tableDetail.focusColumn(m.pendingColumn) {
+		m.statusMessage = fmt.Sprintf("Column %s not found", m.pendingColumn)
+	}
+	m.pendingColumn = ""
+}
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"bqui/internal/bigquery"
+)
+
+func TestStartupTableOpensOnceDatasetsLoad(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{
+		KeyMap:    DefaultKeyMap(),
+		Offline:   true,
+		ProjectID: "proj",
+		Table:     &bigquery.TableReference{DatasetID: "sales", TableID: "orders"},
+		Query:     "SELECT 1",
+	})
+	if m.focus != FocusTableDetail || m.tableDetail.activeTab != ResultsTab || m.startupQuery == nil {
+		t.Fatal("Expected the startup query to run into the Results tab")
+	}
+
+	updated, _ := m.Update(DatasetsLoadedMsg{ProjectID: "proj", Datasets: []*bigquery.Dataset{{ID: "logs"}, {ID: "sales"}}})
+	m = updated.(Model)
+	if m.jumpOnDatasets != nil || m.pendingJump == nil || m.pendingJump.tableID != "orders" {
+		t.Fatalf("Expected a pending jump to the table, got %+v", m.pendingJump)
+	}
+	if !m.datasetList.showingTables || m.datasetList.selectedDataset.ID != "sales" {
+		t.Fatalf("Expected the table's dataset to be opened, got %+v", m.datasetList.selectedDataset)
+	}
+
+	// A jump into another project waits for its datasets
+	updated, cmd := m.jumpTo(jumpTarget{projectID: "other", datasetID: "web", tableID: "events"})
+	m = updated.(Model)
+	if cmd == nil || m.jumpOnDatasets == nil || m.jumpOnDatasets.projectID != "other" {
+		t.Fatalf("Expected a project switch before the jump, got %+v", m.jumpOnDatasets)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.currentProjectID() != "other" || m.jumpOnDatasets == nil {
+		t.Fatalf("Expected the jump to survive the switch to %s", m.currentProjectID())
+	}
+}
@@ -0,0 +1,343 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type KeyMap struct {
+	Up             key.Binding
+	Down           key.Binding
+	Left           key.Binding
+	Right          key.Binding
+	Enter          key.Binding
+	Tab            key.Binding
+	ShiftTab       key.Binding
+	Search         key.Binding
+	Palette        key.Binding
+	GoTo           key.Binding
+	Copy           key.Binding
+	CopyAlt        key.Binding
+	Visual         key.Binding
+	Mark           key.Binding
+	Compare        key.Binding
+	Export         key.Binding
+	SaveToFile     key.Binding
+	Profile        key.Binding
+	ProfileAll     key.Binding
+	Partitions     key.Binding
+	SelectRange    key.Binding
+	QueryPartition key.Binding
+	Record         key.Binding
+	Bookmark       key.Binding
+	SaveResults    key.Binding
+	Sort           key.Binding
+	SortAdd        key.Binding
+	ServerSort     key.Binding
+	PushFilter     key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	VimTop         key.Binding
+	VimBottom      key.Binding
+	PageUp         key.Binding
+	PageDown       key.Binding
+	LineStart      key.Binding
+	LineEnd        key.Binding
+	ProjectList    key.Binding
+	Refresh        key.Binding
+	Escape         key.Binding
+	Back           key.Binding
+	Quit           key.Binding
+	Help           key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "move up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "move down"),
+		),
+		Left: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "move left / scroll left"),
+		),
+		Right: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "move right / scroll right"),
+		),
+		Enter: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "select dataset/table"),
+		),
+		Tab: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next tab"),
+		),
+		ShiftTab: key.NewBinding(
+			key.WithKeys("shift+tab"),
+			key.WithHelp("shift+tab", "previous tab"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search/filter datasets, columns or rows"),
+		),
+		Palette: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "search all datasets, tables and columns"),
+		),
+		GoTo: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "go to a table by name or console URL"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy table name, cell or selection"),
+		),
+		CopyAlt: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "copy table name, cell or selection"),
+		),
+		Visual: key.NewBinding(
+			key.WithKeys("V", "shift+v"),
+			key.WithHelp("V", "toggle visual row selection"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark table for schema diff"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("="),
+			key.WithHelp("=", "diff schema against marked table"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export schema (JSON, DDL, Go, Protobuf, Python)"),
+		),
+		SaveToFile: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "write exported schema to a file"),
+		),
+		Profile: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "profile selected column (whole table outside the Schema tab)"),
+		),
+		ProfileAll: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "profile every column of the table"),
+		),
+		Partitions: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "browse partitions of the table"),
+		),
+		SelectRange: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "start or clear a shard range"),
+		),
+		QueryPartition: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "query the selected partition or shards"),
+		),
+		Record: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open the row as a vertical record"),
+		),
+		Bookmark: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "star/unstar table (favorites)"),
+		),
+		SaveResults: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "save query results as a table"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort rows by column (asc, desc, off)"),
+		),
+		SortAdd: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "add column as another sort key"),
+		),
+		ServerSort: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "re-run the sort in BigQuery (ORDER BY)"),
+		),
+		PushFilter: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "re-fetch preview rows matching the filter (WHERE)"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("home"),
+			key.WithHelp("home", "go to top"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("end"),
+			key.WithHelp("end", "go to bottom"),
+		),
+		VimTop: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "go to top"),
+		),
+		VimBottom: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "go to bottom"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdown", "page down"),
+		),
+		LineStart: key.NewBinding(
+			key.WithKeys("0"),
+			key.WithHelp("0", "go to first column"),
+		),
+		LineEnd: key.NewBinding(
+			key.WithKeys("$"),
+			key.WithHelp("$", "go to last column"),
+		),
+		ProjectList: key.NewBinding(
+			key.WithKeys("ctrl+space", "alt+p"),
+			key.WithHelp("ctrl+space/alt+p", "project selector"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r", "ctrl+r"),
+			key.WithHelp("r/ctrl+r", "refresh/clear cache"),
+		),
+		Escape: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back/cancel/clear"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "back to left pane"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q/ctrl+c", "quit"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "show/hide help"),
+		),
+	}
+}
+
+// actions maps the config file action names to their bindings
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":              &k.Up,
+		"down":            &k.Down,
+		"left":            &k.Left,
+		"right":           &k.Right,
+		"enter":           &k.Enter,
+		"tab":             &k.Tab,
+		"shift_tab":       &k.ShiftTab,
+		"search":          &k.Search,
+		"palette":         &k.Palette,
+		"go_to":           &k.GoTo,
+		"copy":            &k.Copy,
+		"copy_alt":        &k.CopyAlt,
+		"visual":          &k.Visual,
+		"mark":            &k.Mark,
+		"compare":         &k.Compare,
+		"export":          &k.Export,
+		"save_to_file":    &k.SaveToFile,
+		"profile":         &k.Profile,
+		"profile_all":     &k.ProfileAll,
+		"partitions":      &k.Partitions,
+		"select_range":    &k.SelectRange,
+		"query_partition": &k.QueryPartition,
+		"record":          &k.Record,
+		"bookmark":        &k.Bookmark,
+		"save_results":    &k.SaveResults,
+		"sort":            &k.Sort,
+		"sort_add":        &k.SortAdd,
+		"server_sort":     &k.ServerSort,
+		"push_filter":     &k.PushFilter,
+		"top":             &k.Top,
+		"bottom":          &k.Bottom,
+		"vim_top":         &k.VimTop,
+		"vim_bottom":      &k.VimBottom,
+		"page_up":         &k.PageUp,
+		"page_down":       &k.PageDown,
+		"line_start":      &k.LineStart,
+		"line_end":        &k.LineEnd,
+		"project_list":    &k.ProjectList,
+		"refresh":         &k.Refresh,
+		"escape":          &k.Escape,
+		"back":            &k.Back,
+		"quit":            &k.Quit,
+		"help":            &k.Help,
+	}
+}
+
+// NewKeyMap returns the default key map with the given per-action overrides applied
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	keyMap := DefaultKeyMap()
+	actions := keyMap.actions()
+
+	for action, keys := range overrides {
+		binding, ok := actions[action]
+		if !ok {
+			return keyMap, fmt.Errorf("unknown key binding action %q (valid actions: %s)", action, strings.Join(ActionNames(), ", "))
+		}
+		if len(keys) == 0 {
+			binding.SetEnabled(false)
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+
+	return keyMap, nil
+}
+
+// ActionNames returns the sorted list of action names accepted in the config file
+func ActionNames() []string {
+	keyMap := DefaultKeyMap()
+	var names []string
+	for name := range keyMap.actions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Enter, k.Tab, k.ShiftTab, k.Search, k.Copy, k.Quit, k.Help}
+}
+
+func (k KeyMap) FullHelp() [][]key.Binding {
+	var groups [][]key.Binding
+	for _, section := range k.HelpSections() {
+		groups = append(groups, section.Bindings)
+	}
+	return groups
+}
+
+// HelpSection is a titled group of bindings shown on the help screen
+type HelpSection struct {
+	Title    string
+	Bindings []key.Binding
+}
+
+// HelpSections groups the bindings for the help screen
+func (k KeyMap) HelpSections() []HelpSection {
+	return []HelpSection{
+		{Title: "Navigation", Bindings: []key.Binding{k.Up, k.Down, k.Left, k.Right, k.Enter, k.Escape, k.Back}},
+		{Title: "Tabs (Right Pane)", Bindings: []key.Binding{k.Tab, k.ShiftTab}},
+		{Title: "Search & Filter", Bindings: []key.Binding{k.Search, k.Palette, k.GoTo}},
+		{Title: "Actions", Bindings: []key.Binding{k.Copy, k.CopyAlt, k.Visual, k.Mark, k.Compare, k.Export, k.SaveToFile, k.Profile, k.ProfileAll, k.Partitions, k.SelectRange, k.QueryPartition, k.Record, k.Bookmark, k.SaveResults, k.Sort, k.SortAdd, k.ServerSort, k.PushFilter, k.ProjectList, k.Refresh}},
+		{Title: "Vim Shortcuts", Bindings: []key.Binding{k.VimTop, k.Top, k.VimBottom, k.Bottom, k.PageUp, k.PageDown, k.LineStart, k.LineEnd}},
+		{Title: "Other", Bindings: []key.Binding{k.Help, k.Quit}},
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"testing"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewKeyMapOverrides(t *testing.T) {
+	keyMap, err := NewKeyMap(map[string][]string{
+		"visual": {"v"},
+		"quit":   {"ctrl+q"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	visual := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}}
+	if !key.Matches(visual, keyMap.Visual) {
+		t.Error("Expected 'v' to match remapped visual binding")
+	}
+
+	oldVisual := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'V'}}
+	if key.Matches(oldVisual, keyMap.Visual) {
+		t.Error("Expected 'V' to no longer match visual binding")
+	}
+
+	if keyMap.Quit.Help().Key != "ctrl+q" {
+		t.Errorf("Expected help key 'ctrl+q', got '%s'", keyMap.Quit.Help().Key)
+	}
+
+	// Untouched bindings keep their defaults
+	if keyMap.Up.Help().Key != DefaultKeyMap().Up.Help().Key {
+		t.Errorf("Expected default up binding, got '%s'", keyMap.Up.Help().Key)
+	}
+}
+
+func TestNewKeyMapUnknownAction(t *testing.T) {
+	if _, err := NewKeyMap(map[string][]string{"teleport": {"t"}}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
+
+func TestPartitionsUseKeyMapBindings(t *testing.T) {
+	m := NewPartitionsModel(DefaultKeyMap(), tableRef{projectID: "p", datasetID: "d", tableID: "events_*"}, "events_", 30, FocusDatasetList)
+	m.loading = false
+	m.partitions = []*bigquery.Partition{{ID: "20240101"}, {ID: "20240102"}}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// s is the sort binding and no longer queries from the browser
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}); cmd != nil {
+		t.Error("Expected 's' to be ignored by the partition browser")
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if cmd == nil {
+		t.Fatal("Expected the query binding to select the shard range")
+	}
+	selected, ok := cmd().(PartitionSelectedMsg)
+	if !ok || !selected.Query || selected.PartitionID != "20240102" || selected.EndPartitionID != "20240101" {
+		t.Errorf("Unexpected selection: %+v", selected)
+	}
+}
@@ -1,26 +1,68 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"time"
 
 	"bqui/internal/bigquery"
+	"bqui/internal/config"
+	"bqui/pkg/clipboard"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type DatasetsLoadedMsg struct {
-	Datasets []*bigquery.Dataset
+	ProjectID string
+	Datasets  []*bigquery.Dataset
+	// Warning reports datasets whose metadata could not be fetched
+	Warning error
+	// CachedAt is set when the datasets were served from cache
+	CachedAt time.Time
+	// Revalidated marks a background refresh of data already on screen
+	Revalidated bool
 }
 
 type TablesLoadedMsg struct {
+	DatasetID   string
+	Tables      []*bigquery.Table
+	CachedAt    time.Time
+	Revalidated bool
+}
+
+// TablesPageMsg delivers one page of a streamed table listing
+type TablesPageMsg struct {
+	DatasetID     string
+	LoadID        int
+	Tables        []*bigquery.Table
+	NextPageToken string
+}
+
+// TableDetailsLoadedMsg delivers lazily fetched row counts and sizes
+type TableDetailsLoadedMsg struct {
 	DatasetID string
+	// Requested lists every table asked for; those missing from Tables failed
+	Requested []string
 	Tables    []*bigquery.Table
+	Warning   error
 }
 
 type TableSchemaLoadedMsg struct {
-	DatasetID string
-	TableID   string
-	Schema    *bigquery.TableSchema
+	DatasetID   string
+	TableID     string
+	Schema      *bigquery.TableSchema
+	CachedAt    time.Time
+	Revalidated bool
+}
+
+// SchemaDiffLoadedMsg delivers the differences between two tables' schemas
+type SchemaDiffLoadedMsg struct {
+	From  tableRef
+	To    tableRef
+	Diffs []bigquery.FieldDiff
+	Err   error
 }
 
 type TablePreviewLoadedMsg struct {
@@ -47,6 +89,72 @@ type ErrorMsg struct {
 
 type CopySuccessMsg struct {
 	Text string
+	// OSC52 is the escape sequence that sets the terminal's clipboard, when
+	// the text didn't go to the system clipboard
+	OSC52 string
+}
+
+// ProfileLoadedMsg delivers the profile of a table or one of its columns
+type ProfileLoadedMsg struct {
+	Table   tableRef
+	Column  string
+	Profile *bigquery.TableProfile
+	Err     error
+}
+
+// PartitionsLoadedMsg delivers the partitioning and partitions of a table
+type PartitionsLoadedMsg struct {
+	Table        tableRef
+	Partitioning *bigquery.Partitioning
+	Partitions   []*bigquery.Partition
+	Err          error
+}
+
+// PartitionSelectedMsg asks to preview a partition, or to query it when Query
+// is set. Shards are queried from PartitionID to EndPartitionID when a range
+// was selected.
+type PartitionSelectedMsg struct {
+	Table          tableRef
+	PartitionID    string
+	EndPartitionID string
+	Query          bool
+}
+
+// SortPreviewMsg asks to reload the preview sorted in BigQuery
+type SortPreviewMsg struct {
+	OrderBy []bigquery.OrderBy
+}
+
+// CopyRowsMsg asks to copy the visual selection in a format
+type CopyRowsMsg struct {
+	Format bigquery.RowFormat
+}
+
+// FilterPreviewMsg asks to reload the preview with a WHERE condition
+type FilterPreviewMsg struct {
+	Where string
+}
+
+// ExportSchemaMsg asks for the current table's schema in the given format
+type ExportSchemaMsg struct {
+	Format bigquery.SchemaFormat
+	ToFile bool
+}
+
+// FileSavedMsg reports a file written to disk
+type FileSavedMsg struct {
+	Path string
+}
+
+// ResultsSavedMsg reports query results written to a table
+type ResultsSavedMsg struct {
+	Table string
+}
+
+// SaveResultsRejectedMsg reports a save that the form can fix, such as a
+// partitioning the destination table can't be replaced with
+type SaveResultsRejectedMsg struct {
+	Error error
 }
 
 type ProjectSwitchedMsg struct {
@@ -57,19 +165,57 @@ type ProjectSelectedMsg struct {
 	Project *bigquery.Project
 }
 
+// PaletteSelectedMsg asks to jump to a dataset, table or column
+type PaletteSelectedMsg struct {
+	DatasetID string
+	TableID   string
+	Column    string
+}
+
+// BookmarkSelectedMsg asks to open a favorite, which may be in another project
+type BookmarkSelectedMsg struct {
+	Bookmark config.Bookmark
+}
+
+// DatasetIndexedMsg delivers the search palette index of one dataset
+type DatasetIndexedMsg struct {
+	ProjectID string
+	DatasetID string
+	Columns   []*bigquery.ColumnInfo
+	// Tables is the fallback index when no column index is available
+	Tables []*bigquery.Table
+	Err    error
+}
+
 func (m Model) loadDatasets() tea.Cmd {
 	return func() tea.Msg {
-		projectID := m.bqClient.GetProjectID()
+		projectID := m.currentProjectID()
 
-		// Try cache first if available
+		// Serve from cache if available; stale entries are revalidated once shown
 		if m.cache != nil {
-			if cachedDatasets, found := m.cache.GetDatasets(projectID); found {
-				return DatasetsLoadedMsg{Datasets: cachedDatasets}
+			if cachedDatasets, cachedAt, found := m.cache.GetDatasets(projectID); found {
+				return DatasetsLoadedMsg{ProjectID: projectID, Datasets: cachedDatasets, CachedAt: cachedAt}
 			}
 		}
+		if m.offline {
+			return DatasetsLoadedMsg{ProjectID: projectID}
+		}
+
+		return m.fetchDatasets(false)()
+	}
+}
+
+// fetchDatasets loads datasets from BigQuery and refreshes the cache
+func (m Model) fetchDatasets(revalidate bool) tea.Cmd {
+	return func() tea.Msg {
+		projectID := m.currentProjectID()
 
-		// Load from BigQuery
 		datasets, err := m.bqClient.ListDatasets()
+		var partial *bigquery.PartialError
+		if errors.As(err, &partial) {
+			// Show what we have but don't cache it, so the failed items are retried next time
+			return DatasetsLoadedMsg{ProjectID: projectID, Datasets: datasets, Warning: partial, Revalidated: revalidate}
+		}
 		if err != nil {
 			return ErrorMsg{Error: fmt.Errorf("failed to load datasets: %w", err)}
 		}
@@ -79,38 +225,132 @@ func (m Model) loadDatasets() tea.Cmd {
 			_ = m.cache.SetDatasets(projectID, datasets) // Continue even if caching fails
 		}
 
-		return DatasetsLoadedMsg{Datasets: datasets}
+		return DatasetsLoadedMsg{ProjectID: projectID, Datasets: datasets, Revalidated: revalidate}
 	}
 }
 
+// tablesPageSize is the number of tables requested per list call
+const tablesPageSize = 1000
+
+// startLoadingTables begins a new table listing for the selected dataset,
+// superseding any listing still streaming in
+func (m *Model) startLoadingTables() tea.Cmd {
+	m.tablesLoadID++
+	m.loadingTables = true
+	m.tablesCache = cacheState{}
+	m.detailsRequested = make(map[string]bool)
+	return m.loadTables()
+}
+
 func (m Model) loadTables() tea.Cmd {
 	if m.datasetList.selectedDataset == nil {
 		return nil
 	}
 
 	datasetID := m.datasetList.selectedDataset.ID
+	loadID := m.tablesLoadID
 	return func() tea.Msg {
-		projectID := m.bqClient.GetProjectID()
+		projectID := m.currentProjectID()
 
-		// Try cache first if available
+		// Serve from cache if available; stale entries are revalidated once shown
 		if m.cache != nil {
-			if cachedTables, found := m.cache.GetTables(projectID, datasetID); found {
-				return TablesLoadedMsg{DatasetID: datasetID, Tables: cachedTables}
+			if cachedTables, cachedAt, found := m.cache.GetTables(projectID, datasetID); found {
+				return TablesLoadedMsg{DatasetID: datasetID, Tables: cachedTables, CachedAt: cachedAt}
 			}
 		}
+		if m.offline {
+			return TablesLoadedMsg{DatasetID: datasetID}
+		}
 
-		// Load from BigQuery
-		tables, err := m.bqClient.ListTables(datasetID)
+		// Stream from BigQuery page by page
+		return m.fetchTablesPage(datasetID, loadID, "")()
+	}
+}
+
+func (m Model) fetchTablesPage(datasetID string, loadID int, pageToken string) tea.Cmd {
+	return func() tea.Msg {
+		page, err := m.bqClient.ListTablesPage(datasetID, pageToken, tablesPageSize)
 		if err != nil {
 			return ErrorMsg{Error: fmt.Errorf("failed to load tables for dataset %s: %w", datasetID, err)}
 		}
+		return TablesPageMsg{
+			DatasetID:     datasetID,
+			LoadID:        loadID,
+			Tables:        page.Tables,
+			NextPageToken: page.NextPageToken,
+		}
+	}
+}
+
+// revalidateTables re-lists all tables of a dataset in the background and
+// refreshes the cache. Only list-call fields are fetched; details stay lazy.
+func (m Model) revalidateTables(datasetID string) tea.Cmd {
+	return func() tea.Msg {
+		projectID := m.currentProjectID()
+
+		var tables []*bigquery.Table
+		pageToken := ""
+		for {
+			page, err := m.bqClient.ListTablesPage(datasetID, pageToken, tablesPageSize)
+			if err != nil {
+				return ErrorMsg{Error: fmt.Errorf("failed to refresh tables for dataset %s: %w", datasetID, err)}
+			}
+			tables = append(tables, page.Tables...)
+			if page.NextPageToken == "" {
+				break
+			}
+			pageToken = page.NextPageToken
+		}
 
-		// Cache the result if cache is available
 		if m.cache != nil {
 			_ = m.cache.SetTables(projectID, datasetID, tables) // Continue even if caching fails
 		}
 
-		return TablesLoadedMsg{DatasetID: datasetID, Tables: tables}
+		return TablesLoadedMsg{DatasetID: datasetID, Tables: tables, Revalidated: true}
+	}
+}
+
+// cacheTables stores the listed tables of a dataset, with whatever details
+// have been merged so far. The list is copied, since merging details writes
+// into it while the cache encodes it in the background.
+func (m Model) cacheTables(datasetID string) tea.Cmd {
+	if m.cache == nil {
+		return nil
+	}
+	projectID := m.currentProjectID()
+	tables := slices.Clone(m.datasetList.tables)
+	return func() tea.Msg {
+		_ = m.cache.SetTables(projectID, datasetID, tables) // Continue even if caching fails
+		return nil
+	}
+}
+
+// loadVisibleTableDetails fetches row counts and sizes for the tables currently
+// on screen that only have list-call fields
+func (m *Model) loadVisibleTableDetails() tea.Cmd {
+	if m.offline || !m.datasetList.showingTables || m.datasetList.selectedDataset == nil {
+		return nil
+	}
+	if m.detailsRequested == nil {
+		m.detailsRequested = make(map[string]bool)
+	}
+
+	datasetID := m.datasetList.selectedDataset.ID
+	var tableIDs []string
+	for _, table := range m.datasetList.visibleTables() {
+		if table.DetailsLoaded || m.detailsRequested[table.ID] {
+			continue
+		}
+		m.detailsRequested[table.ID] = true
+		tableIDs = append(tableIDs, table.ID)
+	}
+	if len(tableIDs) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		tables, err := m.bqClient.GetTableDetails(datasetID, tableIDs)
+		return TableDetailsLoadedMsg{DatasetID: datasetID, Requested: tableIDs, Tables: tables, Warning: err}
 	}
 }
 
@@ -119,48 +359,197 @@ func (m Model) loadTableSchema() tea.Cmd {
 		return nil
 	}
 
-	table := m.datasetList.selectedTable
+	table := m.datasetList.detailTable()
 	return func() tea.Msg {
-		projectID := m.bqClient.GetProjectID()
+		projectID := m.currentProjectID()
 
-		// Try cache first if available
+		// Serve from cache if the table hasn't changed since the schema was cached.
+		// If that can't be checked, show the cached schema and revalidate it by age.
 		if m.cache != nil {
-			if cachedSchema, found := m.cache.GetSchema(projectID, table.DatasetID, table.ID); found {
-				return TableSchemaLoadedMsg{
+			if cachedSchema, cachedAt, found := m.cache.GetSchema(projectID, table.DatasetID, table.ID); found {
+				cached := TableSchemaLoadedMsg{
 					DatasetID: table.DatasetID,
 					TableID:   table.ID,
 					Schema:    cachedSchema,
+					CachedAt:  cachedAt,
+				}
+				if m.offline {
+					return cached
+				}
+
+				version, err := m.bqClient.GetTableVersion(table.DatasetID, table.ID)
+				if err != nil {
+					return cached
+				}
+				if version.Matches(cachedSchema.Version) {
+					cached.CachedAt = time.Time{} // Verified current
+					return cached
 				}
 			}
 		}
+		if m.offline {
+			return TableSchemaLoadedMsg{DatasetID: table.DatasetID, TableID: table.ID}
+		}
+
+		return m.fetchTableSchema(table.DatasetID, table.ID, false)()
+	}
+}
 
-		// Load from BigQuery
-		schema, err := m.bqClient.GetTableSchema(table.DatasetID, table.ID)
+// fetchTableSchema loads a table schema from BigQuery and refreshes the cache
+func (m Model) fetchTableSchema(datasetID, tableID string, revalidate bool) tea.Cmd {
+	return func() tea.Msg {
+		projectID := m.currentProjectID()
+
+		schema, err := m.bqClient.GetTableSchema(datasetID, tableID)
 		if err != nil {
-			return ErrorMsg{Error: fmt.Errorf("failed to load schema for table %s: %w", table.ID, err)}
+			return ErrorMsg{Error: fmt.Errorf("failed to load schema for table %s: %w", tableID, err)}
 		}
 
 		// Cache the result if cache is available
 		if m.cache != nil {
-			_ = m.cache.SetSchema(projectID, table.DatasetID, table.ID, schema) // Continue even if caching fails
+			_ = m.cache.SetSchema(projectID, datasetID, tableID, schema) // Continue even if caching fails
 		}
 
 		return TableSchemaLoadedMsg{
-			DatasetID: table.DatasetID,
-			TableID:   table.ID,
-			Schema:    schema,
+			DatasetID:   datasetID,
+			TableID:     tableID,
+			Schema:      schema,
+			Revalidated: revalidate,
+		}
+	}
+}
+
+// loadSchemaDiff fetches both schemas and compares them
+func (m Model) loadSchemaDiff(from, to tableRef) tea.Cmd {
+	return func() tea.Msg {
+		msg := SchemaDiffLoadedMsg{From: from, To: to}
+
+		fromSchema, err := m.schemaForDiff(from)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		toSchema, err := m.schemaForDiff(to)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+
+		msg.Diffs = bigquery.DiffSchemas(fromSchema, toSchema)
+		return msg
+	}
+}
+
+// schemaForDiff loads a fresh schema so the diff doesn't report stale fields;
+// offline, the cached schema is all there is
+func (m Model) schemaForDiff(ref tableRef) (*bigquery.TableSchema, error) {
+	if m.offline {
+		if m.cache != nil {
+			if schema, _, found := m.cache.GetSchema(ref.projectID, ref.datasetID, ref.tableID); found {
+				return schema, nil
+			}
+		}
+		return nil, fmt.Errorf("schema of %s is not cached (offline)", ref)
+	}
+
+	schema, err := m.bqClient.GetTableSchemaInProject(ref.projectID, ref.datasetID, ref.tableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema for table %s: %w", ref, err)
+	}
+	if m.cache != nil {
+		_ = m.cache.SetSchema(ref.projectID, ref.datasetID, ref.tableID, schema) // Continue even if caching fails
+	}
+	return schema, nil
+}
+
+// loadProfile runs the profile query for the given columns of a table
+func (m Model) loadProfile(table tableRef, column string, columns []*bigquery.Column) tea.Cmd {
+	return func() tea.Msg {
+		profile, err := m.bqClient.ProfileTable(table.datasetID, table.tableID, columns)
+		return ProfileLoadedMsg{Table: table, Column: column, Profile: profile, Err: err}
+	}
+}
+
+// loadPartitions lists the partitions of a table
+func (m Model) loadPartitions(table tableRef) tea.Cmd {
+	return func() tea.Msg {
+		msg := PartitionsLoadedMsg{Table: table}
+		msg.Partitioning, msg.Err = m.bqClient.GetPartitioning(table.datasetID, table.tableID)
+		if msg.Err == nil && msg.Partitioning == nil {
+			msg.Err = fmt.Errorf("%s is not partitioned", table.tableID)
+		}
+		if msg.Err == nil {
+			msg.Partitions, msg.Err = m.bqClient.ListPartitions(table.datasetID, table.tableID)
 		}
+		return msg
+	}
+}
+
+// loadShards lists the shards of a date-sharded table as partitions
+func (m Model) loadShards(table tableRef, prefix string) tea.Cmd {
+	return func() tea.Msg {
+		shards, err := m.bqClient.ListShards(table.datasetID, prefix)
+		return PartitionsLoadedMsg{Table: table, Partitions: shards, Err: err}
+	}
+}
+
+// exportSchema renders the shown table's schema and copies it to the clipboard
+// or writes it to <table><ext> in the working directory
+func (m Model) exportSchema(format bigquery.SchemaFormat, toFile bool) tea.Cmd {
+	td := m.tableDetail
+	if td.schema == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		text, err := bigquery.ExportSchema(td.schema, format, td.currentProjectID, td.currentDatasetID, td.currentTableName)
+		if err != nil {
+			return ErrorMsg{Error: err}
+		}
+
+		if !toFile {
+			seq, err := clipboard.Copy(text)
+			if err != nil {
+				return ErrorMsg{Error: err}
+			}
+			return CopySuccessMsg{Text: fmt.Sprintf("%s of %s", format, td.currentTableName), OSC52: seq}
+		}
+
+		path := td.currentTableName + format.Extension()
+		// Never overwrite an existing file
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Errorf("failed to save schema: %w", err)}
+		}
+		if _, err := f.WriteString(text); err != nil {
+			f.Close()
+			return ErrorMsg{Error: fmt.Errorf("failed to save schema: %w", err)}
+		}
+		if err := f.Close(); err != nil {
+			return ErrorMsg{Error: fmt.Errorf("failed to save schema: %w", err)}
+		}
+		return FileSavedMsg{Path: path}
 	}
 }
 
 func (m Model) loadTablePreview() tea.Cmd {
+	return m.loadTablePreviewWith(bigquery.PreviewOptions{})
+}
+
+// loadTablePreviewWith previews the selected table reading a given partition
+// or sorting in BigQuery
+func (m Model) loadTablePreviewWith(opts bigquery.PreviewOptions) tea.Cmd {
 	if m.datasetList.selectedTable == nil {
 		return nil
 	}
 
-	table := m.datasetList.selectedTable
+	table := m.datasetList.detailTable()
 	return func() tea.Msg {
-		preview, err := m.bqClient.PreviewTable(table.DatasetID, table.ID, 100)
+		if m.offline {
+			return TablePreviewLoadedMsg{DatasetID: table.DatasetID, TableID: table.ID}
+		}
+
+		preview, err := m.bqClient.Preview(table.DatasetID, table.ID, opts)
 		if err != nil {
 			return ErrorMsg{Error: fmt.Errorf("failed to load preview for table %s: %w", table.ID, err)}
 		}
@@ -172,8 +561,47 @@ func (m Model) loadTablePreview() tea.Cmd {
 	}
 }
 
+// indexDataset loads the column index of a dataset for the search palette,
+// from cache when fresh and from INFORMATION_SCHEMA.COLUMNS otherwise
+func (m Model) indexDataset(projectID, datasetID string) tea.Cmd {
+	return func() tea.Msg {
+		msg := DatasetIndexedMsg{ProjectID: projectID, DatasetID: datasetID}
+
+		if m.cache != nil {
+			columns, cachedAt, found := m.cache.GetColumns(projectID, datasetID)
+			if found && (m.offline || !m.cache.IsStale(cachedAt)) {
+				msg.Columns = columns
+				return msg
+			}
+			msg.Columns = columns // Stale, but better than nothing if the query fails
+		}
+
+		if !m.offline {
+			columns, err := m.bqClient.ListColumns(projectID, datasetID)
+			if err == nil {
+				if m.cache != nil {
+					_ = m.cache.SetColumns(projectID, datasetID, columns) // Continue even if caching fails
+				}
+				msg.Columns = columns
+				return msg
+			}
+			msg.Err = err
+		}
+
+		// Without columns, at least make the cached tables searchable
+		if msg.Columns == nil && m.cache != nil {
+			msg.Tables, _, _ = m.cache.GetTables(projectID, datasetID)
+		}
+		return msg
+	}
+}
+
 func (m Model) loadProjects() tea.Cmd {
 	return func() tea.Msg {
+		if m.offline {
+			return m.loadCachedProjects()
+		}
+
 		projects, err := m.bqClient.ListProjects()
 		if err != nil {
 			return ErrorMsg{Error: fmt.Errorf("failed to load projects: %w", err)}
@@ -184,6 +612,10 @@ func (m Model) loadProjects() tea.Cmd {
 
 func (m Model) switchProject(projectID string) tea.Cmd {
 	return func() tea.Msg {
+		if m.offline {
+			return ProjectSwitchedMsg{ProjectID: projectID}
+		}
+
 		err := m.bqClient.SwitchProject(projectID)
 		if err != nil {
 			return ErrorMsg{Error: fmt.Errorf("failed to switch to project %s: %w", projectID, err)}
@@ -192,6 +624,23 @@ func (m Model) switchProject(projectID string) tea.Cmd {
 	}
 }
 
+// loadCachedProjects lists the projects with cached datasets, for offline mode
+func (m Model) loadCachedProjects() tea.Msg {
+	if m.cache == nil {
+		return ProjectsLoadedMsg{}
+	}
+	projectIDs, err := m.cache.Projects()
+	if err != nil {
+		return ErrorMsg{Error: fmt.Errorf("failed to list cached projects: %w", err)}
+	}
+
+	projects := make([]*bigquery.Project, len(projectIDs))
+	for i, id := range projectIDs {
+		projects[i] = &bigquery.Project{ID: id, Name: id}
+	}
+	return ProjectsLoadedMsg{Projects: projects}
+}
+
 func (m Model) executeQuery(query string) tea.Cmd {
 	return func() tea.Msg {
 		result, err := m.bqClient.ExecuteQuery(query)
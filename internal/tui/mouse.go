@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Panes are laid out by View with a one-column margin, a border and a
+// one-column padding on each side
+const (
+	paneFrameWidth = 4 // Margins and borders; lipgloss widths include padding
+	paneContentX   = 3 // Margin, border and padding left of the content
+)
+
+// handleMouse maps clicks, drags and the wheel onto the pane under the
+// pointer. The wheel reuses the Up/Down key handlers, so it scrolls overlays
+// too; clicks select list items, tabs and cells.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	wheelUp := msg.Button == tea.MouseButtonWheelUp
+	wheelDown := msg.Button == tea.MouseButtonWheelDown
+	if m.showHelp || m.focus == FocusSearch || m.focus == FocusGoTo {
+		return m, nil
+	}
+
+	if m.showProjectList || m.showPalette || m.showSchemaDiff || m.showProfile || m.showPartitions || m.showRecord || m.showSaveResults {
+		switch {
+		case wheelUp:
+			return m.Update(keyMsgFor(m.keyMap.Up))
+		case wheelDown:
+			return m.Update(keyMsgFor(m.keyMap.Down))
+		}
+		return m, nil
+	}
+
+	leftPaneWidth := m.width / 3
+	rightPaneX := leftPaneWidth + paneFrameWidth
+	line := msg.Y - lipgloss.Height(m.renderProjectHeader()) - 1 // Below the top border
+	inLeftPane := msg.X < rightPaneX
+
+	// Typing in the right pane keeps the keyboard, and the mouse, there
+	if !inLeftPane && m.typingInTableDetail() {
+		return m, nil
+	}
+
+	switch {
+	case wheelUp, wheelDown:
+		m.focus = FocusTableDetail
+		if inLeftPane {
+			m.focus = FocusDatasetList
+		}
+		if wheelUp {
+			return m.updateFocusedComponent(keyMsgFor(m.keyMap.Up))
+		}
+		return m.updateFocusedComponent(keyMsgFor(m.keyMap.Down))
+
+	case msg.Button != tea.MouseButtonLeft:
+		return m, nil
+
+	case inLeftPane:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		m.focus = FocusDatasetList
+		i, ok := m.datasetList.itemAt(line)
+		if !ok {
+			return m, nil
+		}
+		// Clicking the selected item opens it, like Enter
+		if i == m.datasetList.cursor {
+			return m.updateFocusedComponent(keyMsgFor(m.keyMap.Enter))
+		}
+		oldShowingTables := m.datasetList.showingTables
+		m.datasetList.cursor = i
+		m.datasetList.updateSelection()
+		return m.syncDatasetList(oldShowingTables, nil)
+	}
+
+	x := msg.X - rightPaneX - paneContentX
+	switch msg.Action {
+	case tea.MouseActionPress:
+		m.focus = FocusTableDetail
+		var cmd tea.Cmd
+		m.tableDetail, cmd = m.tableDetail.handleClick(line, x, m.loadingSchema, m.loadingPreview)
+		return m, cmd
+	case tea.MouseActionMotion:
+		m.tableDetail = m.tableDetail.handleDrag(line)
+	case tea.MouseActionRelease:
+		m.tableDetail = m.tableDetail.endDrag()
+	}
+	return m, nil
+}
+
+// keyMsgFor builds a key press matching binding so mouse actions can reuse
+// the key handlers. key.Matches compares msg.String(), which for rune keys
+// is the runes themselves.
+func keyMsgFor(binding key.Binding) tea.KeyMsg {
+	keys := binding.Keys()
+	if len(keys) == 0 {
+		return tea.KeyMsg{}
+	}
+	switch keys[0] {
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys[0])}
+}
+
+// itemAt returns the index of the item rendered on a line of the list,
+// counting from the title
+func (m DatasetListModel) itemAt(line int) (int, bool) {
+	top := 2 // Title + blank line
+	if m.filter != "" {
+		top += 2 // Filter display + blank line
+	}
+	i := m.viewOffset + line - top
+	if line < top || line-top >= m.visibleItems() || i >= len(m.getFilteredItems()) {
+		return 0, false
+	}
+	return i, true
+}
+
+// renderedHeight counts the lines text takes up once the pane wraps it
+func renderedHeight(text string, width int) int {
+	height := 0
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		height += max(1, (lipgloss.Width(line)+width-1)/max(width, 1))
+	}
+	return height
+}
+
+// tabAt returns the tab rendered at column x of the tab bar
+func (m TableDetailModel) tabAt(x int, loadingSchema, loadingPreview bool) (TabType, bool) {
+	start := 0
+	for i, label := range tabLabels(loadingSchema, loadingPreview) {
+		end := start + lipgloss.Width(TabInactiveStyle.Render(label))
+		if x >= start && x < end {
+			return TabType(i), true
+		}
+		start = end
+	}
+	return 0, false
+}
+
+// rowAt returns the index of the schema field, preview row or result row
+// rendered on a line of the tab content (below the tab bar)
+func (m TableDetailModel) rowAt(line int) (int, bool) {
+	wrapWidth := m.width + 2 // The pane wraps at its inner width
+	switch m.activeTab {
+	case SchemaTab:
+		if m.schema == nil {
+			return 0, false
+		}
+		top := renderedHeight(m.renderSchemaPreamble(), wrapWidth) +
+			renderedHeight(schemaHeaderLine, wrapWidth) + 1 // Separator
+		fields := m.getFilteredSchemaFields()
+		for i := m.scrollOffset; i < len(fields) && i-m.scrollOffset < m.getMaxVisibleSchema(); i++ {
+			// Nested fields render beneath their parent, and long lines wrap
+			height := renderedHeight(m.renderSchemaFieldTabular(fields[i], 0), wrapWidth)
+			if line >= top && line < top+height {
+				return i, true
+			}
+			top += height
+		}
+
+	case PreviewTab:
+		if m.preview == nil {
+			return 0, false
+		}
+		top := renderedHeight(m.renderPreviewPreamble(), wrapWidth) + 2 // Column header + separator
+		i := m.scrollOffset + line - top
+		if line >= top && line-top < m.getMaxVisiblePreview() && i < len(m.getFilteredPreviewRows()) {
+			return i, true
+		}
+
+	case ResultsTab:
+		if m.queryResults == nil {
+			return 0, false
+		}
+		// Rows aren't cut to the pane width, so wide ones wrap like the header
+		tableWidth := 0
+		for _, width := range m.resultsColumnWidths() {
+			tableWidth += width
+		}
+		rowHeight := renderedHeight(strings.Repeat(" ", tableWidth), wrapWidth)
+		top := renderedHeight(m.renderResultsPreamble(), wrapWidth) + rowHeight
+		startRow, endRow := m.resultsWindow()
+		if i := startRow + (line-top)/rowHeight; line >= top && i < endRow {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// columnAt returns the Preview or Results column rendered at column x
+func (m TableDetailModel) columnAt(x int) (int, bool) {
+	var widths []int
+	switch m.activeTab {
+	case PreviewTab:
+		x += m.horizontalOffset
+		for _, width := range m.calculateColumnWidths() {
+			widths = append(widths, width+1) // Cells are separated by a space
+		}
+	case ResultsTab:
+		widths = m.resultsColumnWidths()
+	}
+
+	start := 0
+	for i, width := range widths {
+		if x >= start && x < start+width {
+			return i, true
+		}
+		start += width
+	}
+	return 0, false
+}
+
+// handleClick selects the tab, schema field or cell under the pointer. line
+// counts from the top of the pane content, x from its left edge.
+func (m TableDetailModel) handleClick(line, x int, loadingSchema, loadingPreview bool) (TableDetailModel, tea.Cmd) {
+	if line == 0 {
+		if tab, ok := m.tabAt(x, loadingSchema, loadingPreview); ok && tab != m.activeTab {
+			m.switchTab(tab)
+		}
+		return m, nil
+	}
+	if m.showColumnDialog || m.showExportDialog || m.showCopyDialog {
+		return m, nil
+	}
+
+	row, ok := m.rowAt(line - 2) // Below the tab bar and the blank line after it
+	if !ok {
+		return m, nil
+	}
+	col, colOK := m.columnAt(x)
+
+	switch m.activeTab {
+	case SchemaTab:
+		// Clicking the selected field opens its query dialog, like Enter
+		if row == m.schemaRowCursor {
+			return m.handleKeypress(keyMsgFor(m.keyMap.Enter))
+		}
+		m.schemaRowCursor = row
+		return m, nil
+	case PreviewTab:
+		m.previewRowCursor = row
+		if colOK {
+			m.previewColCursor = col
+			m.ensurePreviewColumnVisible()
+		}
+	case ResultsTab:
+		m.resultsRowCursor = row
+		if colOK {
+			m.resultsColCursor = col
+		}
+	}
+
+	// A drag from here selects rows in visual mode
+	m.visualMode = false
+	m.dragging = true
+	m.dragStartRow = row
+	return m, nil
+}
+
+// handleDrag extends a visual selection from the clicked row to the row under
+// the pointer. The cursor stays put until the button is released, so the
+// Results rows, which are centered on it, don't move while dragging.
+func (m TableDetailModel) handleDrag(line int) TableDetailModel {
+	if !m.dragging {
+		return m
+	}
+	row, ok := m.rowAt(line - 2)
+	if !ok {
+		return m
+	}
+	if row != m.dragStartRow || m.visualMode {
+		m.visualMode = true
+		m.visualStartRow = m.dragStartRow
+		m.visualEndRow = row
+	}
+	return m
+}
+
+// endDrag finishes a drag with the cursor on the last selected row
+func (m TableDetailModel) endDrag() TableDetailModel {
+	if !m.dragging {
+		return m
+	}
+	m.dragging = false
+	if m.visualMode {
+		switch m.activeTab {
+		case PreviewTab:
+			m.previewRowCursor = m.visualEndRow
+			m.ensurePreviewCursorVisible()
+		case ResultsTab:
+			m.resultsRowCursor = m.visualEndRow
+		}
+	}
+	return m
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"bqui/internal/bigquery"
+)
+
+func TestRowAtMatchesRenderedPreview(t *testing.T) {
+	m := NewTableDetailModel(DefaultKeyMap())
+	m.width, m.height = 80, 30
+	m.activeTab = PreviewTab
+	m.currentTableName = "events"
+	m.preview = &bigquery.TablePreview{Headers: []string{"id", "name"}}
+	for i := 0; i < 40; i++ {
+		m.preview.Rows = append(m.preview.Rows, []interface{}{fmt.Sprintf("row%02d", i), "x"})
+	}
+	m.previewFilter = "row"
+	m.scrollOffset = 5
+
+	rowText := regexp.MustCompile(`row(\d\d)`)
+	for line, text := range strings.Split(m.View(), "\n") {
+		row, ok := m.rowAt(line - 2)
+		match := rowText.FindStringSubmatch(text)
+		if ok != (match != nil) {
+			t.Fatalf("line %d %q: expected a row: %v, got %v", line, text, match != nil, ok)
+		}
+		if !ok {
+			continue
+		}
+		if want, _ := strconv.Atoi(match[1]); row != want {
+			t.Errorf("line %d %q: mapped to row %d", line, text, row)
+		}
+	}
+
+	if col, ok := m.columnAt(0); !ok || col != 0 {
+		t.Errorf("Expected column 0 at x=0, got %d", col)
+	}
+	widths := m.calculateColumnWidths()
+	if col, ok := m.columnAt(widths[0] + 1); !ok || col != 1 {
+		t.Errorf("Expected column 1 after the first column, got %d", col)
+	}
+}
@@ -0,0 +1,375 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteWorkers is the number of datasets indexed concurrently
+const paletteWorkers = 4
+
+// paletteMaxVisible is the number of matches shown at once
+const paletteMaxVisible = 15
+
+type paletteKind int
+
+const (
+	paletteDataset paletteKind = iota
+	paletteTable
+	paletteColumn
+)
+
+// paletteEntry is one searchable dataset, table or column
+type paletteEntry struct {
+	kind      paletteKind
+	datasetID string
+	tableID   string
+	column    string
+	dataType  string
+}
+
+// path returns the dotted name that is matched against the query
+func (e paletteEntry) path() string {
+	switch e.kind {
+	case paletteTable:
+		return e.datasetID + "." + e.tableID
+	case paletteColumn:
+		return e.datasetID + "." + e.tableID + "." + e.column
+	default:
+		return e.datasetID
+	}
+}
+
+func (e paletteEntry) icon() string {
+	switch e.kind {
+	case paletteTable:
+		return "📋"
+	case paletteColumn:
+		return "🔹"
+	default:
+		return "📁"
+	}
+}
+
+// PaletteModel is the global search palette over every dataset, table and
+// column of the current project
+type PaletteModel struct {
+	entries []paletteEntry
+	paths   []string
+	filter  string
+	cursor  int
+	matches []int
+	// indexed and total count datasets for the progress line
+	indexed int
+	total   int
+	keyMap  KeyMap
+}
+
+func NewPaletteModel(keyMap KeyMap) PaletteModel {
+	return PaletteModel{keyMap: keyMap}
+}
+
+// setEntries replaces the searchable entries, keeping the filter
+func (m *PaletteModel) setEntries(entries []paletteEntry) {
+	m.entries = entries
+	m.paths = make([]string, len(entries))
+	for i, entry := range entries {
+		m.paths[i] = entry.path()
+	}
+	m.updateMatches()
+}
+
+// addEntries appends entries as their dataset is indexed. Only the new
+// entries are searched; their matches rank below the earlier ones until the
+// filter changes.
+func (m *PaletteModel) addEntries(entries []paletteEntry) {
+	start := len(m.entries)
+	m.entries = append(m.entries, entries...)
+	for _, entry := range entries {
+		m.paths = append(m.paths, entry.path())
+	}
+	if m.filter == "" {
+		for i := start; i < len(m.entries); i++ {
+			m.matches = append(m.matches, i)
+		}
+		return
+	}
+	for _, match := range fuzzy.Find(m.filter, m.paths[start:]) {
+		m.matches = append(m.matches, start+match.Index)
+	}
+}
+
+// updateMatches applies fuzzy search to the entries
+func (m *PaletteModel) updateMatches() {
+	m.matches = m.matches[:0]
+	if m.filter == "" {
+		for i := range m.entries {
+			m.matches = append(m.matches, i)
+		}
+	} else {
+		for _, match := range fuzzy.Find(m.filter, m.paths) {
+			m.matches = append(m.matches, match.Index)
+		}
+	}
+	if m.cursor >= len(m.matches) {
+		m.cursor = max(len(m.matches)-1, 0)
+	}
+}
+
+func (m PaletteModel) Update(msg tea.Msg) (PaletteModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	// Letters belong to the query, so only arrows and ctrl keys navigate
+	switch keyMsg.String() {
+	case "up", "ctrl+p", "ctrl+k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "ctrl+n", "ctrl+j":
+		if m.cursor < len(m.matches)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor < len(m.matches) {
+			entry := m.entries[m.matches[m.cursor]]
+			return m, func() tea.Msg {
+				return PaletteSelectedMsg{
+					DatasetID: entry.datasetID,
+					TableID:   entry.tableID,
+					Column:    entry.column,
+				}
+			}
+		}
+	case "backspace":
+		if len(m.filter) > 0 {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+			m.cursor = 0
+			m.updateMatches()
+		}
+	case "ctrl+u":
+		m.filter = ""
+		m.cursor = 0
+		m.updateMatches()
+	default:
+		if keyMsg.Type == tea.KeyRunes || keyMsg.Type == tea.KeySpace {
+			m.filter += string(keyMsg.Runes)
+			m.cursor = 0
+			m.updateMatches()
+		}
+	}
+
+	return m, nil
+}
+
+func (m PaletteModel) View() string {
+	var content strings.Builder
+
+	content.WriteString(HeaderStyle.Render("🔎 Search datasets, tables and columns") + "\n\n")
+	content.WriteString(SelectedItemStyle.Render("> "+m.filter+"█") + "\n\n")
+
+	if len(m.matches) == 0 {
+		if m.filter != "" {
+			content.WriteString(SubtleItemStyle.Render("No matches for: " + m.filter))
+		} else {
+			content.WriteString(SubtleItemStyle.Render("Nothing indexed yet"))
+		}
+		content.WriteString("\n")
+	}
+
+	// Keep the cursor inside the visible window
+	start := 0
+	if m.cursor >= paletteMaxVisible {
+		start = m.cursor - paletteMaxVisible + 1
+	}
+	end := min(start+paletteMaxVisible, len(m.matches))
+
+	for i := start; i < end; i++ {
+		entry := m.entries[m.matches[i]]
+		style := ItemStyle
+		if i == m.cursor {
+			style = SelectedItemStyle
+		}
+
+		line := style.Render(fmt.Sprintf("  %s %s", entry.icon(), entry.path()))
+		if entry.dataType != "" {
+			line += " " + DataTypeStyle.Render(entry.dataType)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	if len(m.matches) > end {
+		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("  ... and %d more matches", len(m.matches)-end)) + "\n")
+	}
+
+	status := fmt.Sprintf("Matches: %d/%d", len(m.matches), len(m.entries))
+	if m.indexed < m.total {
+		status += fmt.Sprintf(" • indexing datasets %d/%d...", m.indexed, m.total)
+	}
+	content.WriteString("\n" + SubtleItemStyle.Render(status))
+	content.WriteString("\n" + HelpStyle.Render("Type to search • ↑/↓ to navigate • Enter to jump • Esc to cancel"))
+
+	return content.String()
+}
+
+// indexEntries builds the palette entries of one dataset from its column
+// index, falling back to its table list when columns aren't available
+func indexEntries(datasetID string, columns []*bigquery.ColumnInfo, tables []*bigquery.Table) []paletteEntry {
+	var entries []paletteEntry
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		if !seen[column.TableID] {
+			seen[column.TableID] = true
+			entries = append(entries, paletteEntry{kind: paletteTable, datasetID: datasetID, tableID: column.TableID})
+		}
+		entries = append(entries, paletteEntry{
+			kind:      paletteColumn,
+			datasetID: datasetID,
+			tableID:   column.TableID,
+			column:    column.Name,
+			dataType:  column.Type,
+		})
+	}
+	for _, table := range tables {
+		if !seen[table.ID] {
+			seen[table.ID] = true
+			entries = append(entries, paletteEntry{kind: paletteTable, datasetID: datasetID, tableID: table.ID})
+		}
+	}
+	return entries
+}
+
+// paletteEntries flattens the datasets and the search index into palette entries
+func (m Model) paletteEntries() []paletteEntry {
+	var entries []paletteEntry
+	for _, dataset := range m.datasetList.datasets {
+		entries = append(entries, paletteEntry{kind: paletteDataset, datasetID: dataset.ID})
+	}
+
+	datasetIDs := make([]string, 0, len(m.searchIndex))
+	for datasetID := range m.searchIndex {
+		datasetIDs = append(datasetIDs, datasetID)
+	}
+	sort.Strings(datasetIDs)
+	for _, datasetID := range datasetIDs {
+		entries = append(entries, m.searchIndex[datasetID]...)
+	}
+	return entries
+}
+
+// openPalette shows the search palette and starts indexing datasets that
+// haven't been indexed yet
+func (m Model) openPalette() (tea.Model, tea.Cmd) {
+	if m.searchIndex == nil || m.indexProjectID != m.currentProjectID() {
+		m.resetSearchIndex()
+		m.searchIndex = make(map[string][]paletteEntry)
+		m.indexProjectID = m.currentProjectID()
+	}
+
+	m.palette = NewPaletteModel(m.keyMap)
+	m.focus = FocusPalette
+	m.showPalette = true
+
+	// Queue datasets that are neither indexed nor in flight
+	inFlight := make(map[string]bool)
+	for _, datasetID := range m.indexQueue {
+		inFlight[datasetID] = true
+	}
+	for _, dataset := range m.datasetList.datasets {
+		if _, ok := m.searchIndex[dataset.ID]; !ok && !inFlight[dataset.ID] && !m.indexRunning[dataset.ID] {
+			m.indexQueue = append(m.indexQueue, dataset.ID)
+		}
+	}
+
+	var cmds []tea.Cmd
+	for len(m.indexRunning) < paletteWorkers && len(m.indexQueue) > 0 {
+		cmds = append(cmds, m.startIndexingNext())
+	}
+
+	m.updatePaletteEntries()
+	return m, tea.Batch(cmds...)
+}
+
+// startIndexingNext pops the next queued dataset and indexes it
+func (m *Model) startIndexingNext() tea.Cmd {
+	if len(m.indexQueue) == 0 {
+		return nil
+	}
+	datasetID := m.indexQueue[0]
+	m.indexQueue = m.indexQueue[1:]
+	if m.indexRunning == nil {
+		m.indexRunning = make(map[string]bool)
+	}
+	m.indexRunning[datasetID] = true
+	return m.indexDataset(m.indexProjectID, datasetID)
+}
+
+// resetSearchIndex drops the index and its queue, e.g. when switching
+// project. Jobs still in flight are ignored when they finish.
+func (m *Model) resetSearchIndex() {
+	m.searchIndex = nil
+	m.indexProjectID = ""
+	m.indexQueue = nil
+	m.indexRunning = nil
+	m.indexErrors = nil
+}
+
+// updatePaletteEntries refreshes the palette after the index changed
+func (m *Model) updatePaletteEntries() {
+	m.palette.setEntries(m.paletteEntries())
+	m.palette.indexed = len(m.searchIndex)
+	m.palette.total = len(m.datasetList.datasets)
+}
+
+// handlePaletteInput routes keys to the palette; Esc closes it
+func (m Model) handlePaletteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keyMap.Escape) {
+		m.closePalette()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.palette, cmd = m.palette.Update(msg)
+	return m, cmd
+}
+
+// handleDatasetIndexed adds a dataset to the search index and indexes the next one
+func (m Model) handleDatasetIndexed(msg DatasetIndexedMsg) (tea.Model, tea.Cmd) {
+	if m.searchIndex == nil || msg.ProjectID != m.indexProjectID {
+		return m, nil // Index of a project we've since switched away from
+	}
+	delete(m.indexRunning, msg.DatasetID)
+	next := m.startIndexingNext()
+
+	entries := indexEntries(msg.DatasetID, msg.Columns, msg.Tables)
+	m.searchIndex[msg.DatasetID] = entries
+	if msg.Err != nil {
+		m.indexErrors = append(m.indexErrors, msg.Err)
+	}
+	m.palette.addEntries(entries)
+	m.palette.indexed = len(m.searchIndex)
+
+	if len(m.indexRunning) == 0 && len(m.indexQueue) == 0 {
+		m.statusMessage = fmt.Sprintf("Indexed %d datasets for search", len(m.searchIndex))
+		if len(m.indexErrors) > 0 {
+			m.statusMessage += fmt.Sprintf(" (%d without columns: %s)", len(m.indexErrors), m.indexErrors[0].Error())
+		}
+	}
+	return m, next
+}
+
+// closePalette hides the palette and returns focus to the dataset list
+func (m *Model) closePalette() {
+	m.showPalette = false
+	m.focus = FocusDatasetList
+}
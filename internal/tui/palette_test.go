@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bqui/internal/bigquery"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIndexEntries(t *testing.T) {
+	columns := []*bigquery.ColumnInfo{
+		{TableID: "users", Name: "id", Type: "INT64"},
+		{TableID: "users", Name: "email", Type: "STRING"},
+	}
+	tables := []*bigquery.Table{{ID: "users"}, {ID: "orders"}}
+
+	entries := indexEntries("shop", columns, tables)
+
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.path())
+	}
+	expected := []string{"shop.users", "shop.users.id", "shop.users.email", "shop.orders"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Expected entry %d to be '%s', got '%s'", i, expected[i], paths[i])
+		}
+	}
+}
+
+func TestPaletteSelect(t *testing.T) {
+	palette := NewPaletteModel(DefaultKeyMap())
+	palette.setEntries(indexEntries("shop", []*bigquery.ColumnInfo{
+		{TableID: "users", Name: "email", Type: "STRING"},
+		{TableID: "orders", Name: "total", Type: "NUMERIC"},
+	}, nil))
+
+	for _, r := range "ordtot" {
+		palette, _ = palette.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := palette.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command on enter")
+	}
+
+	selected, ok := cmd().(PaletteSelectedMsg)
+	if !ok {
+		t.Fatalf("Expected PaletteSelectedMsg, got %T", cmd())
+	}
+	if selected.DatasetID != "shop" || selected.TableID != "orders" || selected.Column != "total" {
+		t.Errorf("Unexpected selection: %+v", selected)
+	}
+}
+
+func TestPaletteIndexIsDroppedOnProjectSwitch(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "old"})
+	for i := 0; i < paletteWorkers+2; i++ {
+		m.datasetList.datasets = append(m.datasetList.datasets, &bigquery.Dataset{ID: fmt.Sprintf("ds%d", i)})
+	}
+	model, _ := m.openPalette()
+	m = model.(Model)
+	if len(m.indexRunning) != paletteWorkers || len(m.indexQueue) != 2 {
+		t.Fatalf("Expected %d running and 2 queued, got %d and %d", paletteWorkers, len(m.indexRunning), len(m.indexQueue))
+	}
+
+	model, _ = m.Update(ProjectSwitchedMsg{ProjectID: "new"})
+	m = model.(Model)
+	if m.searchIndex != nil || len(m.indexQueue) != 0 || len(m.indexRunning) != 0 {
+		t.Fatal("Expected the old project's index and queue to be dropped")
+	}
+
+	// A job of the old project finishing late neither lands in the new index
+	// nor starts the old queue
+	m.datasetList.datasets = []*bigquery.Dataset{{ID: "fresh"}}
+	model, _ = m.openPalette()
+	m = model.(Model)
+	model, cmd := m.Update(DatasetIndexedMsg{ProjectID: "old", DatasetID: "ds0", Tables: []*bigquery.Table{{ID: "t"}}})
+	m = model.(Model)
+	if cmd != nil || len(m.searchIndex) != 0 || !m.indexRunning["fresh"] {
+		t.Errorf("Expected the stale result to be ignored, got index %v", m.searchIndex)
+	}
+
+	model, _ = m.Update(DatasetIndexedMsg{ProjectID: "new", DatasetID: "fresh", Tables: []*bigquery.Table{{ID: "orders"}}})
+	m = model.(Model)
+	if len(m.palette.entries) != 2 || m.palette.entries[1].path() != "fresh.orders" || len(m.palette.matches) != 2 {
+		t.Errorf("Expected the new entries to be appended, got %+v", m.palette.entries)
+	}
+}
+
+func TestPaletteAddEntriesKeepsFilter(t *testing.T) {
+	palette := NewPaletteModel(DefaultKeyMap())
+	palette.setEntries(indexEntries("shop", nil, []*bigquery.Table{{ID: "orders"}, {ID: "users"}}))
+	for _, r := range "ord" {
+		palette, _ = palette.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	palette.addEntries(indexEntries("web", nil, []*bigquery.Table{{ID: "orders"}, {ID: "visits"}}))
+
+	var matched []string
+	for _, i := range palette.matches {
+		matched = append(matched, palette.entries[i].path())
+	}
+	if len(matched) != 2 || matched[0] != "shop.orders" || matched[1] != "web.orders" {
+		t.Errorf("Expected both orders tables to match, got %v", matched)
+	}
+}
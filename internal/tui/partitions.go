@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PartitionsModel lists the partitions of a partitioned table so one can be
+// previewed or queried. For date-sharded tables it lists the shards instead,
+// and a range of them can be queried through the wildcard table.
+type PartitionsModel struct {
+	table        tableRef
+	partitioning *bigquery.Partitioning
+	partitions   []*bigquery.Partition
+	// shardPrefix is set when browsing the shards of table, a wildcard table
+	shardPrefix string
+	// anchor is the shard where a range selection started, or -1
+	anchor  int
+	loading bool
+	err     error
+	cursor  int
+	offset  int
+	height  int
+	// returnFocus is restored when the browser closes without a selection
+	returnFocus FocusState
+	keyMap      KeyMap
+}
+
+func NewPartitionsModel(keyMap KeyMap, table tableRef, shardPrefix string, height int, returnFocus FocusState) PartitionsModel {
+	return PartitionsModel{
+		table:       table,
+		shardPrefix: shardPrefix,
+		anchor:      -1,
+		loading:     true,
+		height:      height,
+		returnFocus: returnFocus,
+		keyMap:      keyMap,
+	}
+}
+
+// maxVisible is the number of partitions that fit below the header
+func (m PartitionsModel) maxVisible() int {
+	return max(m.height-9, 1)
+}
+
+func (m PartitionsModel) Update(msg tea.Msg) (PartitionsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.partitions) == 0 {
+		return m, nil
+	}
+
+	last := len(m.partitions) - 1
+	switch {
+	case key.Matches(keyMsg, m.keyMap.Up):
+		m.cursor = max(m.cursor-1, 0)
+	case key.Matches(keyMsg, m.keyMap.Down):
+		m.cursor = min(m.cursor+1, last)
+	case key.Matches(keyMsg, m.keyMap.PageUp):
+		m.cursor = max(m.cursor-m.maxVisible(), 0)
+	case key.Matches(keyMsg, m.keyMap.PageDown):
+		m.cursor = min(m.cursor+m.maxVisible(), last)
+	case key.Matches(keyMsg, m.keyMap.Top, m.keyMap.VimTop):
+		m.cursor = 0
+	case key.Matches(keyMsg, m.keyMap.Bottom, m.keyMap.VimBottom):
+		m.cursor = last
+	case key.Matches(keyMsg, m.keyMap.SelectRange) && m.shardPrefix != "":
+		if m.anchor == m.cursor {
+			m.anchor = -1
+		} else {
+			m.anchor = m.cursor
+		}
+	case key.Matches(keyMsg, m.keyMap.Enter, m.keyMap.QueryPartition):
+		selected := PartitionSelectedMsg{
+			Table:       m.table,
+			PartitionID: m.partitions[m.cursor].ID,
+			Query:       key.Matches(keyMsg, m.keyMap.QueryPartition),
+		}
+		if m.anchor >= 0 && selected.Query {
+			selected.EndPartitionID = m.partitions[m.anchor].ID
+		}
+		return m, func() tea.Msg { return selected }
+	}
+
+	// Keep the cursor inside the visible window
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+m.maxVisible() {
+		m.offset = m.cursor - m.maxVisible() + 1
+	}
+	return m, nil
+}
+
+func (m PartitionsModel) View() string {
+	var content strings.Builder
+
+	title := "🗓  Partitions of "
+	if m.shardPrefix != "" {
+		title = "🗓  Shards of "
+	}
+	content.WriteString(HeaderStyle.Render(title+m.table.String()) + "\n")
+
+	switch {
+	case m.loading:
+		content.WriteString("\n" + SubtleItemStyle.Render("Loading partitions...") + "\n")
+	case m.err != nil:
+		content.WriteString("\n" + ErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	case len(m.partitions) == 0:
+		content.WriteString("\n" + SubtleItemStyle.Render("The table has no partitions yet") + "\n")
+	default:
+		column := "Partition"
+		if m.shardPrefix != "" {
+			column = "Shard"
+			content.WriteString(SubtleItemStyle.Render(m.describeShards()) + "\n\n")
+		} else {
+			content.WriteString(SubtleItemStyle.Render(describePartitioning(m.partitioning)) + "\n\n")
+		}
+		content.WriteString(SelectedHeaderStyle.Render(fmt.Sprintf("  %-18s  %10s  %10s  %-19s", column, "Rows", "Size", "Last modified")) + "\n")
+
+		end := min(m.offset+m.maxVisible(), len(m.partitions))
+		for i := m.offset; i < end; i++ {
+			partition := m.partitions[i]
+			style := ItemStyle
+			if i == m.cursor {
+				style = SelectedItemStyle
+			} else if m.inRange(i) {
+				style = VisualSelectionStyle
+			}
+			modified := "-"
+			if !partition.LastModified.IsZero() {
+				modified = partition.LastModified.Local().Format(time.DateTime)
+			}
+			content.WriteString(style.Render(fmt.Sprintf("  %-18s  %10s  %10s  %-19s",
+				partition.ID, formatCount(uint64(partition.Rows)), FormatBytes(partition.Bytes), modified)) + "\n")
+		}
+		if len(m.partitions) > end {
+			content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("... and %d more", len(m.partitions)-end)) + "\n")
+		}
+	}
+
+	query := m.keyMap.QueryPartition.Help().Key
+	help := fmt.Sprintf("↑/↓ to navigate • Enter to preview • %s to query • Esc to close", query)
+	if m.shardPrefix != "" {
+		help = fmt.Sprintf("↑/↓ to navigate • %s to start/clear a range • Enter to open shard • %s to query shards • Esc to close",
+			m.keyMap.SelectRange.Help().Key, query)
+	}
+	content.WriteString("\n" + HelpStyle.Render(help))
+
+	return content.String()
+}
+
+// inRange reports whether the row at i is in the shard range being selected
+func (m PartitionsModel) inRange(i int) bool {
+	return m.anchor >= 0 && i >= min(m.anchor, m.cursor) && i <= max(m.anchor, m.cursor)
+}
+
+// describeShards summarizes the shards and the range selected for a query
+func (m PartitionsModel) describeShards() string {
+	desc := fmt.Sprintf("Date-sharded • %d shards, queried as %s with _TABLE_SUFFIX", len(m.partitions), m.table.tableID)
+	if m.anchor >= 0 {
+		from, to := m.partitions[m.cursor].ID, m.partitions[m.anchor].ID
+		desc += " • range: " + bigquery.SuffixFilter(from, to)
+	}
+	return desc
+}
+
+// describePartitioning summarizes how a table is partitioned
+func describePartitioning(p *bigquery.Partitioning) string {
+	if p == nil {
+		return ""
+	}
+
+	var desc string
+	switch {
+	case p.Type == "RANGE":
+		desc = fmt.Sprintf("Integer-range partitioned on %s (%d to %d, every %d)", p.Field, p.RangeStart, p.RangeEnd, p.RangeInterval)
+	case p.Field == "":
+		desc = fmt.Sprintf("Partitioned by ingestion time (%s)", p.Type)
+	default:
+		desc = fmt.Sprintf("Partitioned by %s on %s", p.Type, p.Field)
+	}
+	if p.RequireFilter {
+		desc += " • partition filter required"
+	}
+	return desc
+}
+
+// partitionsTarget returns the table whose partitions the browser lists for
+// the selection: the wildcard table and shard prefix for date shards
+func (m Model) partitionsTarget() (ref tableRef, shardPrefix string, ok bool) {
+	ref, ok = m.selectedTableRef()
+	if !ok {
+		return ref, "", false
+	}
+	if prefix, _, _, sharded := bigquery.SplitShardID(ref.tableID); sharded {
+		ref.tableID = prefix + "*"
+		return ref, prefix, true
+	}
+	return ref, "", true
+}
+
+// openPartitions opens the partition browser for the selected table
+func (m Model) openPartitions() (tea.Model, tea.Cmd) {
+	if m.offline {
+		m.statusMessage = "Partitions are not available in offline mode"
+		return m, nil
+	}
+	ref, shardPrefix, ok := m.partitionsTarget()
+	if !ok {
+		m.statusMessage = "Select a table to browse its partitions"
+		return m, nil
+	}
+
+	m.partitions = NewPartitionsModel(m.keyMap, ref, shardPrefix, m.height, m.focus)
+	m.showPartitions = true
+	m.focus = FocusPartitions
+	if shardPrefix != "" {
+		return m, m.loadShards(ref, shardPrefix)
+	}
+	return m, m.loadPartitions(ref)
+}
+
+// handlePartitionsInput routes keys to the partition browser; Esc closes it
+func (m Model) handlePartitionsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap.Escape, m.keyMap.Back):
+		m.showPartitions = false
+		m.focus = m.partitions.returnFocus
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.partitions, cmd = m.partitions.Update(msg)
+	return m, cmd
+}
+
+// selectPartition previews the chosen partition, or runs a query over it
+func (m Model) selectPartition(msg PartitionSelectedMsg) (tea.Model, tea.Cmd) {
+	m.showPartitions = false
+	m.focus = FocusTableDetail
+
+	// The selection may have moved on while the browser was open
+	if ref, _, ok := m.partitionsTarget(); !ok || ref != msg.Table {
+		return m, nil
+	}
+
+	if prefix := m.partitions.shardPrefix; prefix != "" {
+		return m.selectShards(msg, prefix)
+	}
+
+	if msg.Query {
+		filter, err := m.partitions.partitioning.Filter(msg.PartitionID)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %s", err.Error())
+			return m, nil
+		}
+		query := fmt.Sprintf("SELECT * FROM `%s` WHERE %s LIMIT 100", msg.Table, filter)
+		var cmd tea.Cmd
+		m.tableDetail, cmd = m.tableDetail.runQuery(query)
+		return m, cmd
+	}
+
+	m.tableDetail.activeTab = PreviewTab
+	m.tableDetail.preview = nil
+	m.loadingPreview = true
+	m.statusMessage = fmt.Sprintf("Loading partition %s...", msg.PartitionID)
+	return m, m.loadTablePreviewWith(bigquery.PreviewOptions{PartitionID: msg.PartitionID})
+}
+
+// selectShards opens the chosen shard, or queries the chosen range of shards
+// through the wildcard table
+func (m Model) selectShards(msg PartitionSelectedMsg, prefix string) (tea.Model, tea.Cmd) {
+	if !msg.Query {
+		return m.jumpTo(jumpTarget{datasetID: msg.Table.datasetID, tableID: prefix + msg.PartitionID})
+	}
+
+	from, to := msg.PartitionID, msg.EndPartitionID
+	if to == "" {
+		to = from
+	} else if from > to {
+		from, to = to, from
+	}
+	// Column dialog queries on these shards use the same range from now on
+	m.tableDetail.shardWildcard = msg.Table.String()
+	m.tableDetail.shardRangeFrom = from
+	m.tableDetail.shardRangeTo = to
+
+	query := fmt.Sprintf("SELECT * FROM `%s` WHERE %s LIMIT 100", msg.Table, bigquery.SuffixFilter(from, to))
+	var cmd tea.Cmd
+	m.tableDetail, cmd = m.tableDetail.runQuery(query)
+	return m, cmd
+}
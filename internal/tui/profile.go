@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ProfileModel shows per-column statistics of a table or a single column
+type ProfileModel struct {
+	table   tableRef
+	column  string // Empty when profiling the whole table
+	profile *bigquery.TableProfile
+	loading bool
+	err     error
+	lines   []string
+	offset  int
+	height  int
+	// returnFocus is restored when the panel closes
+	returnFocus FocusState
+	keyMap      KeyMap
+}
+
+func NewProfileModel(keyMap KeyMap, table tableRef, column string, height int, returnFocus FocusState) ProfileModel {
+	return ProfileModel{
+		table:       table,
+		column:      column,
+		loading:     true,
+		height:      height,
+		returnFocus: returnFocus,
+		keyMap:      keyMap,
+	}
+}
+
+// setProfile stores a loaded profile and renders its lines
+func (m *ProfileModel) setProfile(profile *bigquery.TableProfile, err error) {
+	m.loading = false
+	m.profile = profile
+	m.err = err
+	m.lines = nil
+	m.offset = 0
+	if profile == nil {
+		return
+	}
+
+	pathWidth := len("Column")
+	for _, col := range profile.Columns {
+		pathWidth = max(pathWidth, min(len(col.Path), 40))
+	}
+
+	header := fmt.Sprintf("%-*s  %-10s  %7s  %9s  %-20s  %-20s  %7s",
+		pathWidth, "Column", "Type", "Nulls", "Distinct", "Min", "Max", "Avg len")
+	m.lines = append(m.lines, SelectedHeaderStyle.Render(header))
+
+	for _, col := range profile.Columns {
+		line := fmt.Sprintf("%-*s  %-10s  %6.1f%%  %9s  %-20s  %-20s  %7s",
+			pathWidth, truncate(col.Path, 40),
+			truncate(string(col.Type), 10),
+			100*col.NullFraction(profile.RowCount),
+			formatOptionalCount(col.DistinctCount),
+			truncate(optionalString(col.Min), 20),
+			truncate(optionalString(col.Max), 20),
+			formatOptionalFloat(col.AvgLength))
+		m.lines = append(m.lines, ItemStyle.Render(line))
+
+		if len(col.TopValues) > 0 {
+			var top []string
+			for _, value := range col.TopValues {
+				top = append(top, fmt.Sprintf("%s (%s)", truncate(value.Value, 30), formatCount(uint64(value.Count))))
+			}
+			m.lines = append(m.lines, SubtleItemStyle.Render(fmt.Sprintf("%*s  top: %s", pathWidth, "", strings.Join(top, " • "))))
+		}
+	}
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
+
+func formatOptionalCount(n *int64) string {
+	if n == nil {
+		return "-"
+	}
+	return "≈" + formatCount(uint64(*n))
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", *f)
+}
+
+// maxVisible is the number of profile lines that fit below the header
+func (m ProfileModel) maxVisible() int {
+	return max(m.height-7, 1)
+}
+
+func (m ProfileModel) Update(msg tea.Msg) (ProfileModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	lastOffset := max(len(m.lines)-m.maxVisible(), 0)
+	switch {
+	case key.Matches(keyMsg, m.keyMap.Up):
+		m.offset = max(m.offset-1, 0)
+	case key.Matches(keyMsg, m.keyMap.Down):
+		m.offset = min(m.offset+1, lastOffset)
+	case key.Matches(keyMsg, m.keyMap.PageUp):
+		m.offset = max(m.offset-m.maxVisible(), 0)
+	case key.Matches(keyMsg, m.keyMap.PageDown):
+		m.offset = min(m.offset+m.maxVisible(), lastOffset)
+	case key.Matches(keyMsg, m.keyMap.Top, m.keyMap.VimTop):
+		m.offset = 0
+	case key.Matches(keyMsg, m.keyMap.Bottom, m.keyMap.VimBottom):
+		m.offset = lastOffset
+	}
+
+	return m, nil
+}
+
+func (m ProfileModel) View() string {
+	var content strings.Builder
+
+	title := "📈 Profile of " + m.table.String()
+	if m.column != "" {
+		title += "." + m.column
+	}
+	content.WriteString(HeaderStyle.Render(title) + "\n")
+
+	switch {
+	case m.loading:
+		content.WriteString("\n" + SubtleItemStyle.Render("Running profile query...") + "\n")
+	case m.err != nil:
+		content.WriteString("\n" + ErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	default:
+		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("%s rows • distinct counts and top values are approximate",
+			formatCount(uint64(m.profile.RowCount)))) + "\n\n")
+		end := min(m.offset+m.maxVisible(), len(m.lines))
+		for _, line := range m.lines[m.offset:end] {
+			content.WriteString(line + "\n")
+		}
+		if len(m.lines) > end {
+			content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("... and %d more lines", len(m.lines)-end)) + "\n")
+		}
+	}
+
+	content.WriteString("\n" + HelpStyle.Render("↑/↓ to scroll • Esc to close"))
+
+	return content.String()
+}
+
+// openProfile profiles the selected schema column, or the whole table when
+// wholeTable is set or no column is selected
+func (m Model) openProfile(wholeTable bool) (tea.Model, tea.Cmd) {
+	if m.offline {
+		m.statusMessage = "Profiling is disabled in offline mode"
+		return m, nil
+	}
+	ref, ok := m.selectedTableRef()
+	if !ok || m.tableDetail.schema == nil || m.tableDetail.currentTableName != ref.tableID {
+		m.statusMessage = "Select a table and wait for its schema to load to profile it"
+		return m, nil
+	}
+
+	columns := m.tableDetail.schema.Fields
+	column := ""
+	if !wholeTable {
+		fields := m.tableDetail.getFilteredSchemaFields()
+		if m.tableDetail.schemaRowCursor >= len(fields) {
+			return m, nil
+		}
+		columns = []*bigquery.Column{fields[m.tableDetail.schemaRowCursor]}
+		column = columns[0].Name
+	}
+
+	m.profile = NewProfileModel(m.keyMap, ref, column, m.height, m.focus)
+	m.showProfile = true
+	m.focus = FocusProfile
+	m.statusMessage = "Profiling " + ref.String() + "..."
+	return m, m.loadProfile(ref, column, columns)
+}
+
+// handleProfileInput routes keys to the profile panel; Esc closes it
+func (m Model) handleProfileInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap.Escape, m.keyMap.Back):
+		m.showProfile = false
+		m.focus = m.profile.returnFocus
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.profile, cmd = m.profile.Update(msg)
+	return m, cmd
+}
@@ -16,14 +16,16 @@ type ProjectSelectorModel struct {
 	cursor           int
 	filter           string
 	filteredProjects []*bigquery.Project
+	keyMap           KeyMap
 }
 
-func NewProjectSelectorModel() ProjectSelectorModel {
+func NewProjectSelectorModel(keyMap KeyMap) ProjectSelectorModel {
 	return ProjectSelectorModel{
 		projects:         make([]*bigquery.Project, 0),
 		cursor:           0,
 		filter:           "",
 		filteredProjects: make([]*bigquery.Project, 0),
+		keyMap:           keyMap,
 	}
 }
 
@@ -45,17 +47,17 @@ func (m ProjectSelectorModel) Update(msg tea.Msg) (ProjectSelectorModel, tea.Cmd
 
 func (m ProjectSelectorModel) handleKeypress(msg tea.KeyMsg) (ProjectSelectorModel, tea.Cmd) {
 	switch {
-	case key.Matches(msg, DefaultKeyMap().Up):
+	case key.Matches(msg, m.keyMap.Up):
 		if m.cursor > 0 {
 			m.cursor--
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Down):
+	case key.Matches(msg, m.keyMap.Down):
 		if m.cursor < len(m.filteredProjects)-1 {
 			m.cursor++
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Enter):
+	case key.Matches(msg, m.keyMap.Enter):
 		if len(m.filteredProjects) > 0 && m.cursor < len(m.filteredProjects) {
 			selectedProject := m.filteredProjects[m.cursor]
 			return m, func() tea.Msg {
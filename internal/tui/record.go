@@ -0,0 +1,337 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RecordModel shows one Preview or Results row vertically as column: value
+// pairs, with nested records and arrays expanded beneath their column
+type RecordModel struct {
+	title   string
+	headers []string
+	// fields is the schema of the row's columns; nil when it isn't known
+	fields []*bigquery.Column
+	rows   [][]interface{}
+	index  int
+	lines  []recordLine
+	cursor int
+	offset int
+	height int
+	width  int
+	// returnFocus is restored when the view closes
+	returnFocus FocusState
+	keyMap      KeyMap
+}
+
+// recordLine is one column, record field or array element of the row
+type recordLine struct {
+	depth int
+	name  string
+	typ   string
+	value string
+	// copy is the text copied for the line: the value itself for scalars
+	// and JSON for records and arrays
+	copy string
+}
+
+func NewRecordModel(keyMap KeyMap, title string, headers []string, fields []*bigquery.Column, rows [][]interface{}, index, width, height int, returnFocus FocusState) RecordModel {
+	m := RecordModel{
+		title:       title,
+		headers:     headers,
+		fields:      fields,
+		rows:        rows,
+		index:       index,
+		height:      height,
+		width:       width,
+		returnFocus: returnFocus,
+		keyMap:      keyMap,
+	}
+	m.setRow(index)
+	return m
+}
+
+// setRow shows the row at index, keeping the cursor on the same line when
+// the rows have the same shape
+func (m *RecordModel) setRow(index int) {
+	m.index = index
+	m.lines = nil
+	row := m.rows[index]
+	for i, header := range m.headers {
+		var field *bigquery.Column
+		if i < len(m.fields) {
+			field = m.fields[i]
+		}
+		m.lines = appendRecordLines(m.lines, 0, header, field, cell(row, i))
+	}
+	m.cursor = min(m.cursor, max(len(m.lines)-1, 0))
+	m.ensureCursorVisible()
+}
+
+// appendRecordLines adds the lines for a value, recursing into records and
+// arrays. field may be nil, in which case types are guessed from the value.
+func appendRecordLines(lines []recordLine, depth int, name string, field *bigquery.Column, value interface{}) []recordLine {
+	line := recordLine{
+		depth: depth,
+		name:  name,
+		typ:   recordType(field, value),
+		copy:  recordCopyText(field, value),
+	}
+
+	values, isList := bigquery.NestedValues(value)
+	switch {
+	case value == nil:
+		line.value = "NULL"
+		return append(lines, line)
+	case !isList:
+		line.value = line.copy
+		return append(lines, line)
+	}
+
+	if field != nil && field.Repeated {
+		// Elements share the column's type but aren't repeated themselves
+		element := *field
+		element.Repeated = false
+		line.value = fmt.Sprintf("[%d items]", len(values))
+		lines = append(lines, line)
+		for i, v := range values {
+			lines = appendRecordLines(lines, depth+1, fmt.Sprintf("[%d]", i), &element, v)
+		}
+		return lines
+	}
+
+	lines = append(lines, line)
+	for i, v := range values {
+		var child *bigquery.Column
+		childName := fmt.Sprintf("[%d]", i)
+		if field != nil && i < len(field.Fields) {
+			child = field.Fields[i]
+			childName = child.Name
+		}
+		lines = appendRecordLines(lines, depth+1, childName, child, v)
+	}
+	return lines
+}
+
+// recordType describes a value's type, from the schema when it's known
+func recordType(field *bigquery.Column, value interface{}) string {
+	if field != nil {
+		if field.Repeated {
+			return "ARRAY<" + string(field.Type) + ">"
+		}
+		return string(field.Type)
+	}
+	if value == nil {
+		return ""
+	}
+	if _, isList := bigquery.NestedValues(value); isList {
+		return "ARRAY"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// recordCopyText renders a value for the clipboard
+func recordCopyText(field *bigquery.Column, value interface{}) string {
+	if _, isList := bigquery.NestedValues(value); !isList {
+		return formatRecordScalar(value)
+	}
+	data, err := json.Marshal(recordJSON(field, value))
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// recordJSON converts a value to what it looks like in BigQuery's JSON
+// output: records become objects keyed by field name
+func recordJSON(field *bigquery.Column, value interface{}) interface{} {
+	values, isList := bigquery.NestedValues(value)
+	switch {
+	case !isList:
+		switch value.(type) {
+		case nil, bool, int64, float64:
+			return value
+		}
+		return formatRecordScalar(value)
+	case field != nil && field.Repeated:
+		element := *field
+		element.Repeated = false
+		items := make([]interface{}, len(values))
+		for i, v := range values {
+			items[i] = recordJSON(&element, v)
+		}
+		return items
+	case field != nil && len(field.Fields) > 0:
+		object := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			if i < len(field.Fields) {
+				object[field.Fields[i].Name] = recordJSON(field.Fields[i], v)
+			}
+		}
+		return object
+	}
+	items := make([]interface{}, len(values))
+	for i, v := range values {
+		items[i] = recordJSON(nil, v)
+	}
+	return items
+}
+
+func formatRecordScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case *big.Rat:
+		return strings.TrimRight(strings.TrimRight(v.FloatString(9), "0"), ".")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// maxVisible is the number of lines that fit below the header
+func (m RecordModel) maxVisible() int {
+	return max(m.height-7, 1)
+}
+
+func (m *RecordModel) ensureCursorVisible() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.maxVisible() {
+		m.offset = m.cursor - m.maxVisible() + 1
+	}
+}
+
+func (m RecordModel) Update(msg tea.Msg) (RecordModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.lines) == 0 {
+		return m, nil
+	}
+
+	last := len(m.lines) - 1
+	switch {
+	case key.Matches(keyMsg, m.keyMap.Up):
+		m.cursor = max(m.cursor-1, 0)
+	case key.Matches(keyMsg, m.keyMap.Down):
+		m.cursor = min(m.cursor+1, last)
+	case key.Matches(keyMsg, m.keyMap.PageUp):
+		m.cursor = max(m.cursor-m.maxVisible(), 0)
+	case key.Matches(keyMsg, m.keyMap.PageDown):
+		m.cursor = min(m.cursor+m.maxVisible(), last)
+	case key.Matches(keyMsg, m.keyMap.Top, m.keyMap.VimTop):
+		m.cursor = 0
+	case key.Matches(keyMsg, m.keyMap.Bottom, m.keyMap.VimBottom):
+		m.cursor = last
+	case key.Matches(keyMsg, m.keyMap.Left):
+		if m.index > 0 {
+			m.setRow(m.index - 1)
+		}
+	case key.Matches(keyMsg, m.keyMap.Right):
+		if m.index < len(m.rows)-1 {
+			m.setRow(m.index + 1)
+		}
+	case key.Matches(keyMsg, m.keyMap.Copy, m.keyMap.CopyAlt):
+		line := m.lines[m.cursor]
+		return m, copyToClipboard(line.copy, fmt.Sprintf("Copied %s: %s", line.name, truncate(line.copy, 50)))
+	}
+	m.ensureCursorVisible()
+
+	return m, nil
+}
+
+func (m RecordModel) View() string {
+	var content strings.Builder
+
+	content.WriteString(HeaderStyle.Render("📄 "+m.title) + "\n")
+	content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Row %d of %d", m.index+1, len(m.rows))) + "\n\n")
+
+	nameWidth, typeWidth := 0, 0
+	for _, line := range m.lines {
+		nameWidth = max(nameWidth, min(2*line.depth+len(line.name), 40))
+		typeWidth = max(typeWidth, min(len(line.typ), 24))
+	}
+	valueWidth := max(m.width-nameWidth-typeWidth-8, 20)
+
+	end := min(m.offset+m.maxVisible(), len(m.lines))
+	for i := m.offset; i < end; i++ {
+		line := m.lines[i]
+		name := strings.Repeat("  ", line.depth) + line.name
+		value := strings.ReplaceAll(line.value, "\n", "⏎")
+		text := fmt.Sprintf("%-*s  %-*s  %s", nameWidth, truncate(name, 40), typeWidth, truncate(line.typ, 24), truncate(value, valueWidth))
+
+		style := ItemStyle
+		switch {
+		case i == m.cursor:
+			style = SelectedItemStyle
+		case line.value == "NULL":
+			style = SubtleItemStyle
+		}
+		content.WriteString(style.Render(text) + "\n")
+	}
+	if len(m.lines) > end {
+		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("... and %d more lines", len(m.lines)-end)) + "\n")
+	}
+
+	content.WriteString("\n" + HelpStyle.Render("↑/↓ to move • ←/→ previous/next row • y to copy value • Esc to close"))
+
+	return content.String()
+}
+
+// openRecord shows the Preview or Results row under the cursor vertically
+func (m Model) openRecord() (tea.Model, tea.Cmd) {
+	td := m.tableDetail
+	switch {
+	case m.focus != FocusTableDetail:
+		m.statusMessage = "Select a row in the Preview or Results tab to open it as a record"
+		return m, nil
+
+	case td.activeTab == PreviewTab && td.preview != nil:
+		rows := td.getFilteredPreviewRows()
+		if td.previewRowCursor >= len(rows) {
+			return m, nil
+		}
+		var fields []*bigquery.Column
+		if td.preview.Schema != nil {
+			fields = td.preview.Schema.Fields
+		}
+		m.record = NewRecordModel(m.keyMap, td.currentTableName, td.preview.Headers, fields, rows, td.previewRowCursor, m.width, m.height, m.focus)
+
+	case td.activeTab == ResultsTab && td.queryResults != nil:
+		rows := td.getResultRows()
+		if td.resultsRowCursor >= len(rows) {
+			return m, nil
+		}
+		headers, fields := td.resultsColumns()
+		m.record = NewRecordModel(m.keyMap, "Query results", headers, fields, rows, td.resultsRowCursor, m.width, m.height, m.focus)
+
+	default:
+		m.statusMessage = "Select a row in the Preview or Results tab to open it as a record"
+		return m, nil
+	}
+
+	m.showRecord = true
+	m.focus = FocusRecord
+	return m, nil
+}
+
+// handleRecordInput routes keys to the record view; Esc closes it
+func (m Model) handleRecordInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap.Escape, m.keyMap.Back, m.keyMap.Record):
+		m.showRecord = false
+		m.focus = m.record.returnFocus
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.record, cmd = m.record.Update(msg)
+	return m, cmd
+}
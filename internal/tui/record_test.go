@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bqui/internal/bigquery"
+
+	bq "cloud.google.com/go/bigquery"
+)
+
+func TestRecordLines(t *testing.T) {
+	fields := []*bigquery.Column{
+		{Name: "id", Type: bq.IntegerFieldType},
+		{Name: "address", Type: bq.RecordFieldType, Fields: []*bigquery.Column{
+			{Name: "city", Type: bq.StringFieldType},
+			{Name: "zip", Type: bq.StringFieldType},
+		}},
+		{Name: "tags", Type: bq.StringFieldType, Repeated: true},
+	}
+	row := []interface{}{int64(7), []bq.Value{"Paris", nil}, []bq.Value{"a", "b"}}
+
+	m := NewRecordModel(DefaultKeyMap(), "t", []string{"id", "address", "tags"}, fields, [][]interface{}{row}, 0, 80, 30, FocusTableDetail)
+
+	want := []recordLine{
+		{depth: 0, name: "id", typ: "INTEGER", value: "7", copy: "7"},
+		{depth: 0, name: "address", typ: "RECORD", copy: `{"city":"Paris","zip":null}`},
+		{depth: 1, name: "city", typ: "STRING", value: "Paris", copy: "Paris"},
+		{depth: 1, name: "zip", typ: "STRING", value: "NULL", copy: "NULL"},
+		{depth: 0, name: "tags", typ: "ARRAY<STRING>", value: "[2 items]", copy: `["a","b"]`},
+		{depth: 1, name: "[0]", typ: "STRING", value: "a", copy: "a"},
+		{depth: 1, name: "[1]", typ: "STRING", value: "b", copy: "b"},
+	}
+	if len(m.lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %d: %+v", len(want), len(m.lines), m.lines)
+	}
+	for i := range want {
+		if m.lines[i] != want[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, want[i], m.lines[i])
+		}
+	}
+}
+
+func TestRecordShowsEveryColumnOfWidePreview(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "p"})
+	m.focus = FocusTableDetail
+	m.tableDetail.activeTab = PreviewTab
+
+	schema := &bigquery.TableSchema{}
+	var headers []string
+	var row []interface{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("col%02d", i)
+		schema.Fields = append(schema.Fields, &bigquery.Column{Name: name, Type: bq.IntegerFieldType})
+		headers = append(headers, name)
+		row = append(row, int64(i))
+	}
+	m.tableDetail.preview = &bigquery.TablePreview{Schema: schema, Headers: headers, Rows: [][]interface{}{row}}
+
+	if got := len(m.tableDetail.gridHeaders()); got != previewGridColumns {
+		t.Errorf("Expected the grid to show %d columns, got %d", previewGridColumns, got)
+	}
+
+	updated, _ := m.openRecord()
+	record := updated.(Model).record
+	if len(record.lines) != 20 {
+		t.Fatalf("Expected all 20 columns in the record, got %d", len(record.lines))
+	}
+	if last := record.lines[19]; last.name != "col19" || last.value != "19" {
+		t.Errorf("Expected the last column to be col19 = 19, got %+v", last)
+	}
+}
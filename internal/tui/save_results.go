@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Fields of the save results form, in tab order
+const (
+	saveFieldTable = iota
+	saveFieldDisposition
+	saveFieldPartition
+	saveFieldCluster
+	saveFieldCount
+)
+
+// SaveResultsModel is the form for saving query results to a table: the
+// destination, what to do if it exists, and optional partitioning and
+// clustering
+type SaveResultsModel struct {
+	query       string
+	table       textinput.Model
+	partition   textinput.Model
+	cluster     textinput.Model
+	disposition int // Index into bigquery.WriteDispositions
+	field       int
+	err         error
+	// returnFocus is restored when the form closes
+	returnFocus FocusState
+	keyMap      KeyMap
+}
+
+func NewSaveResultsModel(keyMap KeyMap, query, datasetID string, width int, returnFocus FocusState) SaveResultsModel {
+	newInput := func(placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		input.Width = max(width-20, 20)
+		return input
+	}
+
+	m := SaveResultsModel{
+		query:       query,
+		table:       newInput("dataset.table or project.dataset.table"),
+		partition:   newInput("optional DATE, DATETIME or TIMESTAMP column, partitioned by day"),
+		cluster:     newInput(fmt.Sprintf("optional, up to %d comma-separated columns", bigquery.MaxClusterFields)),
+		returnFocus: returnFocus,
+		keyMap:      keyMap,
+	}
+	if datasetID != "" {
+		m.table.SetValue(datasetID + ".")
+	}
+	m.focusField(saveFieldTable)
+	return m
+}
+
+// input returns the text input of a field, or nil for the write disposition
+func (m *SaveResultsModel) input(field int) *textinput.Model {
+	switch field {
+	case saveFieldTable:
+		return &m.table
+	case saveFieldPartition:
+		return &m.partition
+	case saveFieldCluster:
+		return &m.cluster
+	}
+	return nil
+}
+
+func (m *SaveResultsModel) focusField(field int) {
+	if input := m.input(m.field); input != nil {
+		input.Blur()
+	}
+	m.field = (field + saveFieldCount) % saveFieldCount
+	if input := m.input(m.field); input != nil {
+		input.Focus() // No blink command: a steady cursor is enough here
+	}
+}
+
+// request reads the destination and options from the form
+func (m SaveResultsModel) request() (bigquery.TableReference, bigquery.SaveResultsOptions, error) {
+	dst, err := bigquery.ParseTableReference(m.table.Value())
+	if err != nil {
+		return dst, bigquery.SaveResultsOptions{}, err
+	}
+	if dst.TableID == "" {
+		return dst, bigquery.SaveResultsOptions{}, fmt.Errorf("name the table to save to, e.g. %s.results", dst.DatasetID)
+	}
+
+	opts := bigquery.SaveResultsOptions{
+		WriteDisposition: bigquery.WriteDispositions[m.disposition],
+		PartitionField:   strings.TrimSpace(m.partition.Value()),
+	}
+	for _, field := range strings.Split(m.cluster.Value(), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			opts.ClusterFields = append(opts.ClusterFields, field)
+		}
+	}
+	if len(opts.ClusterFields) > bigquery.MaxClusterFields {
+		return dst, opts, fmt.Errorf("tables can be clustered by at most %d columns", bigquery.MaxClusterFields)
+	}
+	return dst, opts, nil
+}
+
+func (m SaveResultsModel) Update(msg tea.Msg) (SaveResultsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	// Letters go to the inputs, so only arrows and Tab move between fields
+	switch {
+	case key.Matches(keyMsg, m.keyMap.Tab) || keyMsg.Type == tea.KeyDown:
+		m.focusField(m.field + 1)
+		return m, nil
+	case key.Matches(keyMsg, m.keyMap.ShiftTab) || keyMsg.Type == tea.KeyUp:
+		m.focusField(m.field - 1)
+		return m, nil
+	}
+
+	if m.field == saveFieldDisposition {
+		count := len(bigquery.WriteDispositions)
+		switch {
+		case key.Matches(keyMsg, m.keyMap.Left):
+			m.disposition = (m.disposition + count - 1) % count
+		case key.Matches(keyMsg, m.keyMap.Right):
+			m.disposition = (m.disposition + 1) % count
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	input := m.input(m.field)
+	*input, cmd = input.Update(keyMsg)
+	m.err = nil
+	return m, cmd
+}
+
+func (m SaveResultsModel) View() string {
+	var content strings.Builder
+
+	content.WriteString(HeaderStyle.Render("💾 Save query results as a table") + "\n")
+	query := strings.Join(strings.Fields(m.query), " ")
+	content.WriteString(SubtleItemStyle.Render(truncate(query, max(m.table.Width+14, 40))) + "\n\n")
+
+	rows := []struct {
+		label string
+		value string
+	}{
+		{"Destination", m.table.View()},
+		{"If it exists", "◂ " + bigquery.WriteDispositions[m.disposition].String() + " ▸"},
+		{"Partition by", m.partition.View()},
+		{"Cluster by", m.cluster.View()},
+	}
+	for i, row := range rows {
+		style := ItemStyle
+		if i == m.field {
+			style = SelectedItemStyle
+		}
+		content.WriteString(style.Render(fmt.Sprintf("%-13s", row.label)) + " " + row.value + "\n")
+	}
+
+	content.WriteString("\n" + SubtleItemStyle.Render("The query runs again to write the table. Partitioning and clustering apply to new or replaced tables; an existing table keeps its own, and a replaced one must keep its partitioning.") + "\n")
+	if m.err != nil {
+		content.WriteString(ErrorStyle.Render(m.err.Error()) + "\n")
+	}
+	content.WriteString("\n" + HelpStyle.Render("Tab/↑/↓ to move • ←/→ to change what happens if the table exists • Enter to save • Esc to cancel"))
+
+	return content.String()
+}
+
+// openSaveResults shows the form for saving the current query results to a table
+func (m Model) openSaveResults() (tea.Model, tea.Cmd) {
+	td := m.tableDetail
+	switch {
+	case m.offline:
+		m.statusMessage = "Queries are disabled in offline mode"
+		return m, nil
+	case td.queryResults == nil || td.executedQuery == "":
+		m.statusMessage = "Run a query first to save its results as a table"
+		return m, nil
+	}
+
+	datasetID := td.currentDatasetID
+	if datasetID == "" && m.datasetList.selectedDataset != nil {
+		datasetID = m.datasetList.selectedDataset.ID
+	}
+	m.saveResults = NewSaveResultsModel(m.keyMap, td.executedQuery, datasetID, m.width, m.focus)
+	m.showSaveResults = true
+	m.focus = FocusSaveResults
+	return m, nil
+}
+
+// reopenSaveResults shows the form again with the reason a save was
+// rejected, unless the user has moved on to something else
+func (m Model) reopenSaveResults(err error) (tea.Model, tea.Cmd) {
+	if m.focus != m.saveResults.returnFocus {
+		m.statusMessage = fmt.Sprintf("Error: %s", err)
+		return m, nil
+	}
+	m.saveResults.err = err
+	m.showSaveResults = true
+	m.focus = FocusSaveResults
+	m.statusMessage = ""
+	return m, nil
+}
+
+// handleSaveResultsInput edits the form; Enter saves and Esc cancels
+func (m Model) handleSaveResultsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Escape):
+		m.showSaveResults = false
+		m.focus = m.saveResults.returnFocus
+		return m, nil
+
+	case key.Matches(msg, m.keyMap.Enter):
+		dst, opts, err := m.saveResults.request()
+		if err != nil {
+			m.saveResults.err = err
+			return m, nil
+		}
+		if dst.ProjectID == "" {
+			dst.ProjectID = m.currentProjectID()
+		}
+		m.showSaveResults = false
+		m.focus = m.saveResults.returnFocus
+		name := fmt.Sprintf("%s.%s.%s", dst.ProjectID, dst.DatasetID, dst.TableID)
+		m.statusMessage = fmt.Sprintf("Saving query results to %s...", name)
+		query := m.saveResults.query
+		return m, func() tea.Msg {
+			err := m.bqClient.SaveQueryResults(query, dst, opts)
+			if errors.Is(err, bigquery.ErrPartitioningChange) {
+				return SaveResultsRejectedMsg{Error: err}
+			}
+			if err != nil {
+				return ErrorMsg{Error: fmt.Errorf("failed to save results to %s: %w", name, err)}
+			}
+			return ResultsSavedMsg{Table: name}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.saveResults, cmd = m.saveResults.Update(msg)
+	return m, cmd
+}
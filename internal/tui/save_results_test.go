@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"bqui/internal/bigquery"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSaveResultsRequest(t *testing.T) {
+	m := NewSaveResultsModel(DefaultKeyMap(), "SELECT 1", "sales", 80, FocusTableDetail)
+	if _, _, err := m.request(); err == nil {
+		t.Error("Expected an error without a table name")
+	}
+
+	typeText := func(text string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	}
+	typeText("daily")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	typeText("day")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("country, , city")
+
+	dst, opts, err := m.request()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dst != (bigquery.TableReference{DatasetID: "sales", TableID: "daily"}) {
+		t.Errorf("Unexpected destination %+v", dst)
+	}
+	if opts.WriteDisposition != bigquery.WriteAppend || opts.PartitionField != "day" ||
+		!slices.Equal(opts.ClusterFields, []string{"country", "city"}) {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	typeText(",a,b,c")
+	if _, _, err := m.request(); err == nil {
+		t.Error("Expected an error for more than four cluster columns")
+	}
+}
+
+func TestRejectedSaveReopensForm(t *testing.T) {
+	m := NewModel(context.Background(), nil, Options{KeyMap: DefaultKeyMap(), Offline: true, ProjectID: "p"})
+	m.focus = FocusTableDetail
+	m.saveResults = NewSaveResultsModel(m.keyMap, "SELECT 1", "sales", 80, FocusTableDetail)
+
+	model, _ := m.Update(SaveResultsRejectedMsg{Error: bigquery.ErrPartitioningChange})
+	m = model.(Model)
+	if !m.showSaveResults || m.focus != FocusSaveResults || m.saveResults.err == nil {
+		t.Errorf("Expected the form to reopen with the error (focus %v)", m.focus)
+	}
+}
@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"bqui/internal/bigquery"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tableRef identifies a table in any project
+type tableRef struct {
+	projectID string
+	datasetID string
+	tableID   string
+}
+
+func (r tableRef) String() string {
+	return fmt.Sprintf("%s.%s.%s", r.projectID, r.datasetID, r.tableID)
+}
+
+// SchemaDiffModel shows the fields added, removed and changed going from the
+// marked table's schema to another table's
+type SchemaDiffModel struct {
+	from    tableRef
+	to      tableRef
+	diffs   []bigquery.FieldDiff
+	loading bool
+	err     error
+	offset  int
+	height  int
+	keyMap  KeyMap
+}
+
+func NewSchemaDiffModel(keyMap KeyMap, from, to tableRef, height int) SchemaDiffModel {
+	return SchemaDiffModel{
+		from:    from,
+		to:      to,
+		loading: true,
+		height:  height,
+		keyMap:  keyMap,
+	}
+}
+
+// maxVisible is the number of diff lines that fit below the header
+func (m SchemaDiffModel) maxVisible() int {
+	return max(m.height-8, 1)
+}
+
+func (m SchemaDiffModel) Update(msg tea.Msg) (SchemaDiffModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	lastOffset := max(len(m.diffs)-m.maxVisible(), 0)
+	switch {
+	case key.Matches(keyMsg, m.keyMap.Up):
+		m.offset = max(m.offset-1, 0)
+	case key.Matches(keyMsg, m.keyMap.Down):
+		m.offset = min(m.offset+1, lastOffset)
+	case key.Matches(keyMsg, m.keyMap.PageUp):
+		m.offset = max(m.offset-m.maxVisible(), 0)
+	case key.Matches(keyMsg, m.keyMap.PageDown):
+		m.offset = min(m.offset+m.maxVisible(), lastOffset)
+	case key.Matches(keyMsg, m.keyMap.Top, m.keyMap.VimTop):
+		m.offset = 0
+	case key.Matches(keyMsg, m.keyMap.Bottom, m.keyMap.VimBottom):
+		m.offset = lastOffset
+	}
+
+	return m, nil
+}
+
+func (m SchemaDiffModel) View() string {
+	var content strings.Builder
+
+	content.WriteString(HeaderStyle.Render("🔀 Schema diff") + "\n\n")
+	content.WriteString(SubtleItemStyle.Render("From: "+m.from.String()) + "\n")
+	content.WriteString(SubtleItemStyle.Render("To:   "+m.to.String()) + "\n\n")
+
+	switch {
+	case m.loading:
+		content.WriteString(SubtleItemStyle.Render("Loading schemas...") + "\n")
+	case m.err != nil:
+		content.WriteString(ErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	case len(m.diffs) == 0:
+		content.WriteString(SuccessStyle.Render("Schemas are identical") + "\n")
+	default:
+		end := min(m.offset+m.maxVisible(), len(m.diffs))
+		for _, diff := range m.diffs[m.offset:end] {
+			content.WriteString(renderFieldDiff(diff) + "\n")
+		}
+		if len(m.diffs) > end {
+			content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("... and %d more", len(m.diffs)-end)) + "\n")
+		}
+	}
+
+	if !m.loading && m.err == nil {
+		content.WriteString("\n" + SubtleItemStyle.Render(summarizeDiffs(m.diffs)))
+	}
+	content.WriteString("\n" + HelpStyle.Render("↑/↓ to scroll • Esc to close"))
+
+	return content.String()
+}
+
+// renderFieldDiff renders one difference as a +/-/~ line with the field's type and mode
+func renderFieldDiff(diff bigquery.FieldDiff) string {
+	switch diff.Change {
+	case bigquery.FieldAdded:
+		return SuccessStyle.Render("+ "+diff.Path) + "  " + DataTypeStyle.Render(describeField(diff.New))
+	case bigquery.FieldRemoved:
+		return ErrorStyle.Render("- "+diff.Path) + "  " + DataTypeStyle.Render(describeField(diff.Old))
+	default:
+		return ItemStyle.Render("~ "+diff.Path) + "  " +
+			DataTypeStyle.Render(describeField(diff.Old)+" → "+describeField(diff.New))
+	}
+}
+
+func describeField(col *bigquery.Column) string {
+	return fmt.Sprintf("%s %s", col.Type, col.Mode())
+}
+
+// summarizeDiffs counts the differences by kind
+func summarizeDiffs(diffs []bigquery.FieldDiff) string {
+	var added, removed, changed int
+	for _, diff := range diffs {
+		switch diff.Change {
+		case bigquery.FieldAdded:
+			added++
+		case bigquery.FieldRemoved:
+			removed++
+		default:
+			changed++
+		}
+	}
+	return fmt.Sprintf("%d added • %d removed • %d changed", added, removed, changed)
+}
+
+// typingInTableDetail reports whether the right pane is taking text input,
+// so plain letter keys belong to it
+func (m Model) typingInTableDetail() bool {
+	if m.focus != FocusTableDetail {
+		return false
+	}
+	td := m.tableDetail
+	return td.showSchemaFilter || td.showPreviewFilter || (td.activeTab == QueryTab && td.queryInput.Focused())
+}
+
+// selectedTableRef returns the table under the cursor, if any
+func (m Model) selectedTableRef() (tableRef, bool) {
+	table := m.datasetList.detailTable()
+	if table == nil || !m.datasetList.showingTables {
+		return tableRef{}, false
+	}
+	return tableRef{projectID: m.currentProjectID(), datasetID: table.DatasetID, tableID: table.ID}, true
+}
+
+// markedTableID returns the marked table's ID within the current project, so
+// the dataset list can flag it
+func (m Model) markedTableID() string {
+	if m.markedTable == nil || m.markedTable.projectID != m.currentProjectID() {
+		return ""
+	}
+	return m.markedTable.datasetID + "." + m.markedTable.tableID
+}
+
+// toggleMark marks the selected table as the base of a schema diff, or
+// unmarks it if it is already marked
+func (m Model) toggleMark() (tea.Model, tea.Cmd) {
+	ref, ok := m.selectedTableRef()
+	if !ok {
+		m.statusMessage = "Select a table to mark for schema diff"
+		return m, nil
+	}
+
+	if m.markedTable != nil && *m.markedTable == ref {
+		m.markedTable = nil
+		m.statusMessage = fmt.Sprintf("Unmarked %s", ref)
+	} else {
+		m.markedTable = &ref
+		m.statusMessage = fmt.Sprintf("Marked %s — select another table and press %s to compare schemas",
+			ref, m.keyMap.Compare.Help().Key)
+	}
+	m.datasetList.markedTable = m.markedTableID()
+	return m, nil
+}
+
+// compareWithMarked opens the schema diff from the marked table to the selected one
+func (m Model) compareWithMarked() (tea.Model, tea.Cmd) {
+	if m.markedTable == nil {
+		m.statusMessage = fmt.Sprintf("Mark a table with %s first", m.keyMap.Mark.Help().Key)
+		return m, nil
+	}
+	ref, ok := m.selectedTableRef()
+	if !ok || ref == *m.markedTable {
+		m.statusMessage = "Select a different table to compare with the marked one"
+		return m, nil
+	}
+
+	m.schemaDiff = NewSchemaDiffModel(m.keyMap, *m.markedTable, ref, m.height)
+	m.showSchemaDiff = true
+	m.focus = FocusSchemaDiff
+	return m, m.loadSchemaDiff(*m.markedTable, ref)
+}
+
+// handleSchemaDiffInput routes keys to the diff view; Esc closes it
+func (m Model) handleSchemaDiffInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keyMap.Escape, m.keyMap.Back):
+		m.showSchemaDiff = false
+		m.focus = FocusDatasetList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.schemaDiff, cmd = m.schemaDiff.Update(msg)
+	return m, cmd
+}
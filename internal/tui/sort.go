@@ -0,0 +1,250 @@
+package tui
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"bqui/internal/bigquery"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trailingLimit matches a LIMIT (and optional OFFSET) at the end of a query
+var trailingLimit = regexp.MustCompile(`(?i)\bLIMIT\s+\d+(\s+OFFSET\s+\d+)?\s*;?\s*$`)
+
+// endsWithLimit reports whether query ends in LIMIT; wrapping it in an ORDER
+// BY would only reorder the limited rows, not pick the top ones
+func endsWithLimit(query string) bool {
+	return trailingLimit.MatchString(query)
+}
+
+// sortKey orders rows by one column
+type sortKey struct {
+	column int
+	desc   bool
+}
+
+// toggleSort cycles a column through ascending, descending and unsorted. With
+// add the column is kept alongside the other keys, otherwise it replaces them.
+func toggleSort(keys []sortKey, column int, add bool) []sortKey {
+	i := slices.IndexFunc(keys, func(k sortKey) bool { return k.column == column })
+	if !add {
+		switch {
+		case i < 0 || len(keys) > 1:
+			return []sortKey{{column: column}}
+		case !keys[i].desc:
+			return []sortKey{{column: column, desc: true}}
+		default:
+			return nil
+		}
+	}
+
+	keys = slices.Clone(keys)
+	switch {
+	case i < 0:
+		return append(keys, sortKey{column: column})
+	case !keys[i].desc:
+		keys[i].desc = true
+		return keys
+	default:
+		return slices.Delete(keys, i, i+1)
+	}
+}
+
+// sortedRows returns rows ordered by keys, leaving rows untouched
+func sortedRows(rows [][]interface{}, keys []sortKey) [][]interface{} {
+	if len(keys) == 0 {
+		return rows
+	}
+
+	sorted := slices.Clone(rows)
+	slices.SortStableFunc(sorted, func(a, b []interface{}) int {
+		for _, key := range keys {
+			c := compareValues(cell(a, key.column), cell(b, key.column))
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return sorted
+}
+
+func cell(row []interface{}, column int) interface{} {
+	if column < len(row) {
+		return row[column]
+	}
+	return nil
+}
+
+// compareValues orders two cell values the way BigQuery would: NULLs first,
+// numbers, booleans and timestamps by value, and anything else by its text
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	switch x := a.(type) {
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolRank(x), boolRank(y))
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	// Strings, and dates and times whose text sorts chronologically
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case *big.Rat:
+		f, _ := n.Float64()
+		return f, true
+	}
+	return 0, false
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortLabel prefixes a column header with its sort direction, and its rank
+// when sorting by several columns
+func sortLabel(header string, keys []sortKey, column int) string {
+	for i, key := range keys {
+		if key.column != column {
+			continue
+		}
+		arrow := "▲"
+		if key.desc {
+			arrow = "▼"
+		}
+		if len(keys) > 1 {
+			return fmt.Sprintf("%s%d %s", arrow, i+1, header)
+		}
+		return arrow + " " + header
+	}
+	return header
+}
+
+// describeSort lists the sort keys for the tab header
+func describeSort(headers []string, keys []sortKey) string {
+	var parts []string
+	for _, key := range keys {
+		if key.column >= len(headers) {
+			continue
+		}
+		direction := "asc"
+		if key.desc {
+			direction = "desc"
+		}
+		parts = append(parts, headers[key.column]+" "+direction)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleSortKey sorts the Preview or Results rows by the column under the
+// cursor; add makes it an extra key instead of replacing the current ones
+func (m TableDetailModel) handleSortKey(add bool) (TableDetailModel, tea.Cmd) {
+	switch {
+	case m.activeTab == PreviewTab && m.preview != nil:
+		m.previewSort = toggleSort(m.previewSort, m.previewColCursor, add)
+		m.previewRowCursor = 0
+		m.scrollOffset = 0
+	case m.activeTab == ResultsTab && m.queryResults != nil:
+		m.resultsSort = toggleSort(m.resultsSort, m.resultsColCursor, add)
+		m.resultsRowCursor = 0
+	default:
+		return m, nil
+	}
+	m.visualMode = false // Rows have moved
+	return m, nil
+}
+
+// serverSort re-issues the current client-side sort to BigQuery, so it
+// applies to the whole table or query rather than the rows loaded so far
+func (m TableDetailModel) serverSort() (TableDetailModel, tea.Cmd) {
+	switch {
+	case m.activeTab == PreviewTab && m.preview != nil && len(m.previewSort) > 0:
+		var orderBy []bigquery.OrderBy
+		for _, key := range m.previewSort {
+			if m.preview.Schema == nil || key.column >= len(m.preview.Schema.Fields) {
+				return m, nil
+			}
+			field := m.preview.Schema.Fields[key.column]
+			if !orderable(field) {
+				typ := string(field.Type)
+				if field.Repeated {
+					typ = "ARRAY<" + typ + ">"
+				}
+				return m, func() tea.Msg {
+					return ErrorMsg{Error: fmt.Errorf("BigQuery can't sort by %s of type %s", field.Name, typ)}
+				}
+			}
+			orderBy = append(orderBy, bigquery.OrderBy{Column: field.Name, Desc: key.desc})
+		}
+		return m, func() tea.Msg { return SortPreviewMsg{OrderBy: orderBy} }
+
+	case m.activeTab == ResultsTab && m.queryResults != nil && len(m.resultsSort) > 0 && m.executedQuery != "":
+		if endsWithLimit(m.executedQuery) {
+			return m, func() tea.Msg {
+				return ErrorMsg{Error: errors.New("the query ends in LIMIT, so BigQuery would only sort the limited rows; add the ORDER BY to the query instead")}
+			}
+		}
+		// Result columns are unnamed, so order by position
+		var keys []string
+		for _, key := range m.resultsSort {
+			k := fmt.Sprintf("%d", key.column+1)
+			if key.desc {
+				k += " DESC"
+			}
+			keys = append(keys, k)
+		}
+		inner := strings.TrimRight(strings.TrimSpace(m.executedQuery), ";")
+		return m.runQuery(fmt.Sprintf("SELECT * FROM (\n%s\n) ORDER BY %s", inner, strings.Join(keys, ", ")))
+	}
+	return m, nil
+}
+
+// orderable reports whether BigQuery can ORDER BY a column
+func orderable(field *bigquery.Column) bool {
+	if field.Repeated {
+		return false
+	}
+	switch field.Type {
+	case "RECORD", "GEOGRAPHY", "JSON":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortedRows(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"b", int64(10), jan.AddDate(0, 0, 2)},
+		{"a", int64(9), nil},
+		{"b", float64(2.5), jan},
+		{"a", int64(100), jan.AddDate(0, 0, 1)},
+	}
+
+	// Numbers compare by value, not text
+	sorted := sortedRows(rows, []sortKey{{column: 1}})
+	if sorted[0][1] != 2.5 || sorted[3][1] != int64(100) {
+		t.Errorf("Unexpected numeric order: %v", sorted)
+	}
+	if rows[0][0] != "b" || rows[1][1] != int64(9) {
+		t.Error("Expected the original rows to be left untouched")
+	}
+
+	// NULLs sort first; descending puts them last
+	sorted = sortedRows(rows, []sortKey{{column: 2, desc: true}})
+	if sorted[0][2] != jan.AddDate(0, 0, 2) || sorted[3][2] != nil {
+		t.Errorf("Unexpected time order: %v", sorted)
+	}
+
+	// Ties on the first key are broken by the second
+	sorted = sortedRows(rows, []sortKey{{column: 0}, {column: 1, desc: true}})
+	var got []interface{}
+	for _, row := range sorted {
+		got = append(got, row[1])
+	}
+	want := []interface{}{int64(100), int64(9), int64(10), 2.5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestToggleSort(t *testing.T) {
+	keys := toggleSort(nil, 2, false)
+	keys = toggleSort(keys, 2, false)
+	if len(keys) != 1 || !keys[0].desc {
+		t.Fatalf("Expected a descending key after two presses, got %v", keys)
+	}
+	if keys = toggleSort(keys, 2, false); keys != nil {
+		t.Errorf("Expected the third press to clear the sort, got %v", keys)
+	}
+
+	keys = toggleSort([]sortKey{{column: 0}}, 3, true)
+	if len(keys) != 2 || keys[1].column != 3 {
+		t.Errorf("Expected column 3 as a second key, got %v", keys)
+	}
+	if keys = toggleSort(keys, 1, false); len(keys) != 1 || keys[0].column != 1 {
+		t.Errorf("Expected a plain sort to replace all keys, got %v", keys)
+	}
+}
+
+func TestEndsWithLimit(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT * FROM t LIMIT 10":                  true,
+		"SELECT * FROM t\nlimit 10 offset 20;\n":    true,
+		"SELECT * FROM (SELECT * FROM t LIMIT 5) x": false,
+		"SELECT * FROM t WHERE name = 'no limit'":   false,
+		"SELECT * FROM t ORDER BY a":                false,
+	}
+	for query, want := range tests {
+		if got := endsWithLimit(query); got != want {
+			t.Errorf("endsWithLimit(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
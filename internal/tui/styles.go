@@ -1,99 +1,338 @@
 package tui
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Theme is a named color palette. Colors are hex strings or ANSI color numbers;
+// an empty color means "use the terminal default".
+type Theme struct {
+	Name string `json:"name"`
+	// Base names a built-in theme that user theme files extend
+	Base             string `json:"base,omitempty"`
+	Border           string `json:"border"`
+	Bar              string `json:"bar"`
+	Accent           string `json:"accent"`
+	AccentText       string `json:"accent_text"`
+	Selected         string `json:"selected"`
+	SelectedText     string `json:"selected_text"`
+	Text             string `json:"text"`
+	Subtle           string `json:"subtle"`
+	Error            string `json:"error"`
+	Success          string `json:"success"`
+	Background       string `json:"background"`
+	HeaderBackground string `json:"header_background"`
+	HeaderText       string `json:"header_text"`
+	DataType         string `json:"data_type"`
+	VisualBackground string `json:"visual_background"`
+	VisualText       string `json:"visual_text"`
+}
+
+func DarkTheme() Theme {
+	return Theme{
+		Name:             "dark",
+		Border:           "#5A5A5A",
+		Bar:              "#5A5A5A",
+		Accent:           "#00D7FF",
+		AccentText:       "#000000",
+		Selected:         "#FF6B6B",
+		SelectedText:     "#FFFFFF",
+		Text:             "#FFFFFF",
+		Subtle:           "#888888",
+		Error:            "#FF5555",
+		Success:          "#50FA7B",
+		Background:       "#1A1A1A",
+		HeaderBackground: "#2D2D2D",
+		HeaderText:       "#FFFFFF",
+		DataType:         "#FFB86C",
+		VisualBackground: "#44475a",
+		VisualText:       "#f8f8f2",
+	}
+}
+
+func LightTheme() Theme {
+	return Theme{
+		Name:             "light",
+		Border:           "#A8A8A8",
+		Bar:              "#DADADA",
+		Accent:           "#005F87",
+		AccentText:       "#FFFFFF",
+		Selected:         "#D7005F",
+		SelectedText:     "#FFFFFF",
+		Text:             "#1C1C1C",
+		Subtle:           "#6C6C6C",
+		Error:            "#D70000",
+		Success:          "#008700",
+		Background:       "#FAFAFA",
+		HeaderBackground: "#E4E4E4",
+		HeaderText:       "#1C1C1C",
+		DataType:         "#AF5F00",
+		VisualBackground: "#D7D7FF",
+		VisualText:       "#1C1C1C",
+	}
+}
+
+func HighContrastTheme() Theme {
+	return Theme{
+		Name:             "high-contrast",
+		Border:           "#FFFFFF",
+		Bar:              "#000000",
+		Accent:           "#FFFF00",
+		AccentText:       "#000000",
+		Selected:         "#FFFF00",
+		SelectedText:     "#000000",
+		Text:             "#FFFFFF",
+		Subtle:           "#D0D0D0",
+		Error:            "#FF0000",
+		Success:          "#00FF00",
+		Background:       "#000000",
+		HeaderBackground: "#000000",
+		HeaderText:       "#FFFFFF",
+		DataType:         "#00FFFF",
+		VisualBackground: "#0000FF",
+		VisualText:       "#FFFFFF",
+	}
+}
+
+// MonochromeTheme uses no colors at all; selection is shown with reverse video
+func MonochromeTheme() Theme {
+	return Theme{Name: "monochrome"}
+}
+
+var builtinThemes = map[string]func() Theme{
+	"dark":          DarkTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+	"monochrome":    MonochromeTheme,
+}
+
+// ThemeNames returns the sorted names of the built-in themes
+func ThemeNames() []string {
+	var names []string
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme resolves a theme by name. Built-in names are matched first, then
+// a JSON theme file at the given path or at <themesDir>/<name>.json.
+// An empty name selects monochrome when NO_COLOR is set and dark otherwise.
+func LoadTheme(name, themesDir string) (Theme, error) {
+	if name == "" {
+		if os.Getenv("NO_COLOR") != "" {
+			return MonochromeTheme(), nil
+		}
+		return DarkTheme(), nil
+	}
+
+	if builtin, ok := builtinThemes[name]; ok {
+		return builtin(), nil
+	}
+
+	path := name
+	if _, err := os.Stat(path); err != nil && themesDir != "" {
+		path = filepath.Join(themesDir, name+".json")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q (built-in themes: %v)", name, ThemeNames())
+	}
+
+	// Read the base first so the file only needs to list the colors it changes
+	var header struct {
+		Base string `json:"base"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme file %s: %w", path, err)
+	}
+
+	theme := DarkTheme()
+	if header.Base != "" {
+		builtin, ok := builtinThemes[header.Base]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme file %s: unknown base theme %q", path, header.Base)
+		}
+		theme = builtin()
+	}
+
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme file %s: %w", path, err)
+	}
+	if theme.Name == "" || builtinThemes[theme.Name] != nil {
+		theme.Name = name
+	}
+
+	return theme, nil
+}
+
+func themeColor(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
+
 var (
-	BorderColor   = lipgloss.Color("#5A5A5A")
-	AccentColor   = lipgloss.Color("#00D7FF")
-	SelectedColor = lipgloss.Color("#FF6B6B")
-	TextColor     = lipgloss.Color("#FFFFFF")
-	SubtleColor   = lipgloss.Color("#888888")
-	ErrorColor    = lipgloss.Color("#FF5555")
-	SuccessColor  = lipgloss.Color("#50FA7B")
+	BorderColor   lipgloss.TerminalColor
+	AccentColor   lipgloss.TerminalColor
+	SelectedColor lipgloss.TerminalColor
+	TextColor     lipgloss.TerminalColor
+	SubtleColor   lipgloss.TerminalColor
+	ErrorColor    lipgloss.TerminalColor
+	SuccessColor  lipgloss.TerminalColor
 )
 
 var (
+	BaseStyle            lipgloss.Style
+	PaneStyle            lipgloss.Style
+	ActivePaneStyle      lipgloss.Style
+	SelectedItemStyle    lipgloss.Style
+	ItemStyle            lipgloss.Style
+	SubtleItemStyle      lipgloss.Style
+	TabActiveStyle       lipgloss.Style
+	TabInactiveStyle     lipgloss.Style
+	SearchBoxStyle       lipgloss.Style
+	StatusBarStyle       lipgloss.Style
+	HelpStyle            lipgloss.Style
+	ErrorStyle           lipgloss.Style
+	SuccessStyle         lipgloss.Style
+	HeaderStyle          lipgloss.Style
+	DataTypeStyle        lipgloss.Style
+	TableCellStyle       lipgloss.Style
+	SelectedHeaderStyle  lipgloss.Style
+	SelectedRowStyle     lipgloss.Style
+	VisualSelectionStyle lipgloss.Style
+	ProjectHeaderStyle   lipgloss.Style
+)
+
+// ActiveTheme is the theme most recently passed to ApplyTheme
+var ActiveTheme Theme
+
+func init() {
+	ApplyTheme(DarkTheme())
+}
+
+// ApplyTheme rebuilds all shared styles from the given theme
+func ApplyTheme(t Theme) {
+	ActiveTheme = t
+
+	BorderColor = themeColor(t.Border)
+	AccentColor = themeColor(t.Accent)
+	SelectedColor = themeColor(t.Selected)
+	TextColor = themeColor(t.Text)
+	SubtleColor = themeColor(t.Subtle)
+	ErrorColor = themeColor(t.Error)
+	SuccessColor = themeColor(t.Success)
+
+	// Without a selection color, fall back to reverse video so the cursor stays visible
+	reverse := t.Selected == ""
+
 	BaseStyle = lipgloss.NewStyle().
-			Foreground(TextColor).
-			Background(lipgloss.Color("#1A1A1A"))
+		Foreground(TextColor).
+		Background(themeColor(t.Background))
 
 	PaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(BorderColor).
-			Padding(0, 1).
-			Margin(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(BorderColor).
+		Padding(0, 1).
+		Margin(0, 1)
 
 	ActivePaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(AccentColor).
-			Padding(0, 1).
-			Margin(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(AccentColor).
+		Padding(0, 1).
+		Margin(0, 1)
+	if reverse {
+		ActivePaneStyle = ActivePaneStyle.Border(lipgloss.ThickBorder())
+	}
 
 	SelectedItemStyle = lipgloss.NewStyle().
-				Background(SelectedColor).
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Bold(true)
+		Background(SelectedColor).
+		Foreground(themeColor(t.SelectedText)).
+		Reverse(reverse).
+		Bold(true)
 
 	ItemStyle = lipgloss.NewStyle().
-			Foreground(TextColor)
+		Foreground(TextColor)
 
 	SubtleItemStyle = lipgloss.NewStyle().
-			Foreground(SubtleColor)
+		Foreground(SubtleColor)
 
 	TabActiveStyle = lipgloss.NewStyle().
-			Background(AccentColor).
-			Foreground(lipgloss.Color("#000000")).
-			Padding(0, 2).
-			Bold(true)
+		Background(AccentColor).
+		Foreground(themeColor(t.AccentText)).
+		Reverse(reverse).
+		Padding(0, 2).
+		Bold(true)
 
 	TabInactiveStyle = lipgloss.NewStyle().
-				Background(BorderColor).
-				Foreground(TextColor).
-				Padding(0, 2)
+		Background(themeColor(t.Bar)).
+		Foreground(TextColor).
+		Padding(0, 2)
 
 	SearchBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(AccentColor).
-			Padding(0, 1).
-			Margin(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(AccentColor).
+		Padding(0, 1).
+		Margin(0, 1)
 
 	StatusBarStyle = lipgloss.NewStyle().
-			Background(BorderColor).
-			Foreground(TextColor).
-			Padding(0, 1)
+		Background(themeColor(t.Bar)).
+		Foreground(TextColor).
+		Padding(0, 1)
 
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(SubtleColor).
-			Italic(true)
+		Foreground(SubtleColor).
+		Italic(true)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(ErrorColor).
-			Bold(true)
+		Foreground(ErrorColor).
+		Bold(true)
 
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(SuccessColor).
-			Bold(true)
+		Foreground(SuccessColor).
+		Bold(true)
 
 	HeaderStyle = lipgloss.NewStyle().
-			Foreground(AccentColor).
-			Bold(true)
+		Foreground(AccentColor).
+		Bold(true)
 
 	DataTypeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFB86C")).
-			Bold(true)
+		Foreground(themeColor(t.DataType)).
+		Bold(true)
 
 	TableCellStyle = lipgloss.NewStyle().
-			Padding(0, 1).
-			MaxWidth(20)
+		Padding(0, 1).
+		MaxWidth(20)
 
 	SelectedHeaderStyle = lipgloss.NewStyle().
-				Background(AccentColor).
-				Foreground(lipgloss.Color("#000000")).
-				Bold(true)
+		Background(AccentColor).
+		Foreground(themeColor(t.AccentText)).
+		Reverse(reverse).
+		Bold(true)
 
 	SelectedRowStyle = lipgloss.NewStyle().
-				Background(BorderColor).
-				Foreground(TextColor)
-)
+		Background(themeColor(t.Bar)).
+		Foreground(TextColor).
+		Underline(reverse)
+
+	VisualSelectionStyle = lipgloss.NewStyle().
+		Background(themeColor(t.VisualBackground)).
+		Foreground(themeColor(t.VisualText)).
+		Underline(reverse)
+
+	ProjectHeaderStyle = lipgloss.NewStyle().
+		Background(themeColor(t.HeaderBackground)).
+		Foreground(themeColor(t.HeaderText)).
+		Bold(true).
+		Padding(0, 1)
+}
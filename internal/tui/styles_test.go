@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadThemeFileExtendsBase(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"base": "light", "accent": "#123456"}`
+	if err := os.WriteFile(filepath.Join(dir, "mine.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write theme file: %v", err)
+	}
+
+	theme, err := LoadTheme("mine", dir)
+	if err != nil {
+		t.Fatalf("Failed to load theme: %v", err)
+	}
+
+	if theme.Name != "mine" {
+		t.Errorf("Expected theme name 'mine', got '%s'", theme.Name)
+	}
+	if theme.Accent != "#123456" {
+		t.Errorf("Expected overridden accent '#123456', got '%s'", theme.Accent)
+	}
+	if theme.Text != LightTheme().Text {
+		t.Errorf("Expected text color from light base, got '%s'", theme.Text)
+	}
+}
+
+func TestLoadThemeDefaults(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	theme, err := LoadTheme("", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if theme.Name != "monochrome" {
+		t.Errorf("Expected monochrome theme with NO_COLOR set, got '%s'", theme.Name)
+	}
+
+	if _, err := LoadTheme("does-not-exist", t.TempDir()); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+}
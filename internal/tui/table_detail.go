@@ -43,20 +43,40 @@ type TableDetailModel struct {
 	visualMode     bool
 	visualStartRow int
 	visualEndRow   int
+	// dragging is set while the mouse button is held after clicking dragStartRow
+	dragging     bool
+	dragStartRow int
 	// Preview filtering
 	previewFilter     string
 	showPreviewFilter bool
+	// Client-side sort keys of the Preview and Results rows
+	previewSort []sortKey
+	resultsSort []sortKey
 	// Dialog and Results
 	showColumnDialog bool
 	selectedColumn   *bigquery.Column
 	dialogCursor     int
+	// Schema export dialog
+	showExportDialog bool
+	exportCursor     int
+	// Copy format chooser for visual selections
+	showCopyDialog   bool
+	copyCursor       int
 	queryResults     *bigquery.QueryResult
 	executedQuery    string
 	resultsRowCursor int
 	resultsColCursor int
+	// Range of _TABLE_SUFFIX values chosen in the shard browser for the
+	// wildcard table shardWildcard ("project.dataset.prefix*")
+	shardWildcard  string
+	shardRangeFrom string
+	shardRangeTo   string
+	keyMap         KeyMap
+	// offline hides data that can only come from BigQuery
+	offline bool
 }
 
-func NewTableDetailModel() TableDetailModel {
+func NewTableDetailModel(keyMap KeyMap) TableDetailModel {
 	queryInput := textarea.New()
 	queryInput.Placeholder = "Enter your SQL query here..."
 	queryInput.Focus()
@@ -90,6 +110,7 @@ func NewTableDetailModel() TableDetailModel {
 		executedQuery:     "",
 		resultsRowCursor:  0,
 		resultsColCursor:  0,
+		keyMap:            keyMap,
 	}
 }
 
@@ -158,16 +179,16 @@ func (m TableDetailModel) Update(msg tea.Msg) (TableDetailModel, tea.Cmd) {
 
 		if m.activeTab == QueryTab && m.queryInput.Focused() {
 			switch {
-			case key.Matches(msg, DefaultKeyMap().Escape):
+			case key.Matches(msg, m.keyMap.Escape):
 				m.queryInput.Blur()
 				return m, nil
-			case key.Matches(msg, DefaultKeyMap().Tab):
+			case key.Matches(msg, m.keyMap.Tab):
 				// Tab should cycle tabs, not be consumed by textarea
 				m.activeTab = TabType((int(m.activeTab) + 1) % 3)
 				m.scrollOffset = 0
 				m.queryInput.Blur() // Blur the input when switching tabs
 				return m, nil
-			case key.Matches(msg, DefaultKeyMap().ShiftTab):
+			case key.Matches(msg, m.keyMap.ShiftTab):
 				// Shift+Tab cycles backward
 				m.activeTab = TabType((int(m.activeTab) + 2) % 3) // +2 is same as -1 in mod 3
 				m.scrollOffset = 0
@@ -191,12 +212,25 @@ func (m TableDetailModel) Update(msg tea.Msg) (TableDetailModel, tea.Cmd) {
 
 func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.Cmd) {
 	// Handle ESC key with proper hierarchy
-	if key.Matches(msg, DefaultKeyMap().Escape) {
+	if key.Matches(msg, m.keyMap.Escape) {
 		return m.handleEscapeKey()
 	}
 
+	if m.showExportDialog {
+		return m.handleExportDialogKey(msg)
+	}
+	if m.showCopyDialog {
+		return m.handleCopyDialogKey(msg)
+	}
+
+	if key.Matches(msg, m.keyMap.Export) && m.activeTab == SchemaTab && m.schema != nil && !m.showColumnDialog {
+		m.showExportDialog = true
+		m.exportCursor = 0
+		return m, nil
+	}
+
 	// Handle visual mode keys
-	if msg.String() == "V" || msg.String() == "shift+v" {
+	if key.Matches(msg, m.keyMap.Visual) {
 		if (m.activeTab == PreviewTab && m.preview != nil) || (m.activeTab == ResultsTab && m.queryResults != nil) {
 			m.visualMode = !m.visualMode
 			if m.visualMode {
@@ -216,8 +250,20 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 		return m, nil
 	}
 
+	if key.Matches(msg, m.keyMap.PushFilter) && m.activeTab == PreviewTab && !m.showColumnDialog {
+		return m.pushPreviewFilter()
+	}
+
+	// Handle sorting
+	if key.Matches(msg, m.keyMap.Sort, m.keyMap.SortAdd) && !m.showColumnDialog {
+		return m.handleSortKey(key.Matches(msg, m.keyMap.SortAdd))
+	}
+	if key.Matches(msg, m.keyMap.ServerSort) && !m.showColumnDialog {
+		return m.serverSort()
+	}
+
 	// Handle search trigger
-	if msg.String() == "/" {
+	if key.Matches(msg, m.keyMap.Search) {
 		switch m.activeTab {
 		case SchemaTab:
 			m.showSchemaFilter = true
@@ -229,7 +275,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 	}
 
 	// Handle horizontal navigation shortcuts
-	if msg.String() == "0" {
+	if key.Matches(msg, m.keyMap.LineStart) {
 		switch m.activeTab {
 		case PreviewTab:
 			m.previewColCursor = 0
@@ -241,10 +287,10 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 		return m, nil
 	}
 
-	if msg.String() == "$" {
+	if key.Matches(msg, m.keyMap.LineEnd) {
 		if m.activeTab == PreviewTab && m.preview != nil {
-			if len(m.preview.Headers) > 0 {
-				m.previewColCursor = len(m.preview.Headers) - 1
+			if len(m.gridHeaders()) > 0 {
+				m.previewColCursor = len(m.gridHeaders()) - 1
 				// Force horizontal scroll to show the last column immediately
 				m.forceScrollToColumn(m.previewColCursor)
 			}
@@ -256,23 +302,13 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 	}
 
 	switch {
-	case key.Matches(msg, DefaultKeyMap().Tab):
-		m.activeTab = TabType((int(m.activeTab) + 1) % 4)
-		m.scrollOffset = 0
-		m.previewRowCursor = 0
-		m.previewColCursor = 0
-		m.schemaRowCursor = 0
-		m.visualMode = false // Exit visual mode when changing tabs
+	case key.Matches(msg, m.keyMap.Tab):
+		m.switchTab(TabType((int(m.activeTab) + 1) % 4))
 
-	case key.Matches(msg, DefaultKeyMap().ShiftTab):
-		m.activeTab = TabType((int(m.activeTab) + 3) % 4) // +3 is same as -1 in mod 4
-		m.scrollOffset = 0
-		m.previewRowCursor = 0
-		m.previewColCursor = 0
-		m.schemaRowCursor = 0
-		m.visualMode = false // Exit visual mode when changing tabs
+	case key.Matches(msg, m.keyMap.ShiftTab):
+		m.switchTab(TabType((int(m.activeTab) + 3) % 4)) // +3 is same as -1 in mod 4
 
-	case key.Matches(msg, DefaultKeyMap().Up):
+	case key.Matches(msg, m.keyMap.Up):
 		if m.showColumnDialog {
 			if m.dialogCursor > 0 {
 				m.dialogCursor--
@@ -303,7 +339,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			}
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Down):
+	case key.Matches(msg, m.keyMap.Down):
 		if m.showColumnDialog {
 			maxOptions := m.getDialogOptionCount()
 			if m.dialogCursor < maxOptions-1 {
@@ -335,7 +371,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			m.scrollOffset++
 		}
 
-	case key.Matches(msg, DefaultKeyMap().VimTop), key.Matches(msg, DefaultKeyMap().Top):
+	case key.Matches(msg, m.keyMap.VimTop), key.Matches(msg, m.keyMap.Top):
 		if m.activeTab == PreviewTab && m.preview != nil {
 			m.previewRowCursor = 0
 			// In visual mode, extend selection to top; otherwise reset column cursor
@@ -358,7 +394,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			m.scrollOffset = 0
 		}
 
-	case key.Matches(msg, DefaultKeyMap().VimBottom), key.Matches(msg, DefaultKeyMap().Bottom):
+	case key.Matches(msg, m.keyMap.VimBottom), key.Matches(msg, m.keyMap.Bottom):
 		if m.activeTab == SchemaTab && m.schema != nil {
 			filteredFields := m.getFilteredSchemaFields()
 			if len(filteredFields) > 0 {
@@ -372,8 +408,8 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 				if m.visualMode {
 					m.visualEndRow = len(filteredRows) - 1
 				} else {
-					if len(m.preview.Headers) > 0 {
-						m.previewColCursor = len(m.preview.Headers) - 1
+					if len(m.gridHeaders()) > 0 {
+						m.previewColCursor = len(m.gridHeaders()) - 1
 					} else {
 						m.previewColCursor = 0
 					}
@@ -406,7 +442,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			}
 		}
 
-	case key.Matches(msg, DefaultKeyMap().PageUp):
+	case key.Matches(msg, m.keyMap.PageUp):
 		if m.activeTab == PreviewTab && m.preview != nil {
 			m.previewRowCursor -= 10
 			if m.previewRowCursor < 0 {
@@ -424,7 +460,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			}
 		}
 
-	case key.Matches(msg, DefaultKeyMap().PageDown):
+	case key.Matches(msg, m.keyMap.PageDown):
 		if m.activeTab == PreviewTab && m.preview != nil {
 			m.previewRowCursor += 10
 			if m.previewRowCursor >= len(m.preview.Rows) {
@@ -440,7 +476,7 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			m.scrollOffset += 10
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Left):
+	case key.Matches(msg, m.keyMap.Left):
 		if m.activeTab == PreviewTab && m.preview != nil {
 			if m.previewColCursor > 0 {
 				m.previewColCursor--
@@ -456,9 +492,9 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			}
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Right):
+	case key.Matches(msg, m.keyMap.Right):
 		if m.activeTab == PreviewTab && m.preview != nil {
-			if m.previewColCursor < len(m.preview.Headers)-1 {
+			if m.previewColCursor < len(m.gridHeaders())-1 {
 				m.previewColCursor++
 				// The horizontal offset will be automatically adjusted in the view rendering
 			}
@@ -470,20 +506,20 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 			m.horizontalOffset++
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Search):
+	case key.Matches(msg, m.keyMap.Search):
 		if m.activeTab == SchemaTab {
 			m.showSchemaFilter = true
 			return m, nil
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Escape):
+	case key.Matches(msg, m.keyMap.Escape):
 		if m.showSchemaFilter {
 			m.showSchemaFilter = false
 			m.schemaFilter = ""
 			return m, nil
 		}
 
-	case key.Matches(msg, DefaultKeyMap().Enter):
+	case key.Matches(msg, m.keyMap.Enter):
 		if m.showColumnDialog {
 			// Execute the selected query option
 			return m.executeDialogOption()
@@ -513,6 +549,16 @@ func (m TableDetailModel) handleKeypress(msg tea.KeyMsg) (TableDetailModel, tea.
 	return m, nil
 }
 
+// switchTab shows another tab, resetting the cursors and visual mode
+func (m *TableDetailModel) switchTab(tab TabType) {
+	m.activeTab = tab
+	m.scrollOffset = 0
+	m.previewRowCursor = 0
+	m.previewColCursor = 0
+	m.schemaRowCursor = 0
+	m.visualMode = false // Exit visual mode when changing tabs
+}
+
 func (m *TableDetailModel) ensurePreviewCursorVisible() {
 	maxVisible := 15
 
@@ -537,21 +583,33 @@ func (m *TableDetailModel) ensurePreviewCursorVisible() {
 	}
 }
 
+// previewGridColumns caps the columns of the Preview grid. The record view,
+// filters and copies still see every column.
+const previewGridColumns = 12
+
+// gridHeaders returns the columns shown in the Preview grid
+func (m TableDetailModel) gridHeaders() []string {
+	if len(m.preview.Headers) > previewGridColumns {
+		return m.preview.Headers[:previewGridColumns]
+	}
+	return m.preview.Headers
+}
+
 // Shared function to calculate column widths consistently
 func (m *TableDetailModel) calculateColumnWidths() []int {
-	if len(m.preview.Headers) == 0 {
+	if len(m.gridHeaders()) == 0 {
 		return []int{}
 	}
 
-	colWidths := make([]int, len(m.preview.Headers))
+	colWidths := make([]int, len(m.gridHeaders()))
 	minColWidth := 8
 	maxColWidth := 30
 
 	// Use filtered rows for width calculation to be consistent with rendering
 	filteredRows := m.getFilteredPreviewRows()
 
-	for i, header := range m.preview.Headers {
-		width := len(header)
+	for i, header := range m.gridHeaders() {
+		width := len(sortLabel(header, m.previewSort, i))
 		for _, row := range filteredRows {
 			if i < len(row) {
 				cellStr := fmt.Sprintf("%v", row[i])
@@ -573,7 +631,7 @@ func (m *TableDetailModel) calculateColumnWidths() []int {
 }
 
 func (m *TableDetailModel) ensurePreviewColumnVisible() {
-	if len(m.preview.Headers) == 0 {
+	if len(m.gridHeaders()) == 0 {
 		return
 	}
 
@@ -693,6 +751,17 @@ func (m TableDetailModel) renderTabsWithState(loadingSchema, loadingPreview bool
 		resultsStyle = TabActiveStyle
 	}
 
+	labels := tabLabels(loadingSchema, loadingPreview)
+	tabs = append(tabs, schemaStyle.Render(labels[SchemaTab]))
+	tabs = append(tabs, previewStyle.Render(labels[PreviewTab]))
+	tabs = append(tabs, queryStyle.Render(labels[QueryTab]))
+	tabs = append(tabs, resultsStyle.Render(labels[ResultsTab]))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+// tabLabels returns the tab titles, indexed by TabType
+func tabLabels(loadingSchema, loadingPreview bool) []string {
 	schemaText := "Schema"
 	if loadingSchema {
 		schemaText += " (Loading...)"
@@ -701,20 +770,14 @@ func (m TableDetailModel) renderTabsWithState(loadingSchema, loadingPreview bool
 	if loadingPreview {
 		previewText += " (Loading...)"
 	}
-
-	tabs = append(tabs, schemaStyle.Render(schemaText))
-	tabs = append(tabs, previewStyle.Render(previewText))
-	tabs = append(tabs, queryStyle.Render("Query"))
-	tabs = append(tabs, resultsStyle.Render("Results"))
-
-	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+	return []string{schemaText, previewText, "Query", "Results"}
 }
 
-func (m TableDetailModel) renderSchemaTab() string {
-	if m.schema == nil {
-		return SubtleItemStyle.Render("No schema loaded. Select a table to view its schema.")
-	}
+// schemaHeaderLine labels the columns of the Schema tab
+var schemaHeaderLine = fmt.Sprintf("%-25s %-15s %-10s %s", "Field Name", "Type", "Mode", "Description")
 
+// renderSchemaPreamble renders the lines of the Schema tab above the column header
+func (m TableDetailModel) renderSchemaPreamble() string {
 	var content strings.Builder
 	content.WriteString(HeaderStyle.Render("🏗  Table Schema") + "\n")
 
@@ -730,9 +793,19 @@ func (m TableDetailModel) renderSchemaTab() string {
 	}
 	content.WriteString("\n")
 
+	return content.String()
+}
+
+func (m TableDetailModel) renderSchemaTab() string {
+	if m.schema == nil {
+		return SubtleItemStyle.Render("No schema loaded. Select a table to view its schema.")
+	}
+
+	var content strings.Builder
+	content.WriteString(m.renderSchemaPreamble())
+
 	// Render tabular header
-	headerLine := fmt.Sprintf("%-25s %-15s %-10s %s", "Field Name", "Type", "Mode", "Description")
-	content.WriteString(HeaderStyle.Render(headerLine) + "\n")
+	content.WriteString(HeaderStyle.Render(schemaHeaderLine) + "\n")
 	separatorWidth := m.width - 2
 	if separatorWidth < 20 {
 		separatorWidth = 20
@@ -770,34 +843,50 @@ func (m TableDetailModel) renderSchemaTab() string {
 
 	// Add help text
 	if !m.showSchemaFilter {
-		content.WriteString("\n" + HelpStyle.Render("Press / to search columns, ←→ or h/l to scroll horizontally, Enter to query column"))
+		content.WriteString("\n" + HelpStyle.Render(fmt.Sprintf("Press / to search columns, ←→ or h/l to scroll horizontally, Enter to query column, %s to profile it, %s to export schema",
+			m.keyMap.Profile.Help().Key, m.keyMap.Export.Help().Key)))
 	}
 
 	// Render dialog if visible
 	if m.showColumnDialog {
 		content.WriteString("\n\n" + m.renderColumnDialog())
 	}
+	if m.showExportDialog {
+		content.WriteString("\n\n" + m.renderExportDialog())
+	}
 
 	return content.String()
 }
 
-func (m TableDetailModel) renderPreviewTab() string {
-	if m.preview == nil {
-		return SubtleItemStyle.Render("No preview loaded. Select a table to view sample data.")
-	}
-
+// renderPreviewPreamble renders the lines of the Preview tab above the column header
+func (m TableDetailModel) renderPreviewPreamble() string {
 	var content strings.Builder
 	content.WriteString(HeaderStyle.Render("👀 Table Preview") + "\n")
 
 	if m.currentTableName != "" {
-		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Table: %s", m.currentTableName)) + "\n")
+		header := fmt.Sprintf("Table: %s", m.currentTableName)
+		if m.preview.PartitionID != "" {
+			header += fmt.Sprintf(" • partition %s", m.preview.PartitionID)
+		}
+		if m.preview.Where != "" {
+			header += " • filtered in BigQuery"
+		}
+		if columns := len(m.preview.Headers); columns > previewGridColumns {
+			header += fmt.Sprintf(" • first %d of %d columns (%s shows all)", previewGridColumns, columns, m.keyMap.Record.Help().Key)
+		}
+		if len(m.previewSort) > 0 {
+			header += fmt.Sprintf(" • sorted by %s (%s to sort in BigQuery)", describeSort(m.preview.Headers, m.previewSort), m.keyMap.ServerSort.Help().Key)
+		} else if len(m.preview.OrderBy) > 0 {
+			header += " • sorted in BigQuery"
+		}
+		content.WriteString(SubtleItemStyle.Render(header) + "\n")
 	}
 
 	// Show preview filter if active
 	if m.showPreviewFilter {
 		content.WriteString(SearchBoxStyle.Render(fmt.Sprintf("Search rows: %s", m.previewFilter)) + "\n")
 	} else if m.previewFilter != "" {
-		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Filter: %s (press / to edit, esc to clear)", m.previewFilter)) + "\n")
+		content.WriteString(SubtleItemStyle.Render(m.describePreviewFilter()) + "\n")
 	}
 
 	// Show visual mode indicator
@@ -809,7 +898,21 @@ func (m TableDetailModel) renderPreviewTab() string {
 
 	content.WriteString("\n")
 
-	if len(m.preview.Headers) == 0 {
+	return content.String()
+}
+
+func (m TableDetailModel) renderPreviewTab() string {
+	if m.preview == nil {
+		if m.offline {
+			return SubtleItemStyle.Render("Preview is not available in offline mode.")
+		}
+		return SubtleItemStyle.Render("No preview loaded. Select a table to view sample data.")
+	}
+
+	var content strings.Builder
+	content.WriteString(m.renderPreviewPreamble())
+
+	if len(m.gridHeaders()) == 0 {
 		content.WriteString(SubtleItemStyle.Render("No data available"))
 		return content.String()
 	}
@@ -820,8 +923,8 @@ func (m TableDetailModel) renderPreviewTab() string {
 	// Render header with proper alignment and horizontal scrolling
 	headerRow := ""
 	currentPos := 0
-	for i, header := range m.preview.Headers {
-		headerText := truncate(header, colWidths[i])
+	for i, header := range m.gridHeaders() {
+		headerText := truncate(sortLabel(header, m.previewSort, i), colWidths[i])
 		cellFormatted := HeaderStyle.Render(fmt.Sprintf("%-*s", colWidths[i], headerText)) + " "
 
 		// Check if this column should be visible based on horizontal offset
@@ -879,7 +982,7 @@ func (m TableDetailModel) renderPreviewTab() string {
 		rowContent := ""
 
 		for i, cell := range row {
-			if i >= len(m.preview.Headers) {
+			if i >= len(m.gridHeaders()) {
 				break
 			}
 
@@ -927,8 +1030,7 @@ func (m TableDetailModel) renderPreviewTab() string {
 					// Use the original SelectedItemStyle
 					styledCell = SelectedItemStyle.Render(visibleCell)
 				} else if isVisualSelected {
-					visualStyle := lipgloss.NewStyle().Background(lipgloss.Color("#44475a")).Foreground(lipgloss.Color("#f8f8f2"))
-					styledCell = visualStyle.Render(visibleCell)
+					styledCell = VisualSelectionStyle.Render(visibleCell)
 				} else {
 					styledCell = TableCellStyle.Render(visibleCell)
 				}
@@ -957,13 +1059,16 @@ func (m TableDetailModel) renderPreviewTab() string {
 
 	// Show info and help
 	selectedColumnName := ""
-	if len(m.preview.Headers) > m.previewColCursor {
-		selectedColumnName = m.preview.Headers[m.previewColCursor]
+	if len(m.gridHeaders()) > m.previewColCursor {
+		selectedColumnName = m.gridHeaders()[m.previewColCursor]
 	}
 	info := fmt.Sprintf("Rows %d-%d of %d | Cursor[%d,%d] HOffset:%d | (%s)",
 		startRow+1, endRow, len(m.preview.Rows), m.previewRowCursor+1, m.previewColCursor+1, m.horizontalOffset, selectedColumnName)
 	content.WriteString("\n" + SubtleItemStyle.Render(info))
 	content.WriteString("\n" + HelpStyle.Render("Press y to copy selected cell, arrow keys/hjkl to navigate cells"))
+	if m.showCopyDialog {
+		content.WriteString("\n\n" + m.renderCopyDialog())
+	}
 
 	return content.String()
 }
@@ -978,6 +1083,10 @@ func (m TableDetailModel) renderQueryTab() string {
 		content.WriteString("\n")
 	}
 
+	if m.offline {
+		content.WriteString(SubtleItemStyle.Render("Queries are disabled in offline mode.") + "\n\n")
+	}
+
 	content.WriteString("SQL Query:\n")
 	content.WriteString(m.queryInput.View() + "\n")
 
@@ -1052,6 +1161,9 @@ func (m TableDetailModel) getMaxVisibleSchema() int {
 		// Dialog takes: title + column details + options + help + spacing
 		dialogHeight = 6 + m.getDialogOptionCount() // Estimated height for dialog
 	}
+	if m.showExportDialog {
+		dialogHeight = 5 + len(bigquery.SchemaFormats)
+	}
 
 	// Available space for schema rows
 	available := m.height - tabHeight - titleHeight - tableNameHeight - headerHeight - helpHeight - filterHeight - dialogHeight - paddingHeight
@@ -1072,7 +1184,7 @@ func (m TableDetailModel) getMaxVisibleResults() int {
 	paddingHeight := 1   // Some breathing room
 
 	// Available space for result rows
-	available := m.height - tabHeight - titleHeight - queryInfoHeight - headerHeight - helpHeight - paddingHeight
+	available := m.height - tabHeight - titleHeight - queryInfoHeight - headerHeight - helpHeight - paddingHeight - m.copyDialogHeight()
 
 	if available < 1 {
 		available = 1 // Show at least one row
@@ -1090,7 +1202,7 @@ func (m TableDetailModel) getMaxVisiblePreview() int {
 	paddingHeight := 1   // Some breathing room
 
 	// Available space for data rows
-	available := m.height - tabHeight - titleHeight - tableNameHeight - headerHeight - helpHeight - paddingHeight
+	available := m.height - tabHeight - titleHeight - tableNameHeight - headerHeight - helpHeight - paddingHeight - m.copyDialogHeight()
 
 	if available < 1 {
 		available = 1 // Show at least one row
@@ -1098,6 +1210,20 @@ func (m TableDetailModel) getMaxVisiblePreview() int {
 	return available
 }
 
+// focusColumn clears the schema filter and moves the cursor to the named column
+func (m *TableDetailModel) focusColumn(name string) bool {
+	m.schemaFilter = ""
+	m.showSchemaFilter = false
+	for i, field := range m.getFilteredSchemaFields() {
+		if strings.EqualFold(field.Name, name) {
+			m.schemaRowCursor = i
+			m.ensureSchemaCursorVisible()
+			return true
+		}
+	}
+	return false
+}
+
 func (m TableDetailModel) getFilteredSchemaFields() []*bigquery.Column {
 	if m.schema == nil {
 		return nil
@@ -1184,7 +1310,15 @@ func (m TableDetailModel) renderSchemaFieldTabular(field *bigquery.Column, inden
 
 // handleEscapeKey handles ESC key with proper hierarchy
 func (m TableDetailModel) handleEscapeKey() (TableDetailModel, tea.Cmd) {
-	// Priority 1: Close column dialog if open
+	// Priority 1: Close dialogs if open
+	if m.showExportDialog {
+		m.showExportDialog = false
+		return m, nil
+	}
+	if m.showCopyDialog {
+		m.showCopyDialog = false
+		return m, nil
+	}
 	if m.showColumnDialog {
 		m.showColumnDialog = false
 		m.selectedColumn = nil
@@ -1225,7 +1359,13 @@ func (m TableDetailModel) getFilteredPreviewRows() [][]interface{} {
 	}
 
 	if m.previewFilter == "" {
-		return m.preview.Rows
+		return sortedRows(m.preview.Rows, m.previewSort)
+	}
+
+	// Structured filters like "amount > 100"; anything else is a text search
+	if filter, err := bigquery.ParseFilter(m.previewFilter); err == nil {
+		rows, _ := m.matchPreviewRows(filter)
+		return sortedRows(rows, m.previewSort)
 	}
 
 	var filtered [][]interface{}
@@ -1247,7 +1387,29 @@ func (m TableDetailModel) getFilteredPreviewRows() [][]interface{} {
 		}
 	}
 
-	return filtered
+	return sortedRows(filtered, m.previewSort)
+}
+
+// getResultRows returns the query result rows in display order
+func (m TableDetailModel) getResultRows() [][]interface{} {
+	if m.queryResults == nil {
+		return nil
+	}
+	return sortedRows(m.queryResults.Rows, m.resultsSort)
+}
+
+// resultsColumns returns the result column names and, when the query
+// reported a schema, their fields
+func (m TableDetailModel) resultsColumns() ([]string, []*bigquery.Column) {
+	if m.queryResults.Schema == nil {
+		return m.queryResults.Columns, nil
+	}
+	fields := m.queryResults.Schema.Fields
+	headers := make([]string, len(fields))
+	for i, field := range fields {
+		headers[i] = field.Name
+	}
+	return headers, fields
 }
 
 // Helper functions for visual selection are defined in app.go
@@ -1266,7 +1428,7 @@ func (m TableDetailModel) isRowInVisualSelection(rowIdx int) bool {
 
 // forceScrollToColumn immediately calculates and sets horizontal offset to show a specific column
 func (m *TableDetailModel) forceScrollToColumn(colIndex int) {
-	if m.preview == nil || colIndex < 0 || colIndex >= len(m.preview.Headers) {
+	if m.preview == nil || colIndex < 0 || colIndex >= len(m.gridHeaders()) {
 		return
 	}
 
@@ -1287,7 +1449,7 @@ func (m *TableDetailModel) forceScrollToColumn(colIndex int) {
 	if colIndex == 0 {
 		// For first column, scroll to beginning
 		m.horizontalOffset = 0
-	} else if colIndex == len(m.preview.Headers)-1 {
+	} else if colIndex == len(m.gridHeaders())-1 {
 		// For last column, scroll so it's visible on the right
 		m.horizontalOffset = targetColEnd - maxDisplayWidth
 		if m.horizontalOffset < 0 {
@@ -1338,19 +1500,25 @@ func (m TableDetailModel) executeDialogOption() (TableDetailModel, tea.Cmd) {
 		return m, nil
 	}
 
+	// Close the dialog
+	m.showColumnDialog = false
+
+	return m.runQuery(query)
+}
+
+// runQuery shows query in the Query tab and runs it, switching to the Results tab
+func (m TableDetailModel) runQuery(query string) (TableDetailModel, tea.Cmd) {
 	// Clear previous results and reset cursors
 	m.queryResults = nil
 	m.resultsRowCursor = 0
 	m.resultsColCursor = 0
+	m.resultsSort = nil
 	m.visualMode = false
 
 	// Store the query for the Query tab and populate the query input
 	m.executedQuery = query
 	m.queryInput.SetValue(query)
 
-	// Close the dialog
-	m.showColumnDialog = false
-
 	// Switch to Results tab
 	m.activeTab = ResultsTab
 
@@ -1366,13 +1534,14 @@ func (m TableDetailModel) generateQueryForOption(optionIndex int) string {
 		return ""
 	}
 
+	prefix, _, layout, sharded := bigquery.SplitShardID(m.currentTableName)
+
 	// Construct fully qualified table name
 	var fullTableName string
 	if m.currentProjectID != "" && m.currentDatasetID != "" && m.currentTableName != "" {
-		if m.isDatePartitionedTable() {
-			// For partitioned tables, extract the base table name and use wildcard syntax
-			baseTableName := m.getBaseTableName()
-			fullTableName = fmt.Sprintf("`%s.%s.%s*`", m.currentProjectID, m.currentDatasetID, baseTableName)
+		if sharded {
+			// For date-sharded tables, query every shard through the wildcard table
+			fullTableName = fmt.Sprintf("`%s.%s.%s*`", m.currentProjectID, m.currentDatasetID, prefix)
 		} else {
 			fullTableName = fmt.Sprintf("`%s.%s.%s`", m.currentProjectID, m.currentDatasetID, m.currentTableName)
 		}
@@ -1384,11 +1553,18 @@ func (m TableDetailModel) generateQueryForOption(optionIndex int) string {
 	columnName := m.selectedColumn.Name
 	options := []string{}
 
-	// Handle partitioned tables - add _TABLE_SUFFIX constraint for date-partitioned tables
+	// Handle date-sharded tables - add a _TABLE_SUFFIX constraint so queries don't scan every shard,
+	// nor other tables the wildcard matches such as events_intraday_* (see bigquery.SuffixFilter)
 	tableConstraint := ""
-	if m.isDatePartitionedTable() {
-		// For date-partitioned tables, add a recent date constraint to avoid scanning all partitions
-		tableConstraint = " WHERE _TABLE_SUFFIX BETWEEN FORMAT_DATE('%Y%m%d', DATE_SUB(CURRENT_DATE(), INTERVAL 7 DAY)) AND FORMAT_DATE('%Y%m%d', CURRENT_DATE())"
+	if sharded {
+		if m.shardWildcard == strings.Trim(fullTableName, "`") {
+			// Use the range chosen in the shard browser
+			tableConstraint = " WHERE " + bigquery.SuffixFilter(m.shardRangeFrom, m.shardRangeTo)
+		} else {
+			// Default to the last week of shards
+			format := strings.NewReplacer("2006", "%Y", "01", "%m", "02", "%d").Replace(layout)
+			tableConstraint = fmt.Sprintf(" WHERE _TABLE_SUFFIX BETWEEN FORMAT_DATE('%s', DATE_SUB(CURRENT_DATE(), INTERVAL 7 DAY)) AND FORMAT_DATE('%s', CURRENT_DATE())", format, format)
+		}
 	}
 
 	// Basic select
@@ -1437,94 +1613,36 @@ func (m TableDetailModel) generateQueryForOption(optionIndex int) string {
 	return ""
 }
 
-// isDatePartitionedTable checks if the current table appears to be date-partitioned
-func (m TableDetailModel) isDatePartitionedTable() bool {
-	// Check if table name ends with a date pattern (common for partitioned tables)
-	// Examples: table_name_20250101, events__2025_03_30, logs_2025_01_15
-	tableName := m.currentTableName
-	if len(tableName) < 8 {
-		return false
-	}
-
-	// Check for common date partition patterns
-	// Pattern 1: ends with _YYYYMMDD
-	if len(tableName) >= 9 && tableName[len(tableName)-9] == '_' {
-		suffix := tableName[len(tableName)-8:]
-		if isDateString(suffix, "20060102") {
-			return true
-		}
-	}
-
-	// Pattern 2: ends with __YYYY_MM_DD
-	if len(tableName) >= 12 && tableName[len(tableName)-12:len(tableName)-10] == "__" {
-		suffix := tableName[len(tableName)-10:]
-		if isDateString(suffix, "2006_01_02") {
-			return true
-		}
-	}
-
-	// Pattern 3: ends with _YYYY_MM_DD
-	if len(tableName) >= 11 && tableName[len(tableName)-11] == '_' {
-		suffix := tableName[len(tableName)-10:]
-		if isDateString(suffix, "2006_01_02") {
-			return true
-		}
-	}
-
-	return false
-}
-
-// getBaseTableName extracts the base table name from a partitioned table
-func (m TableDetailModel) getBaseTableName() string {
-	tableName := m.currentTableName
-
-	// Pattern 1: table_name_YYYYMMDD -> table_name_
-	if len(tableName) >= 9 && tableName[len(tableName)-9] == '_' {
-		suffix := tableName[len(tableName)-8:]
-		if isDateString(suffix, "20060102") {
-			return tableName[:len(tableName)-8]
-		}
-	}
+// renderResultsPreamble renders the lines of the Results tab above the column header
+func (m TableDetailModel) renderResultsPreamble() string {
+	var content strings.Builder
+	content.WriteString(HeaderStyle.Render("📊 Query Results") + "\n")
 
-	// Pattern 2: table_name__YYYY_MM_DD -> table_name__
-	if len(tableName) >= 12 && tableName[len(tableName)-12:len(tableName)-10] == "__" {
-		suffix := tableName[len(tableName)-10:]
-		if isDateString(suffix, "2006_01_02") {
-			return tableName[:len(tableName)-10]
-		}
+	if m.executedQuery != "" {
+		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Query: %s", m.executedQuery)) + "\n")
 	}
 
-	// Pattern 3: table_name_YYYY_MM_DD -> table_name_
-	if len(tableName) >= 11 && tableName[len(tableName)-11] == '_' {
-		suffix := tableName[len(tableName)-10:]
-		if isDateString(suffix, "2006_01_02") {
-			return tableName[:len(tableName)-10]
+	rowsInfo := fmt.Sprintf("Rows: %d", len(m.queryResults.Rows))
+	if len(m.resultsSort) > 0 {
+		rowsInfo += " • sorted by " + describeSort(m.queryResults.Columns, m.resultsSort)
+		if endsWithLimit(m.executedQuery) {
+			rowsInfo += " (only the rows returned by the LIMIT)"
+		} else {
+			rowsInfo += fmt.Sprintf(" (%s to sort in BigQuery)", m.keyMap.ServerSort.Help().Key)
 		}
 	}
+	content.WriteString(SubtleItemStyle.Render(rowsInfo) + "\n")
 
-	// If no pattern matches, return the original table name
-	return tableName
-}
-
-// isDateString checks if a string matches a date format
-func isDateString(s, layout string) bool {
-	// Simple validation - check if string has right length and format
-	if len(s) != len(layout) {
-		return false
+	// Show visual mode indicator
+	if m.visualMode {
+		start := min(m.visualStartRow, m.visualEndRow)
+		end := max(m.visualStartRow, m.visualEndRow)
+		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("VISUAL: %d rows selected (%d-%d)", end-start+1, start+1, end+1)) + "\n")
 	}
 
-	// Check basic pattern - all digits in the right places
-	for i, char := range layout {
-		if char >= '0' && char <= '9' {
-			if s[i] < '0' || s[i] > '9' {
-				return false
-			}
-		} else if s[i] != byte(char) {
-			return false
-		}
-	}
+	content.WriteString("\n")
 
-	return true
+	return content.String()
 }
 
 // renderResultsTab renders the results of the executed query with preview-like functionality
@@ -1534,22 +1652,7 @@ func (m TableDetailModel) renderResultsTab() string {
 	}
 
 	var content strings.Builder
-	content.WriteString(HeaderStyle.Render("📊 Query Results") + "\n")
-
-	if m.executedQuery != "" {
-		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Query: %s", m.executedQuery)) + "\n")
-	}
-
-	content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Rows: %d", len(m.queryResults.Rows))) + "\n")
-
-	// Show visual mode indicator
-	if m.visualMode {
-		start := min(m.visualStartRow, m.visualEndRow)
-		end := max(m.visualStartRow, m.visualEndRow)
-		content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("VISUAL: %d rows selected (%d-%d)", end-start+1, start+1, end+1)) + "\n")
-	}
-
-	content.WriteString("\n")
+	content.WriteString(m.renderResultsPreamble())
 
 	if len(m.queryResults.Rows) == 0 {
 		content.WriteString(SubtleItemStyle.Render("No data returned from query."))
@@ -1560,18 +1663,12 @@ func (m TableDetailModel) renderResultsTab() string {
 	return content.String() + m.renderQueryResultsTable()
 }
 
-// renderQueryResultsTable renders the results table with navigation
-func (m TableDetailModel) renderQueryResultsTable() string {
-	if m.queryResults == nil || len(m.queryResults.Rows) == 0 {
-		return ""
-	}
-
-	var content strings.Builder
-
-	// Calculate column widths
+// resultsColumnWidths fits each Results column to its header and values,
+// between 8 and 30 characters
+func (m TableDetailModel) resultsColumnWidths() []int {
 	colWidths := make([]int, len(m.queryResults.Columns))
 	for i, header := range m.queryResults.Columns {
-		colWidths[i] = len(header)
+		colWidths[i] = len(sortLabel(header, m.resultsSort, i))
 		for _, row := range m.queryResults.Rows {
 			if i < len(row) {
 				cellValue := fmt.Sprintf("%v", row[i])
@@ -1589,34 +1686,22 @@ func (m TableDetailModel) renderQueryResultsTable() string {
 			colWidths[i] = 8
 		}
 	}
+	return colWidths
+}
 
-	// Render headers
-	var headers []string
-	for i, header := range m.queryResults.Columns {
-		style := HeaderStyle
-		if i == m.resultsColCursor {
-			style = SelectedHeaderStyle
-		}
-		// Pad or truncate header to fit column width
-		displayHeader := header
-		if len(displayHeader) > colWidths[i] {
-			displayHeader = displayHeader[:colWidths[i]-3] + "..."
-		}
-		headers = append(headers, style.Render(fmt.Sprintf("%-*s", colWidths[i], displayHeader)))
-	}
-	content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, headers...) + "\n")
-
+// resultsWindow returns the range of Results rows shown, centered on the cursor
+func (m TableDetailModel) resultsWindow() (startRow, endRow int) {
 	// Calculate visible rows based on cursor and height
 	maxRows := m.getMaxVisibleResults()
 	if maxRows < 1 {
 		maxRows = 1
 	}
 
-	startRow := m.resultsRowCursor - maxRows/2
+	startRow = m.resultsRowCursor - maxRows/2
 	if startRow < 0 {
 		startRow = 0
 	}
-	endRow := startRow + maxRows
+	endRow = startRow + maxRows
 	if endRow > len(m.queryResults.Rows) {
 		endRow = len(m.queryResults.Rows)
 		startRow = endRow - maxRows
@@ -1624,10 +1709,40 @@ func (m TableDetailModel) renderQueryResultsTable() string {
 			startRow = 0
 		}
 	}
+	return startRow, endRow
+}
+
+// renderQueryResultsTable renders the results table with navigation
+func (m TableDetailModel) renderQueryResultsTable() string {
+	if m.queryResults == nil || len(m.queryResults.Rows) == 0 {
+		return ""
+	}
+
+	var content strings.Builder
+	colWidths := m.resultsColumnWidths()
+
+	// Render headers
+	var headers []string
+	for i, header := range m.queryResults.Columns {
+		style := HeaderStyle
+		if i == m.resultsColCursor {
+			style = SelectedHeaderStyle
+		}
+		// Pad or truncate header to fit column width
+		displayHeader := sortLabel(header, m.resultsSort, i)
+		if len(displayHeader) > colWidths[i] {
+			displayHeader = displayHeader[:colWidths[i]-3] + "..."
+		}
+		headers = append(headers, style.Render(fmt.Sprintf("%-*s", colWidths[i], displayHeader)))
+	}
+	content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, headers...) + "\n")
+
+	startRow, endRow := m.resultsWindow()
 
 	// Render visible rows
+	rows := m.getResultRows()
 	for rowIdx := startRow; rowIdx < endRow; rowIdx++ {
-		row := m.queryResults.Rows[rowIdx]
+		row := rows[rowIdx]
 		var cells []string
 
 		for colIdx, colWidth := range colWidths {
@@ -1648,7 +1763,7 @@ func (m TableDetailModel) renderQueryResultsTable() string {
 			} else if rowIdx == m.resultsRowCursor {
 				style = SelectedRowStyle
 			} else if isVisualSelected {
-				style = lipgloss.NewStyle().Background(lipgloss.Color("#44475a")).Foreground(lipgloss.Color("#f8f8f2"))
+				style = VisualSelectionStyle
 			}
 
 			cells = append(cells, style.Render(fmt.Sprintf("%-*s", colWidth, cellValue)))
@@ -1668,6 +1783,9 @@ func (m TableDetailModel) renderQueryResultsTable() string {
 	} else {
 		content.WriteString("\n" + HelpStyle.Render("Press y to copy selected cell, V to enter visual mode"))
 	}
+	if m.showCopyDialog {
+		content.WriteString("\n\n" + m.renderCopyDialog())
+	}
 
 	return content.String()
 }
@@ -1711,7 +1829,7 @@ func (m TableDetailModel) renderColumnDialog() string {
 	// Show column details
 	content.WriteString(SubtleItemStyle.Render(fmt.Sprintf("Type: %s | Mode: %s",
 		m.selectedColumn.Type,
-		m.getColumnMode(m.selectedColumn))) + "\n\n")
+		m.selectedColumn.Mode())) + "\n\n")
 
 	// Render options
 	optionCount := m.getDialogOptionCount()
@@ -1729,13 +1847,43 @@ func (m TableDetailModel) renderColumnDialog() string {
 	return content.String()
 }
 
-// getColumnMode returns a human-readable mode string for a column
-func (m TableDetailModel) getColumnMode(col *bigquery.Column) string {
-	if col.Repeated {
-		return "REPEATED"
+// handleExportDialogKey picks an export format; Enter copies the schema and
+// the SaveToFile binding writes it to a file
+func (m TableDetailModel) handleExportDialogKey(msg tea.KeyMsg) (TableDetailModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Up):
+		if m.exportCursor > 0 {
+			m.exportCursor--
+		}
+	case key.Matches(msg, m.keyMap.Down):
+		if m.exportCursor < len(bigquery.SchemaFormats)-1 {
+			m.exportCursor++
+		}
+	case key.Matches(msg, m.keyMap.Enter, m.keyMap.SaveToFile):
+		format := bigquery.SchemaFormats[m.exportCursor]
+		toFile := key.Matches(msg, m.keyMap.SaveToFile)
+		m.showExportDialog = false
+		return m, func() tea.Msg {
+			return ExportSchemaMsg{Format: format, ToFile: toFile}
+		}
 	}
-	if col.Required {
-		return "REQUIRED"
+	return m, nil
+}
+
+func (m TableDetailModel) renderExportDialog() string {
+	var content strings.Builder
+
+	content.WriteString(HeaderStyle.Render(fmt.Sprintf("Export schema of %s", m.currentTableName)) + "\n\n")
+	for i, format := range bigquery.SchemaFormats {
+		if i == m.exportCursor {
+			content.WriteString(SelectedItemStyle.Render(fmt.Sprintf("► %s", format)) + "\n")
+		} else {
+			content.WriteString(ItemStyle.Render(fmt.Sprintf("  %s", format)) + "\n")
+		}
 	}
-	return "NULLABLE"
+
+	content.WriteString("\n" + HelpStyle.Render(fmt.Sprintf("↑↓ to choose • Enter to copy • %s to save to file • Esc to cancel",
+		m.keyMap.SaveToFile.Help().Key)))
+
+	return content.String()
 }
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"os"
+	"sync"
+)
+
+// TerminalOutput is the program's output. Bubble Tea writes each frame in
+// one call, and escape sequences such as OSC 52 are written between frames
+// rather than into the middle of one.
+type TerminalOutput struct {
+	*os.File // Keeps Fd, so Bubble Tea still sees a terminal
+	mu       sync.Mutex
+}
+
+func NewTerminalOutput(f *os.File) *TerminalOutput {
+	return &TerminalOutput{File: f}
+}
+
+func (t *TerminalOutput) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+func (t *TerminalOutput) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
@@ -1,13 +1,100 @@
 package clipboard
 
 import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
 	"github.com/atotto/clipboard"
 )
 
-func Copy(text string) error {
-	return clipboard.WriteAll(text)
+// Mode selects how text reaches the clipboard
+type Mode string
+
+const (
+	// ModeAuto uses OSC 52 in SSH sessions and the system clipboard
+	// elsewhere, falling back to OSC 52 when no clipboard tool is installed
+	ModeAuto Mode = "auto"
+	// ModeSystem only uses the system clipboard (pbcopy, xclip, xsel, wl-copy, ...)
+	ModeSystem Mode = "system"
+	// ModeOSC52 only asks the terminal to set its clipboard with the OSC 52
+	// escape sequence, which works over SSH and inside containers
+	ModeOSC52 Mode = "osc52"
+)
+
+var (
+	mu   sync.Mutex
+	mode = ModeAuto
+)
+
+// ParseMode validates a mode name; empty means ModeAuto
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case "", ModeAuto:
+		return ModeAuto, nil
+	case ModeSystem, ModeOSC52:
+		return Mode(name), nil
+	}
+	return "", fmt.Errorf("unknown clipboard mode %q (want auto, system or osc52)", name)
+}
+
+// SetMode changes how Copy writes to the clipboard
+func SetMode(m Mode) {
+	mu.Lock()
+	defer mu.Unlock()
+	mode = m
+}
+
+// Copy puts text on the system clipboard, or returns the OSC 52 escape
+// sequence that sets the terminal's clipboard instead. The caller writes the
+// sequence to the terminal itself, so it doesn't land in the middle of
+// whatever else is being drawn.
+func Copy(text string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	switch mode {
+	case ModeSystem:
+		return "", clipboard.WriteAll(text)
+	case ModeOSC52:
+		return osc52(text, inTmux()), nil
+	}
+
+	// Over SSH the system clipboard belongs to the remote machine, not the
+	// one the user is sitting at
+	if remoteSession() || clipboard.Unsupported {
+		return osc52(text, inTmux()), nil
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return osc52(text, inTmux()), nil
+	}
+	return "", nil
 }
 
+// Paste reads the system clipboard. Terminals don't reliably answer OSC 52
+// queries, so there is no OSC 52 fallback.
 func Paste() (string, error) {
 	return clipboard.ReadAll()
 }
+
+func remoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+func inTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// osc52 builds the escape sequence that sets the clipboard to text. Inside
+// tmux it's wrapped in a DCS passthrough, with each ESC doubled, so tmux
+// forwards it to the outer terminal (this needs `set -g allow-passthrough on`
+// in tmux 3.3 and later).
+func osc52(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
@@ -1,13 +1,13 @@
 package clipboard
 
-import (
-	"testing"
-)
+import "testing"
 
 func TestCopyAndPaste(t *testing.T) {
 	testText := "test.dataset.table"
+	SetMode(ModeSystem) // Auto mode would fall back to OSC 52, which can't be pasted
+	defer SetMode(ModeAuto)
 
-	err := Copy(testText)
+	_, err := Copy(testText)
 	if err != nil {
 		t.Skipf("Clipboard not available in test environment: %v", err)
 		return
@@ -24,3 +24,28 @@ func TestCopyAndPaste(t *testing.T) {
 
 	t.Logf("Successfully copied and pasted: %s", testText)
 }
+
+func TestOSC52(t *testing.T) {
+	if got, want := osc52("a.b.c", false), "\x1b]52;c;YS5iLmM=\a"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := osc52("a.b.c", true), "\x1bPtmux;\x1b\x1b]52;c;YS5iLmM=\a\x1b\\"; got != want {
+		t.Errorf("Expected tmux passthrough %q, got %q", want, got)
+	}
+
+	SetMode(ModeOSC52)
+	t.Setenv("TMUX", "")
+	defer SetMode(ModeAuto)
+
+	seq, err := Copy("a.b.c")
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if seq != osc52("a.b.c", false) {
+		t.Errorf("Expected the sequence to be returned, got %q", seq)
+	}
+
+	if _, err := ParseMode("clipboard"); err == nil {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+}